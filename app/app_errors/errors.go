@@ -23,6 +23,7 @@ type ErrorResponse struct {
 var (
 	BadRequest          = &AppError{Code: 400, Message: "bad request"}
 	Unauthorised        = &AppError{Code: 401, Message: "unauthorised"}
+	TooManyRequests     = &AppError{Code: 429, Message: "too many requests, try again later"}
 	InternalServerError = &AppError{Code: 500, Message: "internal server error"}
 )
 
@@ -6,8 +6,14 @@ import (
 	repository "github.com/s8sg/mini-loan-app/app/repostory"
 	"github.com/s8sg/mini-loan-app/app/server"
 	"github.com/s8sg/mini-loan-app/app/service"
+	"github.com/s8sg/mini-loan-app/app/util"
+	"github.com/shopspring/decimal"
 	"log"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	_ "github.com/lib/pq"
 )
@@ -19,6 +25,49 @@ var (
 	DbHost      = "localhost"
 	DbName      = "mini_loan_app"
 	AuthHmacKey = "secretkey"
+	// AuthProvider is local (built-in login) or oidc (ID tokens of OidcIssuer)
+	AuthProvider = service.AUTH_PROVIDER_LOCAL
+	OidcIssuer   = ""
+	OidcClientId = ""
+	// OidcUsernameClaim is the claim used as user id, OidcRolesClaim the claim with the groups of the user
+	OidcUsernameClaim = "sub"
+	OidcRolesClaim    = "groups"
+	// OidcRoleMapping maps groups of the identity provider to roles, format: group1=role1,group2=role2
+	OidcRoleMapping = ""
+	// AuthMfaEncryptionKey encrypts the TOTP secrets of the admins at rest, it has no default
+	AuthMfaEncryptionKey = ""
+	// AuthSigningAlgorithm is HS256 (signed with AuthHmacKey), RS256 or ES256 (signed with AuthSigningKeyFile)
+	AuthSigningAlgorithm = service.SIGNING_ALGORITHM_HS256
+	AuthSigningKeyFile   = ""
+	AuthSigningKeyId     = ""
+	// AuthVerificationKeyFiles are additional public keys accepted for verification, format: kid1=path1,kid2=path2
+	AuthVerificationKeyFiles = ""
+	// AdminUsername and AdminSecret are used to bootstrap the first admin user,
+	// bootstrap is skipped when no secret is configured
+	AdminUsername = "admin"
+	AdminSecret   = ""
+	// TrustedProxies are the proxies allowed to forward the client ip, format: ip1,cidr2
+	TrustedProxies = ""
+	// LoanCurrency is the default currency of the loans, LoanRoundingMode rounds the installments to its minor unit
+	LoanCurrency     = service.DefaultCurrency
+	LoanRoundingMode = service.InstallmentRoundingMode
+	// LoanQuoteSigningKey signs the quote ids, a loan can be created at the terms of a quote until it expires.
+	// A random key is generated at startup when it is not provided
+	LoanQuoteSigningKey = ""
+	// LoanCoolingOffDays are the days after the approval in which a customer can cancel a loan without repayments
+	LoanCoolingOffDays = "14"
+	// LoanApprovalThreshold is the loan amount above which LoanApprovalQuorum distinct admins must approve a loan
+	LoanApprovalThreshold = "1000000"
+	LoanApprovalQuorum    = "2"
+	// LoanRoleApprovalLimits are the approval limits of the staff without a user (identity provider staff and api
+	// keys) by role, format: role1=amount1,role2=amount2
+	LoanRoleApprovalLimits = ""
+	// LoanPendingExpiryDays are the days after the creation in which a pending loan expires, 0 disables the expiry.
+	// LoanExpiryJobInterval is the time between the runs of the expiry job, format: 30m, 1h
+	LoanPendingExpiryDays = "30"
+	LoanExpiryJobInterval = "1h"
+	// LoanPaymentWaterfall is the order in which a payment pays the components of the repayments
+	LoanPaymentWaterfall = "FEE,INTEREST,PRINCIPAL"
 )
 
 func InitializeServer() (*server.Server, error) {
@@ -38,25 +87,265 @@ func InitializeServer() (*server.Server, error) {
 
 	// init repository with db
 	loanRepository := repository.GetLoanRepository(db)
+	userRepository := repository.GetUserRepository(db)
+	customerRepository := repository.GetCustomerRepository(db)
+	sessionRepository := repository.GetSessionRepository(db)
+	roleRepository := repository.GetRoleRepository(db)
+	apiKeyRepository := repository.GetApiKeyRepository(db)
+	loginAttemptRepository := repository.GetLoginAttemptRepository(db)
+	mfaRepository := repository.GetMfaRepository(db)
+	loanProductRepository := repository.GetLoanProductRepository(db)
 
-	authService := service.GetAuthService(AuthHmacKey)
+	err = initializeMfaEncryption()
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialize mfa encryption, err: %v", err)
+	}
+	signingKeys, err := initializeSigningKeys()
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialize signing keys, err: %v", err)
+	}
+
+	authService := service.GetAuthService(signingKeys, userRepository, sessionRepository, roleRepository,
+		apiKeyRepository, loginAttemptRepository, mfaRepository, AuthMfaEncryptionKey)
+	err = initializeAdminUser(authService)
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialize admin user, err: %v", err)
+	}
+	authService, err = initializeAuthProvider(authService)
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialize auth provider, err: %v", err)
+	}
+	err = initializeLoanRounding()
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialize loan rounding, err: %v", err)
+	}
+	err = initializeLoanCancellation()
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialize loan cancellation, err: %v", err)
+	}
+	err = initializeLoanApproval()
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialize loan approval, err: %v", err)
+	}
+	err = initializeLoanExpiry()
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialize loan expiry, err: %v", err)
+	}
+	err = initializeLoanPayment()
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialize loan payment, err: %v", err)
+	}
+	err = initializeLoanQuote()
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialize loan quote, err: %v", err)
+	}
 	// init service with repository
-	loanService := service.GetLoanService(loanRepository)
+	loanService := service.GetLoanService(loanRepository, customerRepository, loanProductRepository, userRepository,
+		LoanQuoteSigningKey)
+	loanProductService := service.GetLoanProductService(loanProductRepository)
 	repaymentService := service.GetRepaymentService(loanRepository)
+	customerService := service.GetCustomerService(customerRepository, userRepository)
+	roleService := service.GetRoleService(roleRepository, userRepository, authService)
+	apiKeyService := service.GetApiKeyService(apiKeyRepository, authService)
+	mfaService := service.GetMfaService(userRepository, mfaRepository, AuthMfaEncryptionKey)
+
+	// start the background jobs
+	go service.RunLoanExpiryJob(loanService)
 
 	// init controllers with service
 	authController := controller.InitAuthController(authService)
 	loanController := controller.InitLoanController(loanService)
 	repaymentController := controller.InitRepaymentController(repaymentService)
+	customerController := controller.InitCustomerController(customerService)
+	roleController := controller.InitRoleController(roleService)
+	apiKeyController := controller.InitApiKeyController(apiKeyService)
+	mfaController := controller.InitMfaController(mfaService)
+	loanProductController := controller.InitLoanProductController(loanProductService)
 
 	// create server and configure with controller specific route configuration
 	appServer := server.GetServer(Port)
+	err = appServer.SetTrustedProxies(splitList(TrustedProxies))
+	if err != nil {
+		return nil, fmt.Errorf("cannot initialize trusted proxies, err: %v", err)
+	}
 	// Initialize routes
-	appServer.InitRoute(authService, loanController, authController, repaymentController)
+	appServer.InitRoute(authService, loanController, authController, repaymentController, customerController,
+		roleController, apiKeyController, mfaController, loanProductController)
 
 	return appServer, nil
 }
 
+// initializeSigningKeys : initialize the keys used to sign and verify the bearer tokens
+func initializeSigningKeys() (*service.SigningKeys, error) {
+	if AuthSigningAlgorithm == service.SIGNING_ALGORITHM_HS256 {
+		return service.GetHmacSigningKeys(AuthHmacKey), nil
+	}
+
+	verificationKeyFiles := map[string]string{}
+	for _, entry := range splitList(AuthVerificationKeyFiles) {
+		kid, path, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid verification key %s, expected format kid=path", entry)
+		}
+		verificationKeyFiles[strings.TrimSpace(kid)] = strings.TrimSpace(path)
+	}
+
+	return service.LoadSigningKeys(AuthSigningAlgorithm, AuthSigningKeyId, AuthSigningKeyFile, verificationKeyFiles)
+}
+
+// initializeMfaEncryption : the TOTP secrets are only protected at rest by a key of the deployment, a public
+// default key would protect nothing
+func initializeMfaEncryption() error {
+	if AuthMfaEncryptionKey == "" {
+		return fmt.Errorf("AUTH_MFA_ENCRYPTION_KEY must be provided")
+	}
+	return nil
+}
+
+// initializeAuthProvider : replaces the built-in login with the external identity provider if configured
+func initializeAuthProvider(authService service.AuthService) (service.AuthService, error) {
+	switch AuthProvider {
+	case service.AUTH_PROVIDER_LOCAL:
+		return authService, nil
+	case service.AUTH_PROVIDER_OIDC:
+		if OidcIssuer == "" || OidcClientId == "" {
+			return nil, fmt.Errorf("OIDC_ISSUER and OIDC_CLIENT_ID must be provided for %s", AuthProvider)
+		}
+		roleMapping := map[string]string{}
+		for _, entry := range splitList(OidcRoleMapping) {
+			group, role, found := strings.Cut(entry, "=")
+			if !found {
+				return nil, fmt.Errorf("invalid role mapping %s, expected format group=role", entry)
+			}
+			roleMapping[strings.TrimSpace(group)] = strings.TrimSpace(role)
+		}
+		return service.GetOidcAuthService(&service.OidcConfig{
+			Issuer:        OidcIssuer,
+			ClientId:      OidcClientId,
+			UsernameClaim: OidcUsernameClaim,
+			RolesClaim:    OidcRolesClaim,
+			RoleMapping:   roleMapping,
+		}, authService, &http.Client{Timeout: 10 * time.Second}), nil
+	default:
+		return nil, fmt.Errorf("unsupported auth provider %s", AuthProvider)
+	}
+}
+
+// initializeAdminUser : creates the configured admin user if it doesn't exist
+func initializeAdminUser(authService service.AuthService) error {
+	if AdminSecret == "" {
+		log.Println("AUTH_ADMIN_SECRET not provided, skipping admin user bootstrap")
+		return nil
+	}
+	err := authService.RegisterUser(AdminUsername, AdminSecret, []string{service.USER_TYPE_ADMIN})
+	if err != nil && err != service.UserAlreadyExists {
+		return err
+	}
+	return nil
+}
+
+// initializeLoanRounding : configures the default currency and the rounding of the installments
+func initializeLoanRounding() error {
+	err := service.ValidateLoanRounding(LoanCurrency, LoanRoundingMode)
+	if err != nil {
+		return err
+	}
+	service.DefaultCurrency = LoanCurrency
+	service.InstallmentRoundingMode = LoanRoundingMode
+	return nil
+}
+
+// initializeLoanCancellation : configures the cooling-off period of the approved loans, 0 disables the cancellation
+// of approved loans
+func initializeLoanCancellation() error {
+	coolingOffDays, err := strconv.Atoi(LoanCoolingOffDays)
+	if err != nil || coolingOffDays < 0 {
+		return fmt.Errorf("cooling-off days %s must be a number of days", LoanCoolingOffDays)
+	}
+	service.LoanCoolingOffPeriod = time.Duration(coolingOffDays) * time.Hour * 24
+	return nil
+}
+
+// initializeLoanApproval : configures the loans requiring the approval of multiple admins (maker-checker)
+func initializeLoanApproval() error {
+	threshold, err := decimal.NewFromString(LoanApprovalThreshold)
+	if err != nil || threshold.IsNegative() {
+		return fmt.Errorf("approval threshold %s must be an amount that is not negative", LoanApprovalThreshold)
+	}
+	quorum, err := strconv.Atoi(LoanApprovalQuorum)
+	if err != nil || quorum < 1 {
+		return fmt.Errorf("approval quorum %s must be a number of admins", LoanApprovalQuorum)
+	}
+	roleApprovalLimits := map[string]decimal.Decimal{}
+	for _, entry := range splitList(LoanRoleApprovalLimits) {
+		role, amount, found := strings.Cut(entry, "=")
+		if !found {
+			return fmt.Errorf("invalid role approval limit %s, expected format role=amount", entry)
+		}
+		limit, err := decimal.NewFromString(strings.TrimSpace(amount))
+		if err != nil || limit.IsNegative() {
+			return fmt.Errorf("approval limit %s of role %s must be an amount that is not negative", amount, role)
+		}
+		roleApprovalLimits[strings.TrimSpace(role)] = limit
+	}
+	service.LoanApprovalThreshold = threshold
+	service.LoanApprovalQuorum = quorum
+	service.RoleApprovalLimits = roleApprovalLimits
+	return nil
+}
+
+// initializeLoanExpiry : configures the expiry of the stale pending loans, 0 days disables the expiry job
+func initializeLoanExpiry() error {
+	expiryDays, err := strconv.Atoi(LoanPendingExpiryDays)
+	if err != nil || expiryDays < 0 {
+		return fmt.Errorf("pending expiry days %s must be a number of days", LoanPendingExpiryDays)
+	}
+	interval, err := time.ParseDuration(LoanExpiryJobInterval)
+	if err != nil || interval <= 0 {
+		return fmt.Errorf("expiry job interval %s must be a positive duration", LoanExpiryJobInterval)
+	}
+	service.LoanPendingExpiryPeriod = time.Duration(expiryDays) * time.Hour * 24
+	service.LoanExpiryJobInterval = interval
+	return nil
+}
+
+// initializeLoanPayment : configures the waterfall in which the payments pay the components of the repayments
+func initializeLoanPayment() error {
+	waterfall := splitList(LoanPaymentWaterfall)
+	err := service.ValidatePaymentWaterfall(waterfall)
+	if err != nil {
+		return err
+	}
+	service.PaymentWaterfall = waterfall
+	return nil
+}
+
+// initializeLoanQuote : generates the key signing the quote ids if none is provided, quotes of a generated key are
+// only valid on this instance until it restarts
+func initializeLoanQuote() error {
+	if LoanQuoteSigningKey != "" {
+		return nil
+	}
+	key, err := util.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+	log.Println("LOAN_QUOTE_SIGNING_KEY not provided, quotes are signed with a generated key of this instance")
+	LoanQuoteSigningKey = key
+	return nil
+}
+
+// splitList : splits a comma separated list ignoring empty entries
+func splitList(list string) []string {
+	entries := make([]string, 0)
+	for _, entry := range strings.Split(list, ",") {
+		if strings.TrimSpace(entry) != "" {
+			entries = append(entries, strings.TrimSpace(entry))
+		}
+	}
+	return entries
+}
+
 func initializeConfigFromEnv() {
 	env := os.Getenv("SERVER_PORT")
 	if env != "" {
@@ -88,4 +377,124 @@ func initializeConfigFromEnv() {
 		log.Println("AUTH_HMAC_SIGNING_KEY: ", env)
 		AuthHmacKey = env
 	}
+	env = os.Getenv("AUTH_MFA_ENCRYPTION_KEY")
+	if env != "" {
+		log.Println("AUTH_MFA_ENCRYPTION_KEY: ", "<provided>")
+		AuthMfaEncryptionKey = env
+	}
+	env = os.Getenv("AUTH_SIGNING_ALGORITHM")
+	if env != "" {
+		log.Println("AUTH_SIGNING_ALGORITHM: ", env)
+		AuthSigningAlgorithm = env
+	}
+	env = os.Getenv("AUTH_SIGNING_KEY_FILE")
+	if env != "" {
+		log.Println("AUTH_SIGNING_KEY_FILE: ", env)
+		AuthSigningKeyFile = env
+	}
+	env = os.Getenv("AUTH_SIGNING_KEY_ID")
+	if env != "" {
+		log.Println("AUTH_SIGNING_KEY_ID: ", env)
+		AuthSigningKeyId = env
+	}
+	env = os.Getenv("AUTH_VERIFICATION_KEY_FILES")
+	if env != "" {
+		log.Println("AUTH_VERIFICATION_KEY_FILES: ", env)
+		AuthVerificationKeyFiles = env
+	}
+	env = os.Getenv("AUTH_ADMIN_USERNAME")
+	if env != "" {
+		log.Println("AUTH_ADMIN_USERNAME: ", env)
+		AdminUsername = env
+	}
+	env = os.Getenv("AUTH_ADMIN_SECRET")
+	if env != "" {
+		log.Println("AUTH_ADMIN_SECRET: ", "<provided>")
+		AdminSecret = env
+	}
+	env = os.Getenv("AUTH_PROVIDER")
+	if env != "" {
+		log.Println("AUTH_PROVIDER: ", env)
+		AuthProvider = env
+	}
+	env = os.Getenv("OIDC_ISSUER")
+	if env != "" {
+		log.Println("OIDC_ISSUER: ", env)
+		OidcIssuer = env
+	}
+	env = os.Getenv("OIDC_CLIENT_ID")
+	if env != "" {
+		log.Println("OIDC_CLIENT_ID: ", env)
+		OidcClientId = env
+	}
+	env = os.Getenv("OIDC_USERNAME_CLAIM")
+	if env != "" {
+		log.Println("OIDC_USERNAME_CLAIM: ", env)
+		OidcUsernameClaim = env
+	}
+	env = os.Getenv("OIDC_ROLES_CLAIM")
+	if env != "" {
+		log.Println("OIDC_ROLES_CLAIM: ", env)
+		OidcRolesClaim = env
+	}
+	env = os.Getenv("OIDC_ROLE_MAPPING")
+	if env != "" {
+		log.Println("OIDC_ROLE_MAPPING: ", env)
+		OidcRoleMapping = env
+	}
+	env = os.Getenv("TRUSTED_PROXIES")
+	if env != "" {
+		log.Println("TRUSTED_PROXIES: ", env)
+		TrustedProxies = env
+	}
+	env = os.Getenv("LOAN_CURRENCY")
+	if env != "" {
+		log.Println("LOAN_CURRENCY: ", env)
+		LoanCurrency = env
+	}
+	env = os.Getenv("LOAN_ROUNDING_MODE")
+	if env != "" {
+		log.Println("LOAN_ROUNDING_MODE: ", env)
+		LoanRoundingMode = env
+	}
+	env = os.Getenv("LOAN_QUOTE_SIGNING_KEY")
+	if env != "" {
+		log.Println("LOAN_QUOTE_SIGNING_KEY: ", "<provided>")
+		LoanQuoteSigningKey = env
+	}
+	env = os.Getenv("LOAN_COOLING_OFF_DAYS")
+	if env != "" {
+		log.Println("LOAN_COOLING_OFF_DAYS: ", env)
+		LoanCoolingOffDays = env
+	}
+	env = os.Getenv("LOAN_APPROVAL_THRESHOLD")
+	if env != "" {
+		log.Println("LOAN_APPROVAL_THRESHOLD: ", env)
+		LoanApprovalThreshold = env
+	}
+	env = os.Getenv("LOAN_APPROVAL_QUORUM")
+	if env != "" {
+		log.Println("LOAN_APPROVAL_QUORUM: ", env)
+		LoanApprovalQuorum = env
+	}
+	env = os.Getenv("LOAN_ROLE_APPROVAL_LIMITS")
+	if env != "" {
+		log.Println("LOAN_ROLE_APPROVAL_LIMITS: ", env)
+		LoanRoleApprovalLimits = env
+	}
+	env = os.Getenv("LOAN_PENDING_EXPIRY_DAYS")
+	if env != "" {
+		log.Println("LOAN_PENDING_EXPIRY_DAYS: ", env)
+		LoanPendingExpiryDays = env
+	}
+	env = os.Getenv("LOAN_EXPIRY_JOB_INTERVAL")
+	if env != "" {
+		log.Println("LOAN_EXPIRY_JOB_INTERVAL: ", env)
+		LoanExpiryJobInterval = env
+	}
+	env = os.Getenv("LOAN_PAYMENT_WATERFALL")
+	if env != "" {
+		log.Println("LOAN_PAYMENT_WATERFALL: ", env)
+		LoanPaymentWaterfall = env
+	}
 }
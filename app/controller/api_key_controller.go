@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	serverError "github.com/s8sg/mini-loan-app/app/app_errors"
+	"github.com/s8sg/mini-loan-app/app/controller/dto"
+	"github.com/s8sg/mini-loan-app/app/service"
+	"log"
+	"net/http"
+)
+
+type ApiKeyController struct {
+	apiKeyService service.ApiKeyService
+}
+
+func InitApiKeyController(apiKeyService service.ApiKeyService) *ApiKeyController {
+	apiKeyController := &ApiKeyController{
+		apiKeyService: apiKeyService,
+	}
+	return apiKeyController
+}
+
+// CreateApiKeyHandler Issue an api key
+// @Summary      Issue an api key
+// @Description  Issue an api key for a machine client, the key is only returned in this response.
+// @Description  Use it with the header "Authorization: ApiKey <key>"
+// @Tags         API Key Management
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Param        data body dto.ApiKeyCreateRequest true "api key create request"
+// @Produce      json
+// @Success      201 {object} dto.ApiKeyCreateResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      403 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/api-key [post]
+func (h *ApiKeyController) CreateApiKeyHandler(c *gin.Context) {
+	apiKeyCreateRequest := &dto.ApiKeyCreateRequest{}
+	err := c.BindJSON(apiKeyCreateRequest)
+	if err != nil {
+		log.Printf("CreateApiKeyHandler: failed to parse request, error %v\n", err)
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	authContext, ok := getAuthContext(c)
+	if !ok {
+		log.Printf("CreateApiKeyHandler: auth context not initialized\n")
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	apiKeyCreateResponse, err := h.apiKeyService.CreateApiKey(authContext, apiKeyCreateRequest)
+	if err != nil {
+		log.Printf("CreateApiKeyHandler: failed to create api key %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, apiKeyCreateResponse)
+}
+
+// GetApiKeysHandler Get all api keys
+// @Summary      Get all api keys
+// @Description  Responds with all api keys with their permissions, expiry and last usage
+// @Tags         API Key Management
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Produce      json
+// @Success      200 {object} dto.GetAllApiKeysResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/api-keys [get]
+func (h *ApiKeyController) GetApiKeysHandler(c *gin.Context) {
+	apiKeyDetailsList, err := h.apiKeyService.GetAllApiKeys()
+	if err != nil {
+		log.Printf("GetApiKeysHandler: failed to get api keys %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllApiKeysResponse{ApiKeys: apiKeyDetailsList})
+}
+
+// RevokeApiKeyHandler Revoke an api key
+// @Summary      Revoke an api key
+// @Description  revoke an api key, requests with the key are rejected afterwards
+// @Tags         API Key Management
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Param        id path string true "api key id"
+// @Produce      json
+// @Success      200 {object} dto.GenericSuccessResponse
+// @Failure      404 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/api-key/{id} [delete]
+func (h *ApiKeyController) RevokeApiKeyHandler(c *gin.Context) {
+	err := h.apiKeyService.RevokeApiKey(c.Param("id"))
+	if err != nil {
+		log.Printf("RevokeApiKeyHandler: failed to revoke api key %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.GenericSuccessResponse{Message: "successfully completed"})
+}
+
+// getAuthContext : identity of the caller set by the auth middleware
+func getAuthContext(c *gin.Context) (*service.AuthContext, bool) {
+	authContextValue, ok := c.Get("auth")
+	if !ok {
+		return nil, false
+	}
+	authContext, ok := authContextValue.(*service.AuthContext)
+	return authContext, ok
+}
@@ -25,10 +25,12 @@ func InitAuthController(authService service.AuthService) *AuthController {
 // @Description  Responds with the bearer token with customer role
 // @Tags         Login
 // @accept       json
-// @Param        data body dto.LoginRequest true "username is mandatory"
+// @Param        data body dto.LoginRequest true "username and secret are mandatory"
 // @Produce      json
 // @Success      200 {object} dto.LoginResponse
 // @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      401 {object} app_errors.ErrorResponse
+// @Failure      429 {object} app_errors.ErrorResponse
 // @Failure      500 {object} app_errors.ErrorResponse
 // @Router       /auth/customer/login [post]
 func (h *AuthController) LoginAsCustomer(c *gin.Context) {
@@ -39,27 +41,27 @@ func (h *AuthController) LoginAsCustomer(c *gin.Context) {
 		app_errors.RespondWithError(c, app_errors.BadRequest)
 		return
 	}
-	token, err := h.authService.Login(loginRequest.Username, service.USER_TYPE_CUSTOMER, loginRequest.Secret)
+	tokens, err := h.authService.Login(loginRequest.Username, service.USER_TYPE_CUSTOMER, loginRequest.Secret, c.ClientIP())
 	if err != nil {
 		log.Printf("LoginAsCustomer: failed to login, error: %v\n", err)
 		app_errors.RespondWithError(c, err)
 		return
 	}
-	loginResponse := &dto.LoginResponse{
-		Token: token,
-	}
-	c.JSON(http.StatusOK, loginResponse)
+	c.JSON(http.StatusOK, toLoginResponse(tokens))
 }
 
 // LoginAsAdmin  Login user as an Admin
 // @Summary      Login user as an Admin
-// @Description  Responds with the bearer token with admin role
+// @Description  Responds with the bearer token with admin role, admins with MFA get a mfa-token to complete the login
+// @Description  with /auth/admin/login/mfa. Admin routes (except /admin/mfa) require a login with MFA
 // @Tags         Login
 // @accept       json
-// @Param        data body dto.LoginRequest true "username is mandatory"
+// @Param        data body dto.LoginRequest true "username and secret are mandatory"
 // @Produce      json
 // @Success      200 {object} dto.LoginResponse
 // @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      401 {object} app_errors.ErrorResponse
+// @Failure      429 {object} app_errors.ErrorResponse
 // @Failure      500 {object} app_errors.ErrorResponse
 // @Router       /auth/admin/login [post]
 func (h *AuthController) LoginAsAdmin(c *gin.Context) {
@@ -70,15 +72,227 @@ func (h *AuthController) LoginAsAdmin(c *gin.Context) {
 		app_errors.RespondWithError(c, app_errors.BadRequest)
 		return
 	}
-	token, err := h.authService.Login(loginRequest.Username, service.USER_TYPE_ADMIN, loginRequest.Secret)
+	tokens, err := h.authService.Login(loginRequest.Username, service.USER_TYPE_ADMIN, loginRequest.Secret, c.ClientIP())
 	if err != nil {
 		log.Printf("LoginAsAdmin: failed to login, error: %v\n", err)
 		app_errors.RespondWithError(c, err)
 		return
 	}
 
-	loginResponse := &dto.LoginResponse{
-		Token: token,
+	c.JSON(http.StatusOK, toLoginResponse(tokens))
+}
+
+// LoginAsAdminWithMfa Complete the admin login with the second factor
+// @Summary      Complete the admin login with the second factor
+// @Description  Responds with the bearer token with admin role, code is a TOTP code or a recovery code
+// @Tags         Login
+// @accept       json
+// @Param        data body dto.MfaLoginRequest true "mfa-token and code are mandatory"
+// @Produce      json
+// @Success      200 {object} dto.LoginResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      401 {object} app_errors.ErrorResponse
+// @Failure      429 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /auth/admin/login/mfa [post]
+func (h *AuthController) LoginAsAdminWithMfa(c *gin.Context) {
+	mfaLoginRequest := &dto.MfaLoginRequest{}
+	err := c.BindJSON(mfaLoginRequest)
+	if err != nil {
+		log.Printf("LoginAsAdminWithMfa: failed to parse request, error: %v\n", err)
+		app_errors.RespondWithError(c, app_errors.BadRequest)
+		return
+	}
+	tokens, err := h.authService.LoginWithMfa(mfaLoginRequest.MfaToken, mfaLoginRequest.Code, c.ClientIP())
+	if err != nil {
+		log.Printf("LoginAsAdminWithMfa: failed to login, error: %v\n", err)
+		app_errors.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toLoginResponse(tokens))
+}
+
+// Refresh      Rotate the refresh token
+// @Summary      Rotate the refresh token
+// @Description  Responds with a new bearer token and refresh token, the provided refresh token can't be used again
+// @Tags         Login
+// @accept       json
+// @Param        data body dto.RefreshRequest true "refresh-token is mandatory"
+// @Produce      json
+// @Success      200 {object} dto.LoginResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      401 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /auth/refresh [post]
+func (h *AuthController) Refresh(c *gin.Context) {
+	refreshRequest := &dto.RefreshRequest{}
+	err := c.BindJSON(refreshRequest)
+	if err != nil {
+		log.Printf("Refresh: failed to parse request, error: %v\n", err)
+		app_errors.RespondWithError(c, app_errors.BadRequest)
+		return
+	}
+	tokens, err := h.authService.Refresh(refreshRequest.RefreshToken)
+	if err != nil {
+		log.Printf("Refresh: failed to refresh, error: %v\n", err)
+		app_errors.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toLoginResponse(tokens))
+}
+
+// Logout       Revoke the session
+// @Summary      Revoke the session
+// @Description  Revokes the session of the refresh token, bearer tokens of the session are rejected afterwards
+// @Tags         Login
+// @accept       json
+// @Param        data body dto.RefreshRequest true "refresh-token is mandatory"
+// @Produce      json
+// @Success      200 {object} dto.GenericSuccessResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      401 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /auth/logout [post]
+func (h *AuthController) Logout(c *gin.Context) {
+	logoutRequest := &dto.RefreshRequest{}
+	err := c.BindJSON(logoutRequest)
+	if err != nil {
+		log.Printf("Logout: failed to parse request, error: %v\n", err)
+		app_errors.RespondWithError(c, app_errors.BadRequest)
+		return
+	}
+	err = h.authService.Logout(logoutRequest.RefreshToken)
+	if err != nil {
+		log.Printf("Logout: failed to logout, error: %v\n", err)
+		app_errors.RespondWithError(c, err)
+		return
 	}
-	c.JSON(http.StatusOK, loginResponse)
+
+	c.JSON(http.StatusOK, &dto.GenericSuccessResponse{Message: "successfully completed"})
+}
+
+// RevokeToken  Revoke a bearer token
+// @Summary      Revoke a bearer token
+// @Description  Revokes a single bearer token, the token is rejected afterwards while its session stays active
+// @Tags         Login
+// @accept       json
+// @Param        data body dto.RevokeTokenRequest true "token is mandatory"
+// @Produce      json
+// @Success      200 {object} dto.GenericSuccessResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      401 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /auth/revoke [post]
+func (h *AuthController) RevokeToken(c *gin.Context) {
+	revokeRequest := &dto.RevokeTokenRequest{}
+	err := c.BindJSON(revokeRequest)
+	if err != nil {
+		log.Printf("RevokeToken: failed to parse request, error: %v\n", err)
+		app_errors.RespondWithError(c, app_errors.BadRequest)
+		return
+	}
+	err = h.authService.RevokeToken(revokeRequest.Token)
+	if err != nil {
+		log.Printf("RevokeToken: failed to revoke token, error: %v\n", err)
+		app_errors.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.GenericSuccessResponse{Message: "successfully completed"})
+}
+
+// UnlockLoginHandler Unlock a login
+// @Summary      Unlock a login
+// @Description  clears the failed login attempts and the lockout of the username and/or the client ip
+// @Tags         Login
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Param        data body dto.LoginUnlockRequest true "username or ip is mandatory"
+// @Produce      json
+// @Success      200 {object} dto.GenericSuccessResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      404 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/login/unlock [post]
+func (h *AuthController) UnlockLoginHandler(c *gin.Context) {
+	unlockRequest := &dto.LoginUnlockRequest{}
+	err := c.BindJSON(unlockRequest)
+	if err != nil {
+		log.Printf("UnlockLoginHandler: failed to parse request, error: %v\n", err)
+		app_errors.RespondWithError(c, app_errors.BadRequest)
+		return
+	}
+	err = h.authService.UnlockLogin(unlockRequest.Username, unlockRequest.Ip)
+	if err != nil {
+		log.Printf("UnlockLoginHandler: failed to unlock login, error: %v\n", err)
+		app_errors.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.GenericSuccessResponse{Message: "successfully completed"})
+}
+
+// ImpersonateHandler View the app as a customer
+// @Summary      View the app as a customer
+// @Description  Responds with a short-lived read only bearer token of the customer for support, mutating requests
+// @Description  made with the token are rejected (403) and every request is logged with the admin and the customer
+// @Tags         Login
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Param        data body dto.ImpersonationRequest true "username is mandatory"
+// @Produce      json
+// @Success      200 {object} dto.ImpersonationResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      401 {object} app_errors.ErrorResponse
+// @Failure      404 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/impersonate [post]
+func (h *AuthController) ImpersonateHandler(c *gin.Context) {
+	impersonationRequest := &dto.ImpersonationRequest{}
+	err := c.BindJSON(impersonationRequest)
+	if err != nil {
+		log.Printf("ImpersonateHandler: failed to parse request, error: %v\n", err)
+		app_errors.RespondWithError(c, app_errors.BadRequest)
+		return
+	}
+
+	authContext, ok := getAuthContext(c)
+	if !ok {
+		log.Printf("ImpersonateHandler: auth context not initialized\n")
+		app_errors.RespondWithError(c, app_errors.BadRequest)
+		return
+	}
+
+	tokens, err := h.authService.Impersonate(authContext, impersonationRequest.Username)
+	if err != nil {
+		log.Printf("ImpersonateHandler: failed to impersonate, error: %v\n", err)
+		app_errors.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.ImpersonationResponse{Token: tokens.AccessToken, ExpiresIn: tokens.ExpiresIn})
+}
+
+func toLoginResponse(tokens *service.AuthTokens) *dto.LoginResponse {
+	if tokens.MfaToken != "" {
+		return &dto.LoginResponse{MfaRequired: true, MfaToken: tokens.MfaToken}
+	}
+	return &dto.LoginResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}
+}
+
+// GetJWKS      Public keys to verify bearer tokens
+// @Summary      Public keys to verify bearer tokens
+// @Description  Responds with the public keys (JWKS) used to verify the bearer tokens, empty when tokens are signed with HMAC
+// @Tags         Login
+// @Produce      json
+// @Success      200 {object} dto.JSONWebKeySet
+// @Router       /.well-known/jwks.json [get]
+func (h *AuthController) GetJWKS(c *gin.Context) {
+	c.JSON(http.StatusOK, h.authService.GetJWKS())
 }
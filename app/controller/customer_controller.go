@@ -0,0 +1,258 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	serverError "github.com/s8sg/mini-loan-app/app/app_errors"
+	"github.com/s8sg/mini-loan-app/app/controller/dto"
+	"github.com/s8sg/mini-loan-app/app/service"
+	"log"
+	"net/http"
+)
+
+type CustomerController struct {
+	customerService service.CustomerService
+}
+
+func InitCustomerController(customerService service.CustomerService) *CustomerController {
+	customerController := &CustomerController{
+		customerService: customerService,
+	}
+	return customerController
+}
+
+// SignUpHandler Register a new customer
+// @Summary      Register a new customer
+// @Description  Creates the customer login and profile, responds with the customer profile
+// @Tags         Customers
+// @accept       json
+// @Param        data body dto.CustomerSignupRequest true "customer signup request"
+// @Produce      json
+// @Success      201 {object} dto.CustomerDetails
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      409 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /auth/customer/signup [post]
+func (h *CustomerController) SignUpHandler(c *gin.Context) {
+	signupRequest := &dto.CustomerSignupRequest{}
+	err := c.BindJSON(signupRequest)
+	if err != nil {
+		log.Printf("SignUpHandler: failed to parse request, error %v\n", err)
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	customerDetails, err := h.customerService.SignUp(signupRequest)
+	if err != nil {
+		log.Printf("SignUpHandler: failed to signup customer %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, customerDetails)
+}
+
+// GetProfileHandler Get the profile of the customer
+// @Summary      Get the profile of the customer
+// @Description  Responds with the profile of the logged in customer
+// @Tags         Customers
+// @accept       json
+// @Param        Authorization header  string true "Bearer customer-token"
+// @Produce      json
+// @Success      200 {object} dto.CustomerDetails
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      404 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /user/profile [get]
+func (h *CustomerController) GetProfileHandler(c *gin.Context) {
+	userIdContext, ok := c.Get("id")
+	if !ok {
+		log.Printf("GetProfileHandler: user context not initialized\n")
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	customerId := fmt.Sprint(userIdContext)
+
+	customerDetails, err := h.customerService.GetCustomer(customerId)
+	if err != nil {
+		log.Printf("GetProfileHandler: failed to get customer %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, customerDetails)
+}
+
+// UpdateProfileHandler Update the profile of the customer
+// @Summary      Update the profile of the customer
+// @Description  Updates the profile of the logged in customer, responds with the updated profile
+// @Tags         Customers
+// @accept       json
+// @Param        Authorization header  string true "Bearer customer-token"
+// @Param        data body dto.CustomerUpdateRequest true "customer profile update request"
+// @Produce      json
+// @Success      200 {object} dto.CustomerDetails
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      404 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /user/profile [put]
+func (h *CustomerController) UpdateProfileHandler(c *gin.Context) {
+	updateRequest := &dto.CustomerUpdateRequest{}
+	err := c.BindJSON(updateRequest)
+	if err != nil {
+		log.Printf("UpdateProfileHandler: failed to parse request, error %v\n", err)
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	userIdContext, ok := c.Get("id")
+	if !ok {
+		log.Printf("UpdateProfileHandler: user context not initialized\n")
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	customerId := fmt.Sprint(userIdContext)
+
+	customerDetails, err := h.customerService.UpdateCustomer(customerId, updateRequest)
+	if err != nil {
+		log.Printf("UpdateProfileHandler: failed to update customer %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, customerDetails)
+}
+
+// GetCustomersHandler Get all customers
+// @Summary      Get all customers
+// @Description  Responds with all the customers
+// @Tags         Customer Management
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Produce      json
+// @Success      200 {object} dto.GetAllCustomersResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/customers [get]
+func (h *CustomerController) GetCustomersHandler(c *gin.Context) {
+	customerDetailsList, err := h.customerService.GetAllCustomers()
+	if err != nil {
+		log.Printf("GetCustomersHandler: failed to get customers %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllCustomersResponse{Customers: customerDetailsList})
+}
+
+// GetCustomerHandler Get a customer
+// @Summary      Get a customer
+// @Description  Responds with the customer profile
+// @Tags         Customer Management
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Param        id path string true "customer id"
+// @Produce      json
+// @Success      200 {object} dto.CustomerDetails
+// @Failure      404 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/customer/{id} [get]
+func (h *CustomerController) GetCustomerHandler(c *gin.Context) {
+	customerDetails, err := h.customerService.GetCustomer(c.Param("id"))
+	if err != nil {
+		log.Printf("GetCustomerHandler: failed to get customer %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, customerDetails)
+}
+
+// DisableCustomerHandler Disable a customer
+// @Summary      Disable a customer
+// @Description  disable a customer, disabled customers can't create loans
+// @Tags         Customer Management
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Param        data body dto.CustomerStatusRequest true "customer disable request"
+// @Produce      json
+// @Success      200 {object} dto.GenericSuccessResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      404 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/customer/disable [post]
+func (h *CustomerController) DisableCustomerHandler(c *gin.Context) {
+	statusRequest := &dto.CustomerStatusRequest{}
+	err := c.BindJSON(statusRequest)
+	if err != nil {
+		log.Printf("DisableCustomerHandler: failed to parse request, error %v\n", err)
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	err = h.customerService.DisableCustomer(statusRequest.CustomerId)
+	if err != nil {
+		log.Printf("DisableCustomerHandler: failed to disable customer %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.GenericSuccessResponse{Message: "successfully completed"})
+}
+
+// EnableCustomerHandler Enable a customer
+// @Summary      Enable a customer
+// @Description  enable a disabled customer
+// @Tags         Customer Management
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Param        data body dto.CustomerStatusRequest true "customer enable request"
+// @Produce      json
+// @Success      200 {object} dto.GenericSuccessResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      404 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/customer/enable [post]
+func (h *CustomerController) EnableCustomerHandler(c *gin.Context) {
+	statusRequest := &dto.CustomerStatusRequest{}
+	err := c.BindJSON(statusRequest)
+	if err != nil {
+		log.Printf("EnableCustomerHandler: failed to parse request, error %v\n", err)
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	err = h.customerService.EnableCustomer(statusRequest.CustomerId)
+	if err != nil {
+		log.Printf("EnableCustomerHandler: failed to enable customer %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.GenericSuccessResponse{Message: "successfully completed"})
+}
+
+// DeleteCustomerHandler Delete a customer
+// @Summary      Delete a customer
+// @Description  delete a customer and its login, customers with loans can only be disabled
+// @Tags         Customer Management
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Param        id path string true "customer id"
+// @Produce      json
+// @Success      200 {object} dto.GenericSuccessResponse
+// @Failure      404 {object} app_errors.ErrorResponse
+// @Failure      409 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/customer/{id} [delete]
+func (h *CustomerController) DeleteCustomerHandler(c *gin.Context) {
+	err := h.customerService.DeleteCustomer(c.Param("id"))
+	if err != nil {
+		log.Printf("DeleteCustomerHandler: failed to delete customer %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.GenericSuccessResponse{Message: "successfully completed"})
+}
@@ -0,0 +1,22 @@
+package dto
+
+import "github.com/s8sg/mini-loan-app/app/dto"
+
+// ApiKeyCreateRequest api key create request
+// @Description api key create request, the key can only be granted permissions of the admin creating it
+type ApiKeyCreateRequest struct {
+	Name          string   `json:"name" example:"nightly-reconciliation"`
+	Permissions   []string `json:"permissions" example:"loan:read:any,customer:read"`
+	ExpiresInDays int      `json:"expires-in-days" example:"90"`
+}
+
+// ApiKeyCreateResponse api key create response
+// @Description api key create response, the key is only returned once and can't be retrieved again
+type ApiKeyCreateResponse struct {
+	Key    string             `json:"key" example:"c6b0bd7e-6f0a-4f2a-9c4e-0e7a1e0b6a11.dummy-value"`
+	ApiKey *dto.ApiKeyDetails `json:"api-key"`
+}
+
+type GetAllApiKeysResponse struct {
+	ApiKeys []*dto.ApiKeyDetails `json:"api-keys"`
+}
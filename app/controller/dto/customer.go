@@ -0,0 +1,29 @@
+package dto
+
+import "github.com/s8sg/mini-loan-app/app/dto"
+
+// CustomerSignupRequest customer signup request
+// @Description customer signup request (phone is optional)
+type CustomerSignupRequest struct {
+	Username string `json:"username" example:"user1"`
+	Secret   string `json:"secret" example:"dummy-value"`
+	Name     string `json:"name" example:"John Doe"`
+	Email    string `json:"email" example:"john@example.com"`
+	Phone    string `json:"phone" example:"+6591234567"`
+}
+
+// CustomerUpdateRequest customer profile update request
+// @Description customer profile update request (phone is optional)
+type CustomerUpdateRequest struct {
+	Name  string `json:"name" example:"John Doe"`
+	Email string `json:"email" example:"john@example.com"`
+	Phone string `json:"phone" example:"+6591234567"`
+}
+
+type CustomerStatusRequest struct {
+	CustomerId string `json:"customer-id" example:"user1"`
+}
+
+type GetAllCustomersResponse struct {
+	Customers []*dto.CustomerDetails `json:"customers"`
+}
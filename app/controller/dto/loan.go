@@ -2,24 +2,88 @@ package dto
 
 import "github.com/s8sg/mini-loan-app/app/dto"
 
+// LoanCreateRequest loan creation request
+// @Description loan creation request, interest-rate is the annual rate in percent (default 0),
+// @Description interest-method is FLAT (default), DECLINING_BALANCE or INTEREST_ONLY,
+// @Description currency (ISO 4217) is the configured default currency when empty,
+// @Description repayment-frequency is WEEKLY (default), BI_WEEKLY, SEMI_MONTHLY or MONTHLY.
+// @Description With a product-id the currency, interest and repayment frequency are set by the loan product.
+// @Description With a quote-id the loan is created at the terms of the quote, the other fields must be empty
 type LoanCreateRequest struct {
-	Amount float64 `json:"amount" example:"300000"`
-	Term   int     `json:"term" example:"1"`
+	QuoteId        string  `json:"quote-id" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	ProductId      string  `json:"product-id" example:"5f1c2f0e-3b7a-4d55-9d3c-6f4f0a8b2c11"`
+	Amount         float64 `json:"amount" example:"300000"`
+	Currency       string  `json:"currency" example:"USD"`
+	Term           int     `json:"term" example:"1"`
+	Frequency      string  `json:"repayment-frequency" example:"MONTHLY"`
+	InterestRate   float64 `json:"interest-rate" example:"12.5"`
+	InterestMethod string  `json:"interest-method" example:"DECLINING_BALANCE"`
 }
 
+// LoanApproveRequest loan approval request
+// @Description loan approval request, with an adjusted amount, term or interest-rate the loan is offered to the
+// @Description customer at the adjusted terms (counter-offer) and approved once the customer accepts the offer
 type LoanApproveRequest struct {
+	LoanId       string   `json:"loan-id" example:"b9348325-d798-4f81-85fc-336220380d4f"`
+	Amount       float64  `json:"amount" example:"200000"`
+	Term         int      `json:"term" example:"6"`
+	InterestRate *float64 `json:"interest-rate" example:"14.5"`
+}
+
+// LoanOfferDecisionRequest counter-offer decision request
+// @Description counter-offer decision request, decision is ACCEPT (approves the loan) or DECLINE
+type LoanOfferDecisionRequest struct {
+	LoanId   string `json:"loan-id" example:"b9348325-d798-4f81-85fc-336220380d4f"`
+	Decision string `json:"decision" example:"ACCEPT"`
+}
+
+// LoanCancelRequest loan cancellation request
+// @Description loan cancellation request, pending loans can be cancelled and approved loans within
+// @Description the cooling-off period if nothing has been repaid
+type LoanCancelRequest struct {
 	LoanId string `json:"loan-id" example:"b9348325-d798-4f81-85fc-336220380d4f"`
 }
 
+// LoanRejectRequest loan rejection request
+// @Description loan rejection request, reason is CREDIT_HISTORY, INSUFFICIENT_INCOME, INCOMPLETE_APPLICATION, POLICY
+// @Description or OTHER (notes required). The reason and the notes are shown to the customer
+type LoanRejectRequest struct {
+	LoanId string `json:"loan-id" example:"b9348325-d798-4f81-85fc-336220380d4f"`
+	Reason string `json:"reason" example:"INSUFFICIENT_INCOME"`
+	Notes  string `json:"notes" example:"monthly income below the installment"`
+}
+
 type LoanRepaymentRequest struct {
 	RepaymentID string  `json:"repayment-id" example:"393be183-ecc3-4a52-a035-f2e8a70d3711"`
 	Amount      float64 `json:"amount" example:"300000"`
+	// Channel of the payment, CARD (default), BANK_TRANSFER or DIRECT_DEBIT
+	Channel string `json:"channel" example:"CARD"`
+}
+
+// LoanPayRequest loan payment request
+// @Description loan payment request, the amount pays the oldest due repayments of the loan first,
+// @Description channel is CARD (default), BANK_TRANSFER or DIRECT_DEBIT
+type LoanPayRequest struct {
+	Amount  float64 `json:"amount" example:"1500"`
+	Channel string  `json:"channel" example:"CARD"`
 }
 
 type GetAllLoansResponse struct {
 	Loans []*dto.LoanDetails `json:"loans"`
 }
 
+type GetLoanPaymentsResponse struct {
+	Payments []*dto.PaymentDetails `json:"payments"`
+}
+
+type GetLoanStatusHistoryResponse struct {
+	History []*dto.LoanStatusHistory `json:"history"`
+}
+
+type GetLoanApprovalsResponse struct {
+	Approvals []*dto.LoanApprovalDetails `json:"approvals"`
+}
+
 type GenericSuccessResponse struct {
 	Message string `json:"message" example:"successfully completed"`
 }
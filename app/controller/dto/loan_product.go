@@ -0,0 +1,27 @@
+package dto
+
+import "github.com/s8sg/mini-loan-app/app/dto"
+
+// LoanProductSaveRequest loan product create or update request
+// @Description loan product create or update request, interest-rate and origination-fee-rate are in percent,
+// @Description the origination fee is charged with the first repayment. Eligibility: max-open-loans limits the
+// @Description pending and approved loans of the customer, min-customer-days is the minimum days since signup (0 = no limit)
+type LoanProductSaveRequest struct {
+	Name               string  `json:"name" example:"Personal Loan 12M"`
+	Description        string  `json:"description" example:"unsecured personal loan"`
+	Status             string  `json:"status" example:"ACTIVE"`
+	Currency           string  `json:"currency" example:"USD"`
+	MinAmount          float64 `json:"min-amount" example:"1000"`
+	MaxAmount          float64 `json:"max-amount" example:"50000"`
+	Terms              []int64 `json:"terms" example:"6,12"`
+	InterestRate       float64 `json:"interest-rate" example:"12.5"`
+	InterestMethod     string  `json:"interest-method" example:"DECLINING_BALANCE"`
+	Frequency          string  `json:"repayment-frequency" example:"MONTHLY"`
+	OriginationFeeRate float64 `json:"origination-fee-rate" example:"1.5"`
+	MaxOpenLoans       int     `json:"max-open-loans" example:"1"`
+	MinCustomerDays    int     `json:"min-customer-days" example:"30"`
+}
+
+type GetAllLoanProductsResponse struct {
+	LoanProducts []*dto.LoanProductDetails `json:"loan-products"`
+}
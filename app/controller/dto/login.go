@@ -1,14 +1,51 @@
 package dto
 
 // LoginRequest login request
-// @Description login request (Secret is optional)
+// @Description login request (username and secret are mandatory)
 type LoginRequest struct {
 	Username string `json:"username" example:"user1"`
 	Secret   string `json:"secret" example:"dummy-value"`
 }
 
 // LoginResponse login response body
-// @Description login response with token
+// @Description login response with bearer token and the refresh token to rotate it,
+// @Description admins with MFA get only the mfa-token to complete the login with /auth/admin/login/mfa
 type LoginResponse struct {
+	Token        string `json:"token" example:"<bearer token>"`
+	RefreshToken string `json:"refresh-token" example:"<refresh token>"`
+	ExpiresIn    int64  `json:"expires-in" example:"1800"`
+	MfaRequired  bool   `json:"mfa-required,omitempty" example:"false"`
+	MfaToken     string `json:"mfa-token,omitempty" example:"<mfa token>"`
+}
+
+// RefreshRequest refresh or logout request
+// @Description refresh request with the refresh token of the session
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh-token" example:"<refresh token>"`
+}
+
+// RevokeTokenRequest token revocation request
+// @Description token revocation request with the bearer token to revoke
+type RevokeTokenRequest struct {
 	Token string `json:"token" example:"<bearer token>"`
 }
+
+// LoginUnlockRequest login unlock request
+// @Description login unlock request (username or ip is mandatory)
+type LoginUnlockRequest struct {
+	Username string `json:"username" example:"user1"`
+	Ip       string `json:"ip" example:"10.0.0.1"`
+}
+
+// ImpersonationRequest impersonation request
+// @Description impersonation request (username of the customer is mandatory)
+type ImpersonationRequest struct {
+	Username string `json:"username" example:"user1"`
+}
+
+// ImpersonationResponse impersonation response body
+// @Description read only bearer token of the customer, it can't be refreshed
+type ImpersonationResponse struct {
+	Token     string `json:"token" example:"<bearer token>"`
+	ExpiresIn int64  `json:"expires-in" example:"900"`
+}
@@ -0,0 +1,31 @@
+package dto
+
+// MfaEnrollResponse mfa enroll response
+// @Description TOTP secret to add to the authenticator app, otpauth-uri can be rendered as QR code
+type MfaEnrollResponse struct {
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	OtpauthUri string `json:"otpauth-uri" example:"otpauth://totp/mini-loan-app:admin?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+}
+
+// MfaActivateRequest mfa activate request
+// @Description mfa activate request with a code generated by the authenticator app
+type MfaActivateRequest struct {
+	Code string `json:"code" example:"123456"`
+}
+
+// RecoveryCodesResponse recovery codes response
+// @Description single use recovery codes, only returned once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery-codes" example:"ABCD-EFGH-IJKL-MNOP"`
+}
+
+// MfaLoginRequest mfa login request
+// @Description second step of the admin login, code is a TOTP code or a recovery code
+type MfaLoginRequest struct {
+	MfaToken string `json:"mfa-token" example:"<mfa token>"`
+	Code     string `json:"code" example:"123456"`
+}
+
+type MfaResetRequest struct {
+	Username string `json:"username" example:"admin"`
+}
@@ -0,0 +1,42 @@
+package dto
+
+import "github.com/s8sg/mini-loan-app/app/dto"
+
+// RoleSaveRequest role create or update request
+// @Description role create or update request, permissions of an existing role are replaced
+type RoleSaveRequest struct {
+	Name        string   `json:"name" example:"credit-officer"`
+	Description string   `json:"description" example:"staff approving loans"`
+	Permissions []string `json:"permissions" example:"loan:approve,loan:read:any"`
+}
+
+// UserRolesRequest user roles update request
+// @Description user roles update request, roles of the user are replaced
+type UserRolesRequest struct {
+	Username string   `json:"username" example:"officer1"`
+	Roles    []string `json:"roles" example:"credit-officer"`
+}
+
+// ApprovalLimitRequest approval limit update request
+// @Description approval limit update request, approval-limit is the highest loan amount the staff user can approve,
+// @Description null removes the limit
+type ApprovalLimitRequest struct {
+	Username      string   `json:"username" example:"officer1"`
+	ApprovalLimit *float64 `json:"approval-limit" example:"50000"`
+}
+
+// StaffUserCreateRequest staff user create request
+// @Description staff user create request, staff users login with /auth/admin/login
+type StaffUserCreateRequest struct {
+	Username string   `json:"username" example:"officer1"`
+	Secret   string   `json:"secret" example:"dummy-value"`
+	Roles    []string `json:"roles" example:"credit-officer"`
+}
+
+type GetAllRolesResponse struct {
+	Roles []*dto.RoleDetails `json:"roles"`
+}
+
+type GetAllPermissionsResponse struct {
+	Permissions []*dto.PermissionDetails `json:"permissions"`
+}
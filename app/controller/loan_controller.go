@@ -31,6 +31,7 @@ func InitLoanController(loanService service.LoanService) *LoanController {
 // @Produce      json
 // @Success      200 {object} dto.LoanDetails
 // @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      409 {object} app_errors.ErrorResponse
 // @Failure      500 {object} app_errors.ErrorResponse
 // @Router       /user/loan [post]
 func (h *LoanController) CreateLoanHandler(c *gin.Context) {
@@ -61,6 +62,47 @@ func (h *LoanController) CreateLoanHandler(c *gin.Context) {
 	c.JSON(http.StatusCreated, loanDetails)
 }
 
+// QuoteLoanHandler Quote a loan for a customer
+// @Summary      Quote a loan for a customer
+// @Description  Responds with the repayment schedule, total interest and APR of the loan without creating it.
+// @Description  The loan is created at the quoted terms with the quote-id until the quote expires
+// @Tags         Loans
+// @accept       json
+// @Param        Authorization header  string true "Bearer customer-token"
+// @Param        data body dto.LoanCreateRequest true "loan creation request"
+// @Produce      json
+// @Success      200 {object} dto.LoanQuoteDetails
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /user/loan/quote [post]
+func (h *LoanController) QuoteLoanHandler(c *gin.Context) {
+	loanCreateRequest := &dto.LoanCreateRequest{}
+	err := c.BindJSON(loanCreateRequest)
+	if err != nil {
+		log.Printf("QuoteLoanHandler: failed to parse request, error %v\n", err)
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	userIdContext, ok := c.Get("id")
+	if !ok {
+		log.Printf("QuoteLoanHandler: user context not initialized\n")
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	customerId := fmt.Sprint(userIdContext)
+
+	loanQuoteDetails, err := h.loanService.QuoteLoan(customerId, loanCreateRequest)
+	if err != nil {
+		log.Printf("QuoteLoanHandler: failed to quote loan %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, loanQuoteDetails)
+}
+
 // GetLoansHandler Get all loans for a customer
 // @Summary      Get all loans for a customer
 // @Description  Responds with the all loan details belongs to customer
@@ -95,9 +137,196 @@ func (h *LoanController) GetLoansHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, dto.GetAllLoansResponse{Loans: loanDetails})
 }
 
+// GetLoanStatusHistoryHandler Get the status history of a loan
+// @Summary      Get the status history of a loan
+// @Description  Responds with the status transitions of a loan of the customer in the order they were made
+// @Tags         Loans
+// @accept       json
+// @Param        Authorization header  string true "Bearer customer-token"
+// @Param        id path string true "loan id"
+// @Produce      json
+// @Success      200 {object} dto.GetLoanStatusHistoryResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      404 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /user/loan/{id}/history [get]
+func (h *LoanController) GetLoanStatusHistoryHandler(c *gin.Context) {
+	userIdContext, ok := c.Get("id")
+	if !ok {
+		log.Printf("GetLoanStatusHistoryHandler: user context not initialized\n")
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	customerId := fmt.Sprint(userIdContext)
+
+	history, err := h.loanService.GetLoanStatusHistory(customerId, c.Param("id"))
+	if err != nil {
+		log.Printf("GetLoanStatusHistoryHandler: failed to get loan status history %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetLoanStatusHistoryResponse{History: history})
+}
+
+// RespondToLoanOfferHandler Accept or decline a counter-offer
+// @Summary      Accept or decline a counter-offer
+// @Description  Accept the adjusted terms offered for a loan of the customer, which approves the loan, or decline them
+// @Tags         Loans
+// @accept       json
+// @Param        Authorization header  string true "Bearer customer-token"
+// @Param        data body dto.LoanOfferDecisionRequest true "counter-offer decision request"
+// @Produce      json
+// @Success      200 {object} dto.GenericSuccessResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      404 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /user/loan/offer [post]
+func (h *LoanController) RespondToLoanOfferHandler(c *gin.Context) {
+	loanOfferDecisionRequest := &dto.LoanOfferDecisionRequest{}
+	err := c.BindJSON(loanOfferDecisionRequest)
+	if err != nil {
+		log.Printf("RespondToLoanOfferHandler: failed to parse request, error %v\n", err)
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	userIdContext, ok := c.Get("id")
+	if !ok {
+		log.Printf("RespondToLoanOfferHandler: user context not initialized\n")
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	customerId := fmt.Sprint(userIdContext)
+
+	err = h.loanService.RespondToLoanOffer(customerId, loanOfferDecisionRequest)
+	if err != nil {
+		log.Printf("RespondToLoanOfferHandler: failed to respond to loan offer %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.GenericSuccessResponse{Message: "successfully completed"})
+}
+
+// CancelLoanHandler Cancel a loan of a customer
+// @Summary      Cancel a loan of a customer
+// @Description  Cancel a pending loan, or an approved loan within the cooling-off period if nothing has been repaid
+// @Tags         Loans
+// @accept       json
+// @Param        Authorization header  string true "Bearer customer-token"
+// @Param        data body dto.LoanCancelRequest true "loan cancellation request"
+// @Produce      json
+// @Success      200 {object} dto.GenericSuccessResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      404 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /user/loan/cancel [post]
+func (h *LoanController) CancelLoanHandler(c *gin.Context) {
+	loanCancelRequest := &dto.LoanCancelRequest{}
+	err := c.BindJSON(loanCancelRequest)
+	if err != nil {
+		log.Printf("CancelLoanHandler: failed to parse request, error %v\n", err)
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	userIdContext, ok := c.Get("id")
+	if !ok {
+		log.Printf("CancelLoanHandler: user context not initialized\n")
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	customerId := fmt.Sprint(userIdContext)
+
+	err = h.loanService.CancelLoan(customerId, loanCancelRequest)
+	if err != nil {
+		log.Printf("CancelLoanHandler: failed to cancel loan %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.GenericSuccessResponse{Message: "successfully completed"})
+}
+
+// GetCustomerLoansHandler Get all loans of a customer
+// @Summary      Get all loans of a customer
+// @Description  Responds with the all loan details belongs to the customer
+// @Tags         Customer Management
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Param        id path string true "customer id"
+// @Produce      json
+// @Success      200 {object} dto.GetAllLoansResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/customer/{id}/loans [get]
+func (h *LoanController) GetCustomerLoansHandler(c *gin.Context) {
+	loanDetails, err := h.loanService.GetAllLoansForCustomer(c.Param("id"))
+	if err != nil {
+		log.Printf("GetCustomerLoansHandler: failed to get loans %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllLoansResponse{Loans: loanDetails})
+}
+
+// GetAnyLoanStatusHistoryHandler Get the status history of any loan
+// @Summary      Get the status history of any loan
+// @Description  Responds with the status transitions of a loan with the actor, the time and the reason of each transition
+// @Tags         Loan Approval
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Param        id path string true "loan id"
+// @Produce      json
+// @Success      200 {object} dto.GetLoanStatusHistoryResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      404 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/loan/{id}/history [get]
+func (h *LoanController) GetAnyLoanStatusHistoryHandler(c *gin.Context) {
+	history, err := h.loanService.GetLoanStatusHistory("", c.Param("id"))
+	if err != nil {
+		log.Printf("GetAnyLoanStatusHistoryHandler: failed to get loan status history %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetLoanStatusHistoryResponse{History: history})
+}
+
+// GetLoanApprovalsHandler Get the approvals of a loan
+// @Summary      Get the approvals of a loan
+// @Description  Responds with the approvals of a loan, loans above the approval threshold list the partial approvals
+// @Description  until the quorum of distinct admins is reached
+// @Tags         Loan Approval
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Param        id path string true "loan id"
+// @Produce      json
+// @Success      200 {object} dto.GetLoanApprovalsResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/loan/{id}/approvals [get]
+func (h *LoanController) GetLoanApprovalsHandler(c *gin.Context) {
+	approvals, err := h.loanService.GetLoanApprovals(c.Param("id"))
+	if err != nil {
+		log.Printf("GetLoanApprovalsHandler: failed to get loan approvals %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetLoanApprovalsResponse{Approvals: approvals})
+}
+
 // ApproveLoanHandler Approve a loan
 // @Summary      Approve a loan
-// @Description  approve a loan
+// @Description  approve a pending loan, loans above the approval threshold are approved once the quorum of distinct
+// @Description  admins approved them. With an adjusted amount, term or interest rate the repayment schedule is
+// @Description  regenerated and the loan is OFFERED to the customer, the loan is approved once the customer accepts
 // @Tags         Loan Approval
 // @accept       json
 // @Param        Authorization header  string true "Bearer admin-token"
@@ -105,7 +334,9 @@ func (h *LoanController) GetLoansHandler(c *gin.Context) {
 // @Produce      json
 // @Success      200 {object} dto.GenericSuccessResponse
 // @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      403 {object} app_errors.ErrorResponse
 // @Failure      404 {object} app_errors.ErrorResponse
+// @Failure      409 {object} app_errors.ErrorResponse
 // @Failure      500 {object} app_errors.ErrorResponse
 // @Router       /admin/loan/approve [post]
 func (h *LoanController) ApproveLoanHandler(c *gin.Context) {
@@ -117,7 +348,14 @@ func (h *LoanController) ApproveLoanHandler(c *gin.Context) {
 		return
 	}
 
-	err = h.loanService.ApproveLoan(loanApproveRequest)
+	authContext, ok := getAuthContext(c)
+	if !ok {
+		log.Printf("ApproveLoanHandler: auth context not initialized\n")
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	err = h.loanService.ApproveLoan(authContext, loanApproveRequest)
 	if err != nil {
 		log.Printf("GetLoansHandler: failed to get loans %v\n", err)
 		serverError.RespondWithError(c, err)
@@ -126,3 +364,42 @@ func (h *LoanController) ApproveLoanHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, &dto.GenericSuccessResponse{Message: "successfully completed"})
 }
+
+// RejectLoanHandler Reject a loan
+// @Summary      Reject a loan
+// @Description  reject a pending loan with a reason, the reason and the notes are shown to the customer
+// @Tags         Loan Approval
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Param        data body dto.LoanRejectRequest true "loan rejection request"
+// @Produce      json
+// @Success      200 {object} dto.GenericSuccessResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      404 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/loan/reject [post]
+func (h *LoanController) RejectLoanHandler(c *gin.Context) {
+	loanRejectRequest := &dto.LoanRejectRequest{}
+	err := c.BindJSON(loanRejectRequest)
+	if err != nil {
+		log.Printf("RejectLoanHandler: failed to parse request, error %v\n", err)
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	authContext, ok := getAuthContext(c)
+	if !ok {
+		log.Printf("RejectLoanHandler: auth context not initialized\n")
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	err = h.loanService.RejectLoan(authContext, loanRejectRequest)
+	if err != nil {
+		log.Printf("RejectLoanHandler: failed to reject loan %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.GenericSuccessResponse{Message: "successfully completed"})
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	serverError "github.com/s8sg/mini-loan-app/app/app_errors"
+	"github.com/s8sg/mini-loan-app/app/controller/dto"
+	"github.com/s8sg/mini-loan-app/app/service"
+	"log"
+	"net/http"
+)
+
+type LoanProductController struct {
+	loanProductService service.LoanProductService
+}
+
+func InitLoanProductController(loanProductService service.LoanProductService) *LoanProductController {
+	loanProductController := &LoanProductController{
+		loanProductService: loanProductService,
+	}
+	return loanProductController
+}
+
+// CreateLoanProductHandler Create a loan product
+// @Summary      Create a loan product
+// @Description  Create a loan product offered to the customers, responds with the newly created loan product
+// @Tags         Loan Product Management
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Param        data body dto.LoanProductSaveRequest true "loan product save request"
+// @Produce      json
+// @Success      201 {object} dto.LoanProductDetails
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/loan-product [post]
+func (h *LoanProductController) CreateLoanProductHandler(c *gin.Context) {
+	loanProductSaveRequest := &dto.LoanProductSaveRequest{}
+	err := c.BindJSON(loanProductSaveRequest)
+	if err != nil {
+		log.Printf("CreateLoanProductHandler: failed to parse request, error %v\n", err)
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	loanProductDetails, err := h.loanProductService.CreateLoanProduct(loanProductSaveRequest)
+	if err != nil {
+		log.Printf("CreateLoanProductHandler: failed to create loan product %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, loanProductDetails)
+}
+
+// UpdateLoanProductHandler Update a loan product
+// @Summary      Update a loan product
+// @Description  replace the terms of a loan product and increment its version, existing loans keep their terms
+// @Tags         Loan Product Management
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Param        id path string true "loan product id"
+// @Param        data body dto.LoanProductSaveRequest true "loan product save request"
+// @Produce      json
+// @Success      200 {object} dto.LoanProductDetails
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      404 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/loan-product/{id} [put]
+func (h *LoanProductController) UpdateLoanProductHandler(c *gin.Context) {
+	loanProductSaveRequest := &dto.LoanProductSaveRequest{}
+	err := c.BindJSON(loanProductSaveRequest)
+	if err != nil {
+		log.Printf("UpdateLoanProductHandler: failed to parse request, error %v\n", err)
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	loanProductDetails, err := h.loanProductService.UpdateLoanProduct(c.Param("id"), loanProductSaveRequest)
+	if err != nil {
+		log.Printf("UpdateLoanProductHandler: failed to update loan product %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, loanProductDetails)
+}
+
+// GetAllLoanProductsHandler Get all loan products
+// @Summary      Get all loan products
+// @Description  Responds with all loan products including the inactive ones
+// @Tags         Loan Product Management
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Produce      json
+// @Success      200 {object} dto.GetAllLoanProductsResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/loan-products [get]
+func (h *LoanProductController) GetAllLoanProductsHandler(c *gin.Context) {
+	loanProductDetailsList, err := h.loanProductService.GetAllLoanProducts()
+	if err != nil {
+		log.Printf("GetAllLoanProductsHandler: failed to get loan products %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllLoanProductsResponse{LoanProducts: loanProductDetailsList})
+}
+
+// GetLoanProductsHandler Get the offered loan products
+// @Summary      Get the offered loan products
+// @Description  Responds with the active loan products a customer can create a loan for
+// @Tags         Loans
+// @accept       json
+// @Param        Authorization header  string true "Bearer customer-token"
+// @Produce      json
+// @Success      200 {object} dto.GetAllLoanProductsResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /user/loan-products [get]
+func (h *LoanProductController) GetLoanProductsHandler(c *gin.Context) {
+	loanProductDetailsList, err := h.loanProductService.GetActiveLoanProducts()
+	if err != nil {
+		log.Printf("GetLoanProductsHandler: failed to get loan products %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllLoanProductsResponse{LoanProducts: loanProductDetailsList})
+}
@@ -0,0 +1,155 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	serverError "github.com/s8sg/mini-loan-app/app/app_errors"
+	"github.com/s8sg/mini-loan-app/app/controller/dto"
+	"github.com/s8sg/mini-loan-app/app/service"
+	"log"
+	"net/http"
+)
+
+type MfaController struct {
+	mfaService service.MfaService
+}
+
+func InitMfaController(mfaService service.MfaService) *MfaController {
+	mfaController := &MfaController{
+		mfaService: mfaService,
+	}
+	return mfaController
+}
+
+// EnrollHandler Enroll TOTP for the admin
+// @Summary      Enroll TOTP for the admin
+// @Description  Responds with a new TOTP secret and otpauth URI for the authenticator app, MFA is enabled with /admin/mfa/activate
+// @Tags         MFA
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Produce      json
+// @Success      200 {object} dto.MfaEnrollResponse
+// @Failure      401 {object} app_errors.ErrorResponse
+// @Failure      409 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/mfa/enroll [post]
+func (h *MfaController) EnrollHandler(c *gin.Context) {
+	authContext, ok := getAuthContext(c)
+	if !ok {
+		log.Printf("EnrollHandler: auth context not initialized\n")
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	mfaEnrollResponse, err := h.mfaService.Enroll(authContext)
+	if err != nil {
+		log.Printf("EnrollHandler: failed to enroll mfa %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mfaEnrollResponse)
+}
+
+// ActivateHandler Activate TOTP for the admin
+// @Summary      Activate TOTP for the admin
+// @Description  Enables MFA with a code of the enrolled secret, responds with the single use recovery codes.
+// @Description  Login again to get a bearer token for the admin routes
+// @Tags         MFA
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Param        data body dto.MfaActivateRequest true "code is mandatory"
+// @Produce      json
+// @Success      200 {object} dto.RecoveryCodesResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      401 {object} app_errors.ErrorResponse
+// @Failure      409 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/mfa/activate [post]
+func (h *MfaController) ActivateHandler(c *gin.Context) {
+	activateRequest := &dto.MfaActivateRequest{}
+	err := c.BindJSON(activateRequest)
+	if err != nil {
+		log.Printf("ActivateHandler: failed to parse request, error %v\n", err)
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	authContext, ok := getAuthContext(c)
+	if !ok {
+		log.Printf("ActivateHandler: auth context not initialized\n")
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	recoveryCodesResponse, err := h.mfaService.Activate(authContext, activateRequest)
+	if err != nil {
+		log.Printf("ActivateHandler: failed to activate mfa %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, recoveryCodesResponse)
+}
+
+// RegenerateRecoveryCodesHandler Regenerate the recovery codes of the admin
+// @Summary      Regenerate the recovery codes of the admin
+// @Description  Responds with new single use recovery codes, the previous codes can't be used anymore
+// @Tags         MFA
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Produce      json
+// @Success      200 {object} dto.RecoveryCodesResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      401 {object} app_errors.ErrorResponse
+// @Failure      403 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/mfa/recovery-codes [post]
+func (h *MfaController) RegenerateRecoveryCodesHandler(c *gin.Context) {
+	authContext, ok := getAuthContext(c)
+	if !ok {
+		log.Printf("RegenerateRecoveryCodesHandler: auth context not initialized\n")
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	recoveryCodesResponse, err := h.mfaService.RegenerateRecoveryCodes(authContext)
+	if err != nil {
+		log.Printf("RegenerateRecoveryCodesHandler: failed to regenerate recovery codes %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, recoveryCodesResponse)
+}
+
+// ResetMfaHandler Reset MFA of a user
+// @Summary      Reset MFA of a user
+// @Description  disables MFA of a user who lost the authenticator and the recovery codes, the user has to enroll again
+// @Tags         MFA
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Param        data body dto.MfaResetRequest true "username is mandatory"
+// @Produce      json
+// @Success      200 {object} dto.GenericSuccessResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      404 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/user/mfa/reset [post]
+func (h *MfaController) ResetMfaHandler(c *gin.Context) {
+	resetRequest := &dto.MfaResetRequest{}
+	err := c.BindJSON(resetRequest)
+	if err != nil {
+		log.Printf("ResetMfaHandler: failed to parse request, error %v\n", err)
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	err = h.mfaService.ResetMfa(resetRequest)
+	if err != nil {
+		log.Printf("ResetMfaHandler: failed to reset mfa %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.GenericSuccessResponse{Message: "successfully completed"})
+}
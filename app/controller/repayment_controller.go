@@ -23,7 +23,7 @@ func InitRepaymentController(repaymentService service.RepaymentService) *Repayme
 
 // RepayLoanHandler Repay a repayment of loan
 // @Summary      Repay a repayment of loan
-// @Description  repay a repayment, mark loan as paid when all repayment paid
+// @Description  repay a repayment partially or in full, the excess pays the following repayments, mark loan as paid when all repayment paid
 // @Tags         Loans
 // @accept       json
 // @Param        Authorization header  string true "Bearer admin-token"
@@ -61,3 +61,100 @@ func (h *RepaymentController) RepayLoanHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, &dto.GenericSuccessResponse{Message: "successfully completed"})
 }
+
+// PayLoanHandler Pay a loan
+// @Summary      Pay a loan
+// @Description  pay the oldest due repayments of the loan first, the fees, interest and principal are paid in the order of the configured waterfall
+// @Tags         Loans
+// @accept       json
+// @Param        Authorization header  string true "Bearer customer-token"
+// @Param        id path string true "loan id"
+// @Param        data body dto.LoanPayRequest true "loan pay request"
+// @Produce      json
+// @Success      200 {object} dto.PaymentDetails
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      404 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /user/loan/{id}/pay [post]
+func (h *RepaymentController) PayLoanHandler(c *gin.Context) {
+	loanPayRequest := &dto.LoanPayRequest{}
+	err := c.BindJSON(loanPayRequest)
+	if err != nil {
+		log.Printf("PayLoanHandler: failed to parse request, error %v\n", err)
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	userIdContext, ok := c.Get("id")
+	if !ok {
+		log.Printf("PayLoanHandler: user context not initialized\n")
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	customerId := fmt.Sprint(userIdContext)
+
+	payment, err := h.repaymentService.PayLoan(customerId, c.Param("id"), loanPayRequest)
+	if err != nil {
+		log.Printf("PayLoanHandler: failed to pay loan %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payment)
+}
+
+// GetLoanPaymentsHandler Get the payments of a loan
+// @Summary      Get the payments of a loan
+// @Description  Responds with the payments of a loan of the customer and the repayments each payment paid
+// @Tags         Loans
+// @accept       json
+// @Param        Authorization header  string true "Bearer customer-token"
+// @Param        id path string true "loan id"
+// @Produce      json
+// @Success      200 {object} dto.GetLoanPaymentsResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /user/loan/{id}/payments [get]
+func (h *RepaymentController) GetLoanPaymentsHandler(c *gin.Context) {
+	userIdContext, ok := c.Get("id")
+	if !ok {
+		log.Printf("GetLoanPaymentsHandler: user context not initialized\n")
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	customerId := fmt.Sprint(userIdContext)
+
+	payments, err := h.repaymentService.GetLoanPayments(customerId, c.Param("id"))
+	if err != nil {
+		log.Printf("GetLoanPaymentsHandler: failed to get loan payments %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetLoanPaymentsResponse{Payments: payments})
+}
+
+// GetAnyLoanPaymentsHandler Get the payments of any loan
+// @Summary      Get the payments of any loan
+// @Description  Responds with the payments received for a loan with the amount, the channel and the repayments each payment paid
+// @Tags         Loan Approval
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Param        id path string true "loan id"
+// @Produce      json
+// @Success      200 {object} dto.GetLoanPaymentsResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/loan/{id}/payments [get]
+func (h *RepaymentController) GetAnyLoanPaymentsHandler(c *gin.Context) {
+	payments, err := h.repaymentService.GetLoanPayments("", c.Param("id"))
+	if err != nil {
+		log.Printf("GetAnyLoanPaymentsHandler: failed to get loan payments %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetLoanPaymentsResponse{Payments: payments})
+}
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	serverError "github.com/s8sg/mini-loan-app/app/app_errors"
+	"github.com/s8sg/mini-loan-app/app/controller/dto"
+	"github.com/s8sg/mini-loan-app/app/service"
+	"log"
+	"net/http"
+)
+
+type RoleController struct {
+	roleService service.RoleService
+}
+
+func InitRoleController(roleService service.RoleService) *RoleController {
+	roleController := &RoleController{
+		roleService: roleService,
+	}
+	return roleController
+}
+
+// GetRolesHandler Get all roles
+// @Summary      Get all roles
+// @Description  Responds with all roles and the permissions granted to them
+// @Tags         Role Management
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Produce      json
+// @Success      200 {object} dto.GetAllRolesResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/roles [get]
+func (h *RoleController) GetRolesHandler(c *gin.Context) {
+	roleDetailsList, err := h.roleService.GetAllRoles()
+	if err != nil {
+		log.Printf("GetRolesHandler: failed to get roles %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllRolesResponse{Roles: roleDetailsList})
+}
+
+// GetPermissionsHandler Get all permissions
+// @Summary      Get all permissions
+// @Description  Responds with all permissions that can be granted to roles
+// @Tags         Role Management
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Produce      json
+// @Success      200 {object} dto.GetAllPermissionsResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/permissions [get]
+func (h *RoleController) GetPermissionsHandler(c *gin.Context) {
+	permissionDetailsList, err := h.roleService.GetAllPermissions()
+	if err != nil {
+		log.Printf("GetPermissionsHandler: failed to get permissions %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GetAllPermissionsResponse{Permissions: permissionDetailsList})
+}
+
+// SaveRoleHandler Create or update a role
+// @Summary      Create or update a role
+// @Description  create a role or replace the permissions of an existing role
+// @Tags         Role Management
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Param        data body dto.RoleSaveRequest true "role save request"
+// @Produce      json
+// @Success      200 {object} dto.GenericSuccessResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/role [put]
+func (h *RoleController) SaveRoleHandler(c *gin.Context) {
+	roleSaveRequest := &dto.RoleSaveRequest{}
+	err := c.BindJSON(roleSaveRequest)
+	if err != nil {
+		log.Printf("SaveRoleHandler: failed to parse request, error %v\n", err)
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	err = h.roleService.SaveRole(roleSaveRequest)
+	if err != nil {
+		log.Printf("SaveRoleHandler: failed to save role %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.GenericSuccessResponse{Message: "successfully completed"})
+}
+
+// UpdateUserRolesHandler Update the roles of a user
+// @Summary      Update the roles of a user
+// @Description  replace the staff roles of a user, active sessions get the new roles on refresh
+// @Tags         Role Management
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Param        data body dto.UserRolesRequest true "user roles request"
+// @Produce      json
+// @Success      200 {object} dto.GenericSuccessResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      404 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/user/roles [post]
+func (h *RoleController) UpdateUserRolesHandler(c *gin.Context) {
+	userRolesRequest := &dto.UserRolesRequest{}
+	err := c.BindJSON(userRolesRequest)
+	if err != nil {
+		log.Printf("UpdateUserRolesHandler: failed to parse request, error %v\n", err)
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	err = h.roleService.UpdateUserRoles(userRolesRequest)
+	if err != nil {
+		log.Printf("UpdateUserRolesHandler: failed to update user roles %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.GenericSuccessResponse{Message: "successfully completed"})
+}
+
+// UpdateApprovalLimitHandler Update the approval limit of a user
+// @Summary      Update the approval limit of a user
+// @Description  set the highest loan amount a staff user can approve, a null approval-limit removes the limit
+// @Tags         Role Management
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Param        data body dto.ApprovalLimitRequest true "approval limit request"
+// @Produce      json
+// @Success      200 {object} dto.GenericSuccessResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      404 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/user/approval-limit [post]
+func (h *RoleController) UpdateApprovalLimitHandler(c *gin.Context) {
+	approvalLimitRequest := &dto.ApprovalLimitRequest{}
+	err := c.BindJSON(approvalLimitRequest)
+	if err != nil {
+		log.Printf("UpdateApprovalLimitHandler: failed to parse request, error %v\n", err)
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	err = h.roleService.UpdateApprovalLimit(approvalLimitRequest)
+	if err != nil {
+		log.Printf("UpdateApprovalLimitHandler: failed to update approval limit %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.GenericSuccessResponse{Message: "successfully completed"})
+}
+
+// CreateStaffUserHandler Create a staff user
+// @Summary      Create a staff user
+// @Description  create a user with staff roles, staff users login with /auth/admin/login
+// @Tags         Role Management
+// @accept       json
+// @Param        Authorization header  string true "Bearer admin-token"
+// @Param        data body dto.StaffUserCreateRequest true "staff user create request"
+// @Produce      json
+// @Success      201 {object} dto.GenericSuccessResponse
+// @Failure      400 {object} app_errors.ErrorResponse
+// @Failure      409 {object} app_errors.ErrorResponse
+// @Failure      500 {object} app_errors.ErrorResponse
+// @Router       /admin/user [post]
+func (h *RoleController) CreateStaffUserHandler(c *gin.Context) {
+	staffUserCreateRequest := &dto.StaffUserCreateRequest{}
+	err := c.BindJSON(staffUserCreateRequest)
+	if err != nil {
+		log.Printf("CreateStaffUserHandler: failed to parse request, error %v\n", err)
+		serverError.RespondWithError(c, serverError.BadRequest)
+		return
+	}
+
+	err = h.roleService.CreateStaffUser(staffUserCreateRequest)
+	if err != nil {
+		log.Printf("CreateStaffUserHandler: failed to create staff user %v\n", err)
+		serverError.RespondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, &dto.GenericSuccessResponse{Message: "successfully completed"})
+}
@@ -15,9 +15,29 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/admin/loan/approve": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Responds with the public keys (JWKS) used to verify the bearer tokens, empty when tokens are signed with HMAC",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Login"
+                ],
+                "summary": "Public keys to verify bearer tokens",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.JSONWebKeySet"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/api-key": {
             "post": {
-                "description": "approve a loan",
+                "description": "Issue an api key for a machine client, the key is only returned in this response.\nUse it with the header \"Authorization: ApiKey \u003ckey\u003e\"",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,9 +45,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Loan Approval"
+                    "API Key Management"
                 ],
-                "summary": "Approve a loan",
+                "summary": "Issue an api key",
                 "parameters": [
                     {
                         "type": "string",
@@ -37,20 +57,20 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "loan approval request",
+                        "description": "api key create request",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.LoanApproveRequest"
+                            "$ref": "#/definitions/dto.ApiKeyCreateRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/dto.GenericSuccessResponse"
+                            "$ref": "#/definitions/dto.ApiKeyCreateResponse"
                         }
                     },
                     "400": {
@@ -59,8 +79,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/app_errors.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/app_errors.ErrorResponse"
                         }
@@ -74,9 +94,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/admin/login": {
-            "post": {
-                "description": "Responds with the bearer token with admin role",
+        "/admin/api-key/{id}": {
+            "delete": {
+                "description": "revoke an api key, requests with the key are rejected afterwards",
                 "consumes": [
                     "application/json"
                 ],
@@ -84,29 +104,34 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Login"
+                    "API Key Management"
                 ],
-                "summary": "Login user as an Admin",
+                "summary": "Revoke an api key",
                 "parameters": [
                     {
-                        "description": "username is mandatory",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.LoginRequest"
-                        }
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "api key id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.LoginResponse"
+                            "$ref": "#/definitions/dto.GenericSuccessResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/app_errors.ErrorResponse"
                         }
@@ -120,9 +145,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/customer/login": {
-            "post": {
-                "description": "Responds with the bearer token with customer role",
+        "/admin/api-keys": {
+            "get": {
+                "description": "Responds with all api keys with their permissions, expiry and last usage",
                 "consumes": [
                     "application/json"
                 ],
@@ -130,31 +155,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Login"
+                    "API Key Management"
                 ],
-                "summary": "Login user as a Customer",
+                "summary": "Get all api keys",
                 "parameters": [
                     {
-                        "description": "username is mandatory",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.LoginRequest"
-                        }
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.LoginResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                            "$ref": "#/definitions/dto.GetAllApiKeysResponse"
                         }
                     },
                     "500": {
@@ -166,9 +183,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/loan": {
+        "/admin/customer/disable": {
             "post": {
-                "description": "Create a loan for a customer, responds with the newly created loan details",
+                "description": "disable a customer, disabled customers can't create loans",
                 "consumes": [
                     "application/json"
                 ],
@@ -176,24 +193,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Loans"
+                    "Customer Management"
                 ],
-                "summary": "Create a loan for a customer",
+                "summary": "Disable a customer",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Bearer customer-token",
+                        "description": "Bearer admin-token",
                         "name": "Authorization",
                         "in": "header",
                         "required": true
                     },
                     {
-                        "description": "loan creation request",
+                        "description": "customer disable request",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.LoanCreateRequest"
+                            "$ref": "#/definitions/dto.CustomerStatusRequest"
                         }
                     }
                 ],
@@ -201,7 +218,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.LoanDetails"
+                            "$ref": "#/definitions/dto.GenericSuccessResponse"
                         }
                     },
                     "400": {
@@ -210,6 +227,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/app_errors.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -219,9 +242,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/loan/repayment": {
+        "/admin/customer/enable": {
             "post": {
-                "description": "repay a repayment, mark loan as paid when all repayment paid",
+                "description": "enable a disabled customer",
                 "consumes": [
                     "application/json"
                 ],
@@ -229,9 +252,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Loans"
+                    "Customer Management"
                 ],
-                "summary": "Repay a repayment of loan",
+                "summary": "Enable a customer",
                 "parameters": [
                     {
                         "type": "string",
@@ -241,12 +264,12 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "loan repayment request",
+                        "description": "customer enable request",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.LoanRepaymentRequest"
+                            "$ref": "#/definitions/dto.CustomerStatusRequest"
                         }
                     }
                 ],
@@ -278,9 +301,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/loans": {
+        "/admin/customer/{id}": {
             "get": {
-                "description": "Responds with the all loan details belongs to customer",
+                "description": "Responds with the customer profile",
                 "consumes": [
                     "application/json"
                 ],
@@ -288,27 +311,34 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Loans"
+                    "Customer Management"
                 ],
-                "summary": "Get all loans for a customer",
+                "summary": "Get a customer",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Bearer customer-token",
+                        "description": "Bearer admin-token",
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "customer id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.GetAllLoansResponse"
+                            "$ref": "#/definitions/dto.CustomerDetails"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/app_errors.ErrorResponse"
                         }
@@ -320,74 +350,3079 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "app_errors.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string"
-                }
-            }
-        },
-        "dto.GenericSuccessResponse": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string",
-                    "example": "successfully completed"
+            },
+            "delete": {
+                "description": "delete a customer and its login, customers with loans can only be disabled",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customer Management"
+                ],
+                "summary": "Delete a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "customer id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GenericSuccessResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "dto.GetAllLoansResponse": {
-            "type": "object",
-            "properties": {
-                "loans": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.LoanDetails"
+        "/admin/customer/{id}/loans": {
+            "get": {
+                "description": "Responds with the all loan details belongs to the customer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customer Management"
+                ],
+                "summary": "Get all loans of a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "customer id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetAllLoansResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "dto.LoanApproveRequest": {
-            "type": "object",
-            "properties": {
-                "loan-id": {
-                    "type": "string",
-                    "example": "b9348325-d798-4f81-85fc-336220380d4f"
+        "/admin/customers": {
+            "get": {
+                "description": "Responds with all the customers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customer Management"
+                ],
+                "summary": "Get all customers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetAllCustomersResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "dto.LoanCreateRequest": {
-            "type": "object",
-            "properties": {
-                "amount": {
+        "/admin/impersonate": {
+            "post": {
+                "description": "Responds with a short-lived read only bearer token of the customer for support, mutating requests\nmade with the token are rejected (403) and every request is logged with the admin and the customer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Login"
+                ],
+                "summary": "View the app as a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "username is mandatory",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ImpersonationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ImpersonationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/loan-product": {
+            "post": {
+                "description": "Create a loan product offered to the customers, responds with the newly created loan product",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loan Product Management"
+                ],
+                "summary": "Create a loan product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "loan product save request",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoanProductSaveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoanProductDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/loan-product/{id}": {
+            "put": {
+                "description": "replace the terms of a loan product and increment its version, existing loans keep their terms",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loan Product Management"
+                ],
+                "summary": "Update a loan product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "loan product id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "loan product save request",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoanProductSaveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoanProductDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/loan-products": {
+            "get": {
+                "description": "Responds with all loan products including the inactive ones",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loan Product Management"
+                ],
+                "summary": "Get all loan products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetAllLoanProductsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/loan/approve": {
+            "post": {
+                "description": "approve a pending loan, loans above the approval threshold are approved once the quorum of distinct\nadmins approved them. With an adjusted amount, term or interest rate the repayment schedule is\nregenerated and the loan is OFFERED to the customer, the loan is approved once the customer accepts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loan Approval"
+                ],
+                "summary": "Approve a loan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "loan approval request",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoanApproveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GenericSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/loan/reject": {
+            "post": {
+                "description": "reject a pending loan with a reason, the reason and the notes are shown to the customer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loan Approval"
+                ],
+                "summary": "Reject a loan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "loan rejection request",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoanRejectRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GenericSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/loan/{id}/approvals": {
+            "get": {
+                "description": "Responds with the approvals of a loan, loans above the approval threshold list the partial approvals\nuntil the quorum of distinct admins is reached",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loan Approval"
+                ],
+                "summary": "Get the approvals of a loan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "loan id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetLoanApprovalsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/loan/{id}/history": {
+            "get": {
+                "description": "Responds with the status transitions of a loan with the actor, the time and the reason of each transition",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loan Approval"
+                ],
+                "summary": "Get the status history of any loan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "loan id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetLoanStatusHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/loan/{id}/payments": {
+            "get": {
+                "description": "Responds with the payments received for a loan with the amount, the channel and the repayments each payment paid",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loan Approval"
+                ],
+                "summary": "Get the payments of any loan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "loan id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetLoanPaymentsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/login/unlock": {
+            "post": {
+                "description": "clears the failed login attempts and the lockout of the username and/or the client ip",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Login"
+                ],
+                "summary": "Unlock a login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "username or ip is mandatory",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginUnlockRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GenericSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/mfa/activate": {
+            "post": {
+                "description": "Enables MFA with a code of the enrolled secret, responds with the single use recovery codes.\nLogin again to get a bearer token for the admin routes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "Activate TOTP for the admin",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "code is mandatory",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.MfaActivateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/mfa/enroll": {
+            "post": {
+                "description": "Responds with a new TOTP secret and otpauth URI for the authenticator app, MFA is enabled with /admin/mfa/activate",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "Enroll TOTP for the admin",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MfaEnrollResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/mfa/recovery-codes": {
+            "post": {
+                "description": "Responds with new single use recovery codes, the previous codes can't be used anymore",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "Regenerate the recovery codes of the admin",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RecoveryCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/permissions": {
+            "get": {
+                "description": "Responds with all permissions that can be granted to roles",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role Management"
+                ],
+                "summary": "Get all permissions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetAllPermissionsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/role": {
+            "put": {
+                "description": "create a role or replace the permissions of an existing role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role Management"
+                ],
+                "summary": "Create or update a role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "role save request",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RoleSaveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GenericSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/roles": {
+            "get": {
+                "description": "Responds with all roles and the permissions granted to them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role Management"
+                ],
+                "summary": "Get all roles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetAllRolesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/user": {
+            "post": {
+                "description": "create a user with staff roles, staff users login with /auth/admin/login",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role Management"
+                ],
+                "summary": "Create a staff user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "staff user create request",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.StaffUserCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GenericSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/user/approval-limit": {
+            "post": {
+                "description": "set the highest loan amount a staff user can approve, a null approval-limit removes the limit",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role Management"
+                ],
+                "summary": "Update the approval limit of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "approval limit request",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ApprovalLimitRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GenericSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/user/mfa/reset": {
+            "post": {
+                "description": "disables MFA of a user who lost the authenticator and the recovery codes, the user has to enroll again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "Reset MFA of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "username is mandatory",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.MfaResetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GenericSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/user/roles": {
+            "post": {
+                "description": "replace the staff roles of a user, active sessions get the new roles on refresh",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role Management"
+                ],
+                "summary": "Update the roles of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "user roles request",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserRolesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GenericSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/admin/login": {
+            "post": {
+                "description": "Responds with the bearer token with admin role, admins with MFA get a mfa-token to complete the login\nwith /auth/admin/login/mfa. Admin routes (except /admin/mfa) require a login with MFA",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Login"
+                ],
+                "summary": "Login user as an Admin",
+                "parameters": [
+                    {
+                        "description": "username and secret are mandatory",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/admin/login/mfa": {
+            "post": {
+                "description": "Responds with the bearer token with admin role, code is a TOTP code or a recovery code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Login"
+                ],
+                "summary": "Complete the admin login with the second factor",
+                "parameters": [
+                    {
+                        "description": "mfa-token and code are mandatory",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.MfaLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/customer/login": {
+            "post": {
+                "description": "Responds with the bearer token with customer role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Login"
+                ],
+                "summary": "Login user as a Customer",
+                "parameters": [
+                    {
+                        "description": "username and secret are mandatory",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/customer/signup": {
+            "post": {
+                "description": "Creates the customer login and profile, responds with the customer profile",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Register a new customer",
+                "parameters": [
+                    {
+                        "description": "customer signup request",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CustomerSignupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CustomerDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "Revokes the session of the refresh token, bearer tokens of the session are rejected afterwards",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Login"
+                ],
+                "summary": "Revoke the session",
+                "parameters": [
+                    {
+                        "description": "refresh-token is mandatory",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GenericSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Responds with a new bearer token and refresh token, the provided refresh token can't be used again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Login"
+                ],
+                "summary": "Rotate the refresh token",
+                "parameters": [
+                    {
+                        "description": "refresh-token is mandatory",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/revoke": {
+            "post": {
+                "description": "Revokes a single bearer token, the token is rejected afterwards while its session stays active",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Login"
+                ],
+                "summary": "Revoke a bearer token",
+                "parameters": [
+                    {
+                        "description": "token is mandatory",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RevokeTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GenericSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/loan": {
+            "post": {
+                "description": "Create a loan for a customer, responds with the newly created loan details",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loans"
+                ],
+                "summary": "Create a loan for a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer customer-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "loan creation request",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoanCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoanDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/loan-products": {
+            "get": {
+                "description": "Responds with the active loan products a customer can create a loan for",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loans"
+                ],
+                "summary": "Get the offered loan products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer customer-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetAllLoanProductsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/loan/cancel": {
+            "post": {
+                "description": "Cancel a pending loan, or an approved loan within the cooling-off period if nothing has been repaid",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loans"
+                ],
+                "summary": "Cancel a loan of a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer customer-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "loan cancellation request",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoanCancelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GenericSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/loan/offer": {
+            "post": {
+                "description": "Accept the adjusted terms offered for a loan of the customer, which approves the loan, or decline them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loans"
+                ],
+                "summary": "Accept or decline a counter-offer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer customer-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "counter-offer decision request",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoanOfferDecisionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GenericSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/loan/quote": {
+            "post": {
+                "description": "Responds with the repayment schedule, total interest and APR of the loan without creating it.\nThe loan is created at the quoted terms with the quote-id until the quote expires",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loans"
+                ],
+                "summary": "Quote a loan for a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer customer-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "loan creation request",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoanCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoanQuoteDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/loan/repayment": {
+            "post": {
+                "description": "repay a repayment partially or in full, the excess pays the following repayments, mark loan as paid when all repayment paid",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loans"
+                ],
+                "summary": "Repay a repayment of loan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "loan repayment request",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoanRepaymentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GenericSuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/loan/{id}/history": {
+            "get": {
+                "description": "Responds with the status transitions of a loan of the customer in the order they were made",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loans"
+                ],
+                "summary": "Get the status history of a loan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer customer-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "loan id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetLoanStatusHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/loan/{id}/pay": {
+            "post": {
+                "description": "pay the oldest due repayments of the loan first, the fees, interest and principal are paid in the order of the configured waterfall",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loans"
+                ],
+                "summary": "Pay a loan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer customer-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "loan id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "loan pay request",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoanPayRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PaymentDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/loan/{id}/payments": {
+            "get": {
+                "description": "Responds with the payments of a loan of the customer and the repayments each payment paid",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loans"
+                ],
+                "summary": "Get the payments of a loan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer customer-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "loan id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetLoanPaymentsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/loans": {
+            "get": {
+                "description": "Responds with the all loan details belongs to customer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Loans"
+                ],
+                "summary": "Get all loans for a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer customer-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetAllLoansResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/profile": {
+            "get": {
+                "description": "Responds with the profile of the logged in customer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Get the profile of the customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer customer-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CustomerDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Updates the profile of the logged in customer, responds with the updated profile",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Customers"
+                ],
+                "summary": "Update the profile of the customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer customer-token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "customer profile update request",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CustomerUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CustomerDetails"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app_errors.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "app_errors.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ApiKeyCreateRequest": {
+            "description": "api key create request, the key can only be granted permissions of the admin creating it",
+            "type": "object",
+            "properties": {
+                "expires-in-days": {
+                    "type": "integer",
+                    "example": 90
+                },
+                "name": {
+                    "type": "string",
+                    "example": "nightly-reconciliation"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "loan:read:any",
+                        "customer:read"
+                    ]
+                }
+            }
+        },
+        "dto.ApiKeyCreateResponse": {
+            "description": "api key create response, the key is only returned once and can't be retrieved again",
+            "type": "object",
+            "properties": {
+                "api-key": {
+                    "$ref": "#/definitions/dto.ApiKeyDetails"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "c6b0bd7e-6f0a-4f2a-9c4e-0e7a1e0b6a11.dummy-value"
+                }
+            }
+        },
+        "dto.ApiKeyDetails": {
+            "type": "object",
+            "properties": {
+                "created-by": {
+                    "type": "string",
+                    "example": "admin"
+                },
+                "created-timestamp": {
+                    "type": "string",
+                    "example": "2023-03-10T09:58:40.011177Z"
+                },
+                "expires-at": {
+                    "type": "string",
+                    "example": "2023-06-10T09:58:40.011177Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "c6b0bd7e-6f0a-4f2a-9c4e-0e7a1e0b6a11"
+                },
+                "last-used-at": {
+                    "type": "string",
+                    "example": "2023-03-10T09:58:40.011177Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "nightly-reconciliation"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "loan:read:any",
+                        "customer:read"
+                    ]
+                },
+                "revoked": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "updated-timestamp": {
+                    "type": "string",
+                    "example": "2023-03-10T09:58:40.011177Z"
+                }
+            }
+        },
+        "dto.ApprovalLimitRequest": {
+            "description": "approval limit update request, approval-limit is the highest loan amount the staff user can approve, null removes the limit",
+            "type": "object",
+            "properties": {
+                "approval-limit": {
+                    "type": "number",
+                    "example": 50000
+                },
+                "username": {
+                    "type": "string",
+                    "example": "officer1"
+                }
+            }
+        },
+        "dto.CustomerDetails": {
+            "type": "object",
+            "properties": {
+                "created-timestamp": {
+                    "type": "string",
+                    "example": "2023-03-10T09:58:40.011177Z"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "user1"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+6591234567"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ACTIVE"
+                },
+                "updated-timestamp": {
+                    "type": "string",
+                    "example": "2023-03-10T09:58:40.011177Z"
+                }
+            }
+        },
+        "dto.CustomerSignupRequest": {
+            "description": "customer signup request (phone is optional)",
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+6591234567"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "dummy-value"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "user1"
+                }
+            }
+        },
+        "dto.CustomerStatusRequest": {
+            "type": "object",
+            "properties": {
+                "customer-id": {
+                    "type": "string",
+                    "example": "user1"
+                }
+            }
+        },
+        "dto.CustomerUpdateRequest": {
+            "description": "customer profile update request (phone is optional)",
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john@example.com"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+6591234567"
+                }
+            }
+        },
+        "dto.GenericSuccessResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "successfully completed"
+                }
+            }
+        },
+        "dto.GetAllApiKeysResponse": {
+            "type": "object",
+            "properties": {
+                "api-keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ApiKeyDetails"
+                    }
+                }
+            }
+        },
+        "dto.GetAllCustomersResponse": {
+            "type": "object",
+            "properties": {
+                "customers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CustomerDetails"
+                    }
+                }
+            }
+        },
+        "dto.GetAllLoanProductsResponse": {
+            "type": "object",
+            "properties": {
+                "loan-products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.LoanProductDetails"
+                    }
+                }
+            }
+        },
+        "dto.GetAllLoansResponse": {
+            "type": "object",
+            "properties": {
+                "loans": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.LoanDetails"
+                    }
+                }
+            }
+        },
+        "dto.GetAllPermissionsResponse": {
+            "type": "object",
+            "properties": {
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PermissionDetails"
+                    }
+                }
+            }
+        },
+        "dto.GetAllRolesResponse": {
+            "type": "object",
+            "properties": {
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RoleDetails"
+                    }
+                }
+            }
+        },
+        "dto.GetLoanApprovalsResponse": {
+            "type": "object",
+            "properties": {
+                "approvals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.LoanApprovalDetails"
+                    }
+                }
+            }
+        },
+        "dto.GetLoanPaymentsResponse": {
+            "type": "object",
+            "properties": {
+                "payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PaymentDetails"
+                    }
+                }
+            }
+        },
+        "dto.GetLoanStatusHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "history": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.LoanStatusHistory"
+                    }
+                }
+            }
+        },
+        "dto.ImpersonationRequest": {
+            "description": "impersonation request (username of the customer is mandatory)",
+            "type": "object",
+            "properties": {
+                "username": {
+                    "type": "string",
+                    "example": "user1"
+                }
+            }
+        },
+        "dto.ImpersonationResponse": {
+            "description": "read only bearer token of the customer, it can't be refreshed",
+            "type": "object",
+            "properties": {
+                "expires-in": {
+                    "type": "integer",
+                    "example": 900
+                },
+                "token": {
+                    "type": "string",
+                    "example": "\u003cbearer token\u003e"
+                }
+            }
+        },
+        "dto.JSONWebKey": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string",
+                    "example": "RS256"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string",
+                    "example": "AQAB"
+                },
+                "kid": {
+                    "type": "string",
+                    "example": "key-2023-03"
+                },
+                "kty": {
+                    "type": "string",
+                    "example": "RSA"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string",
+                    "example": "sig"
+                },
+                "x": {
+                    "type": "string"
+                },
+                "y": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.JSONWebKeySet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.JSONWebKey"
+                    }
+                }
+            }
+        },
+        "dto.LoanApprovalDetails": {
+            "type": "object",
+            "properties": {
+                "approver-id": {
+                    "type": "string",
+                    "example": "admin"
+                },
+                "approver-type": {
+                    "type": "string",
+                    "example": "admin"
+                },
+                "created-timestamp": {
+                    "type": "string",
+                    "example": "2023-03-10T09:58:40.011177Z"
+                },
+                "loan-id": {
+                    "type": "string",
+                    "example": "b9348325-d798-4f81-85fc-336220380d4f"
+                }
+            }
+        },
+        "dto.LoanApproveRequest": {
+            "description": "loan approval request, with an adjusted amount, term or interest-rate the loan is offered to the customer at the adjusted terms (counter-offer) and approved once the customer accepts the offer",
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 200000
+                },
+                "interest-rate": {
+                    "type": "number",
+                    "example": 14.5
+                },
+                "loan-id": {
+                    "type": "string",
+                    "example": "b9348325-d798-4f81-85fc-336220380d4f"
+                },
+                "term": {
+                    "type": "integer",
+                    "example": 6
+                }
+            }
+        },
+        "dto.LoanCancelRequest": {
+            "description": "loan cancellation request, pending loans can be cancelled and approved loans within the cooling-off period if nothing has been repaid",
+            "type": "object",
+            "properties": {
+                "loan-id": {
+                    "type": "string",
+                    "example": "b9348325-d798-4f81-85fc-336220380d4f"
+                }
+            }
+        },
+        "dto.LoanCreateRequest": {
+            "description": "loan creation request, interest-rate is the annual rate in percent (default 0), interest-method is FLAT (default), DECLINING_BALANCE or INTEREST_ONLY, currency (ISO 4217) is the configured default currency when empty, repayment-frequency is WEEKLY (default), BI_WEEKLY, SEMI_MONTHLY or MONTHLY. With a product-id the currency, interest and repayment frequency are set by the loan product. With a quote-id the loan is created at the terms of the quote, the other fields must be empty",
+            "type": "object",
+            "properties": {
+                "amount": {
                     "type": "number",
                     "example": 300000
                 },
-                "term": {
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "interest-method": {
+                    "type": "string",
+                    "example": "DECLINING_BALANCE"
+                },
+                "interest-rate": {
+                    "type": "number",
+                    "example": 12.5
+                },
+                "product-id": {
+                    "type": "string",
+                    "example": "5f1c2f0e-3b7a-4d55-9d3c-6f4f0a8b2c11"
+                },
+                "quote-id": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                },
+                "repayment-frequency": {
+                    "type": "string",
+                    "example": "MONTHLY"
+                },
+                "term": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "dto.LoanDetails": {
+            "type": "object",
+            "properties": {
+                "approved-at": {
+                    "type": "string",
+                    "example": "2023-03-11T09:58:40.009375Z"
+                },
+                "created-timestamp": {
+                    "type": "string",
+                    "example": "2023-03-10T09:58:40.011177Z"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "customer-id": {
+                    "type": "string",
+                    "example": "user1"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "b9348325-d798-4f81-85fc-336220380d4f"
+                },
+                "interest-method": {
+                    "type": "string",
+                    "example": "DECLINING_BALANCE"
+                },
+                "interest-rate": {
+                    "type": "number",
+                    "example": 12.5
+                },
+                "origination-fee": {
+                    "type": "number",
+                    "example": 1500
+                },
+                "outstanding-amount": {
+                    "type": "number",
+                    "example": 100000
+                },
+                "product-id": {
+                    "type": "string",
+                    "example": "5f1c2f0e-3b7a-4d55-9d3c-6f4f0a8b2c11"
+                },
+                "product-version": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "rejection-notes": {
+                    "type": "string",
+                    "example": "monthly income below the installment"
+                },
+                "rejection-reason": {
+                    "type": "string",
+                    "example": "INSUFFICIENT_INCOME"
+                },
+                "repayment-frequency": {
+                    "type": "string",
+                    "example": "MONTHLY"
+                },
+                "repayments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RepaymentDetails"
+                    }
+                },
+                "start-date": {
+                    "type": "string",
+                    "example": "2023-03-10T09:58:40.009375Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "PENDING"
+                },
+                "term": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "total-amount": {
+                    "type": "number",
+                    "example": 100000
+                },
+                "updated-timestamp": {
+                    "type": "string",
+                    "example": "2023-03-10T09:58:40.011177Z"
+                }
+            }
+        },
+        "dto.LoanOfferDecisionRequest": {
+            "description": "counter-offer decision request, decision is ACCEPT (approves the loan) or DECLINE",
+            "type": "object",
+            "properties": {
+                "decision": {
+                    "type": "string",
+                    "example": "ACCEPT"
+                },
+                "loan-id": {
+                    "type": "string",
+                    "example": "b9348325-d798-4f81-85fc-336220380d4f"
+                }
+            }
+        },
+        "dto.LoanPayRequest": {
+            "description": "loan payment request, the amount pays the oldest due repayments of the loan first, channel is CARD (default), BANK_TRANSFER or DIRECT_DEBIT",
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 1500
+                },
+                "channel": {
+                    "type": "string",
+                    "example": "CARD"
+                }
+            }
+        },
+        "dto.LoanProductDetails": {
+            "type": "object",
+            "properties": {
+                "created-timestamp": {
+                    "type": "string",
+                    "example": "2023-03-10T09:58:40.011177Z"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "unsecured personal loan"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5f1c2f0e-3b7a-4d55-9d3c-6f4f0a8b2c11"
+                },
+                "interest-method": {
+                    "type": "string",
+                    "example": "DECLINING_BALANCE"
+                },
+                "interest-rate": {
+                    "type": "number",
+                    "example": 12.5
+                },
+                "max-amount": {
+                    "type": "number",
+                    "example": 50000
+                },
+                "max-open-loans": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "min-amount": {
+                    "type": "number",
+                    "example": 1000
+                },
+                "min-customer-days": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Personal Loan 12M"
+                },
+                "origination-fee-rate": {
+                    "type": "number",
+                    "example": 1.5
+                },
+                "repayment-frequency": {
+                    "type": "string",
+                    "example": "MONTHLY"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ACTIVE"
+                },
+                "terms": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        6,
+                        12
+                    ]
+                },
+                "updated-timestamp": {
+                    "type": "string",
+                    "example": "2023-03-10T09:58:40.011177Z"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "dto.LoanProductSaveRequest": {
+            "description": "loan product create or update request, interest-rate and origination-fee-rate are in percent, the origination fee is charged with the first repayment. Eligibility: max-open-loans limits the pending and approved loans of the customer, min-customer-days is the minimum days since signup (0 = no limit)",
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "unsecured personal loan"
+                },
+                "interest-method": {
+                    "type": "string",
+                    "example": "DECLINING_BALANCE"
+                },
+                "interest-rate": {
+                    "type": "number",
+                    "example": 12.5
+                },
+                "max-amount": {
+                    "type": "number",
+                    "example": 50000
+                },
+                "max-open-loans": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "min-amount": {
+                    "type": "number",
+                    "example": 1000
+                },
+                "min-customer-days": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Personal Loan 12M"
+                },
+                "origination-fee-rate": {
+                    "type": "number",
+                    "example": 1.5
+                },
+                "repayment-frequency": {
+                    "type": "string",
+                    "example": "MONTHLY"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ACTIVE"
+                },
+                "terms": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        6,
+                        12
+                    ]
+                }
+            }
+        },
+        "dto.LoanQuoteDetails": {
+            "type": "object",
+            "properties": {
+                "approved-at": {
+                    "type": "string",
+                    "example": "2023-03-11T09:58:40.009375Z"
+                },
+                "apr": {
+                    "type": "number",
+                    "example": 15.03
+                },
+                "created-timestamp": {
+                    "type": "string",
+                    "example": "2023-03-10T09:58:40.011177Z"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "customer-id": {
+                    "type": "string",
+                    "example": "user1"
+                },
+                "expires-at": {
+                    "type": "string",
+                    "example": "2023-03-10T10:13:40.011177Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "b9348325-d798-4f81-85fc-336220380d4f"
+                },
+                "interest-method": {
+                    "type": "string",
+                    "example": "DECLINING_BALANCE"
+                },
+                "interest-rate": {
+                    "type": "number",
+                    "example": 12.5
+                },
+                "origination-fee": {
+                    "type": "number",
+                    "example": 1500
+                },
+                "outstanding-amount": {
+                    "type": "number",
+                    "example": 100000
+                },
+                "product-id": {
+                    "type": "string",
+                    "example": "5f1c2f0e-3b7a-4d55-9d3c-6f4f0a8b2c11"
+                },
+                "product-version": {
                     "type": "integer",
                     "example": 1
-                }
-            }
-        },
-        "dto.LoanDetails": {
-            "type": "object",
-            "properties": {
-                "created-timestamp": {
+                },
+                "quote-id": {
                     "type": "string",
-                    "example": "2023-03-10T09:58:40.011177Z"
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
                 },
-                "customer-id": {
+                "rejection-notes": {
                     "type": "string",
-                    "example": "user1"
+                    "example": "monthly income below the installment"
                 },
-                "id": {
+                "rejection-reason": {
                     "type": "string",
-                    "example": "b9348325-d798-4f81-85fc-336220380d4f"
+                    "example": "INSUFFICIENT_INCOME"
+                },
+                "repayment-frequency": {
+                    "type": "string",
+                    "example": "MONTHLY"
                 },
                 "repayments": {
                     "type": "array",
@@ -411,12 +3446,38 @@ const docTemplate = `{
                     "type": "number",
                     "example": 100000
                 },
+                "total-interest": {
+                    "type": "number",
+                    "example": 6593.24
+                },
+                "total-repayment": {
+                    "type": "number",
+                    "example": 107593.24
+                },
                 "updated-timestamp": {
                     "type": "string",
                     "example": "2023-03-10T09:58:40.011177Z"
                 }
             }
         },
+        "dto.LoanRejectRequest": {
+            "description": "loan rejection request, reason is CREDIT_HISTORY, INSUFFICIENT_INCOME, INCOMPLETE_APPLICATION, POLICY or OTHER (notes required). The reason and the notes are shown to the customer",
+            "type": "object",
+            "properties": {
+                "loan-id": {
+                    "type": "string",
+                    "example": "b9348325-d798-4f81-85fc-336220380d4f"
+                },
+                "notes": {
+                    "type": "string",
+                    "example": "monthly income below the installment"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "INSUFFICIENT_INCOME"
+                }
+            }
+        },
         "dto.LoanRepaymentRequest": {
             "type": "object",
             "properties": {
@@ -424,14 +3485,52 @@ const docTemplate = `{
                     "type": "number",
                     "example": 300000
                 },
+                "channel": {
+                    "description": "Channel of the payment, CARD (default), BANK_TRANSFER or DIRECT_DEBIT",
+                    "type": "string",
+                    "example": "CARD"
+                },
                 "repayment-id": {
                     "type": "string",
                     "example": "393be183-ecc3-4a52-a035-f2e8a70d3711"
                 }
             }
         },
+        "dto.LoanStatusHistory": {
+            "type": "object",
+            "properties": {
+                "actor-id": {
+                    "type": "string",
+                    "example": "admin"
+                },
+                "actor-type": {
+                    "type": "string",
+                    "example": "admin"
+                },
+                "created-timestamp": {
+                    "type": "string",
+                    "example": "2023-03-10T09:58:40.011177Z"
+                },
+                "from-status": {
+                    "type": "string",
+                    "example": "PENDING"
+                },
+                "loan-id": {
+                    "type": "string",
+                    "example": "b9348325-d798-4f81-85fc-336220380d4f"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "CREDIT_HISTORY"
+                },
+                "to-status": {
+                    "type": "string",
+                    "example": "APPROVED"
+                }
+            }
+        },
         "dto.LoginRequest": {
-            "description": "login request (Secret is optional)",
+            "description": "login request (username and secret are mandatory)",
             "type": "object",
             "properties": {
                 "secret": {
@@ -445,15 +3544,194 @@ const docTemplate = `{
             }
         },
         "dto.LoginResponse": {
-            "description": "login response with token",
+            "description": "login response with bearer token and the refresh token to rotate it, admins with MFA get only the mfa-token to complete the login with /auth/admin/login/mfa",
             "type": "object",
             "properties": {
+                "expires-in": {
+                    "type": "integer",
+                    "example": 1800
+                },
+                "mfa-required": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "mfa-token": {
+                    "type": "string",
+                    "example": "\u003cmfa token\u003e"
+                },
+                "refresh-token": {
+                    "type": "string",
+                    "example": "\u003crefresh token\u003e"
+                },
                 "token": {
                     "type": "string",
                     "example": "\u003cbearer token\u003e"
                 }
             }
         },
+        "dto.LoginUnlockRequest": {
+            "description": "login unlock request (username or ip is mandatory)",
+            "type": "object",
+            "properties": {
+                "ip": {
+                    "type": "string",
+                    "example": "10.0.0.1"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "user1"
+                }
+            }
+        },
+        "dto.MfaActivateRequest": {
+            "description": "mfa activate request with a code generated by the authenticator app",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "dto.MfaEnrollResponse": {
+            "description": "TOTP secret to add to the authenticator app, otpauth-uri can be rendered as QR code",
+            "type": "object",
+            "properties": {
+                "otpauth-uri": {
+                    "type": "string",
+                    "example": "otpauth://totp/mini-loan-app:admin?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+                }
+            }
+        },
+        "dto.MfaLoginRequest": {
+            "description": "second step of the admin login, code is a TOTP code or a recovery code",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "mfa-token": {
+                    "type": "string",
+                    "example": "\u003cmfa token\u003e"
+                }
+            }
+        },
+        "dto.MfaResetRequest": {
+            "type": "object",
+            "properties": {
+                "username": {
+                    "type": "string",
+                    "example": "admin"
+                }
+            }
+        },
+        "dto.PaymentAllocation": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number",
+                    "example": 600
+                },
+                "fee": {
+                    "type": "number",
+                    "example": 0
+                },
+                "interest": {
+                    "type": "number",
+                    "example": 100
+                },
+                "principal": {
+                    "type": "number",
+                    "example": 500
+                },
+                "repayment-id": {
+                    "type": "string",
+                    "example": "9b02d974-2b09-4e42-8006-5e94ee93659a"
+                }
+            }
+        },
+        "dto.PaymentDetails": {
+            "type": "object",
+            "properties": {
+                "allocations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PaymentAllocation"
+                    }
+                },
+                "amount": {
+                    "type": "number",
+                    "example": 1100
+                },
+                "channel": {
+                    "type": "string",
+                    "example": "CARD"
+                },
+                "created-timestamp": {
+                    "type": "string",
+                    "example": "2023-03-10T09:58:40.011177Z"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "customer-id": {
+                    "type": "string",
+                    "example": "user1"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "2c1e6f4b-8d0a-4f6e-9a3b-5d7c8e9f0a1b"
+                },
+                "loan-id": {
+                    "type": "string",
+                    "example": "b9348325-d798-4f81-85fc-336220380d4f"
+                }
+            }
+        },
+        "dto.PermissionDetails": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "approve loans"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "loan:approve"
+                }
+            }
+        },
+        "dto.RecoveryCodesResponse": {
+            "description": "single use recovery codes, only returned once",
+            "type": "object",
+            "properties": {
+                "recovery-codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ABCD-EFGH-IJKL-MNOP"
+                    ]
+                }
+            }
+        },
+        "dto.RefreshRequest": {
+            "description": "refresh request with the refresh token of the session",
+            "type": "object",
+            "properties": {
+                "refresh-token": {
+                    "type": "string",
+                    "example": "\u003crefresh token\u003e"
+                }
+            }
+        },
         "dto.RepaymentDetails": {
             "type": "object",
             "properties": {
@@ -469,10 +3747,18 @@ const docTemplate = `{
                     "type": "string",
                     "example": "2023-03-17T10:36:48.430739Z"
                 },
+                "fee": {
+                    "type": "number",
+                    "example": 0
+                },
                 "id": {
                     "type": "string",
                     "example": "9b02d974-2b09-4e42-8006-5e94ee93659a"
                 },
+                "interest": {
+                    "type": "number",
+                    "example": 1000
+                },
                 "loan-id": {
                     "type": "string"
                 },
@@ -480,6 +3766,30 @@ const docTemplate = `{
                     "type": "integer",
                     "example": 1
                 },
+                "outstanding-amount": {
+                    "type": "number",
+                    "example": 100000
+                },
+                "paid-amount": {
+                    "type": "number",
+                    "example": 0
+                },
+                "paid-fee": {
+                    "type": "number",
+                    "example": 0
+                },
+                "paid-interest": {
+                    "type": "number",
+                    "example": 0
+                },
+                "paid-principal": {
+                    "type": "number",
+                    "example": 0
+                },
+                "principal": {
+                    "type": "number",
+                    "example": 99000
+                },
                 "status": {
                     "type": "string",
                     "example": "PENDING"
@@ -489,6 +3799,113 @@ const docTemplate = `{
                     "example": "2023-03-10T10:36:48.431463Z"
                 }
             }
+        },
+        "dto.RevokeTokenRequest": {
+            "description": "token revocation request with the bearer token to revoke",
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "\u003cbearer token\u003e"
+                }
+            }
+        },
+        "dto.RoleDetails": {
+            "type": "object",
+            "properties": {
+                "created-timestamp": {
+                    "type": "string",
+                    "example": "2023-03-10T09:58:40.011177Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "staff approving loans"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "credit-officer"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "loan:approve",
+                        "loan:read:any"
+                    ]
+                },
+                "updated-timestamp": {
+                    "type": "string",
+                    "example": "2023-03-10T09:58:40.011177Z"
+                }
+            }
+        },
+        "dto.RoleSaveRequest": {
+            "description": "role create or update request, permissions of an existing role are replaced",
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "staff approving loans"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "credit-officer"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "loan:approve",
+                        "loan:read:any"
+                    ]
+                }
+            }
+        },
+        "dto.StaffUserCreateRequest": {
+            "description": "staff user create request, staff users login with /auth/admin/login",
+            "type": "object",
+            "properties": {
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "credit-officer"
+                    ]
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "dummy-value"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "officer1"
+                }
+            }
+        },
+        "dto.UserRolesRequest": {
+            "description": "user roles update request, roles of the user are replaced",
+            "type": "object",
+            "properties": {
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "credit-officer"
+                    ]
+                },
+                "username": {
+                    "type": "string",
+                    "example": "officer1"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+type ApiKeyDetails struct {
+	ApiKeyId         string     `json:"id" example:"c6b0bd7e-6f0a-4f2a-9c4e-0e7a1e0b6a11"`
+	Name             string     `json:"name" example:"nightly-reconciliation"`
+	KeyHash          string     `json:"-"`
+	Permissions      []string   `json:"permissions" example:"loan:read:any,customer:read"`
+	CreatedBy        string     `json:"created-by" example:"admin"`
+	ExpiresAt        time.Time  `json:"expires-at" example:"2023-06-10T09:58:40.011177Z"`
+	LastUsedAt       *time.Time `json:"last-used-at" example:"2023-03-10T09:58:40.011177Z"`
+	Revoked          bool       `json:"revoked" example:"false"`
+	CreatedTimestamp time.Time  `json:"created-timestamp" example:"2023-03-10T09:58:40.011177Z"`
+	UpdatedTimestamp time.Time  `json:"updated-timestamp" example:"2023-03-10T09:58:40.011177Z"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+const (
+	CustomerStatusActive   = "ACTIVE"
+	CustomerStatusDisabled = "DISABLED"
+)
+
+type CustomerDetails struct {
+	CustomerId       string    `json:"id" example:"user1"`
+	Name             string    `json:"name" example:"John Doe"`
+	Email            string    `json:"email" example:"john@example.com"`
+	Phone            string    `json:"phone" example:"+6591234567"`
+	Status           string    `json:"status" example:"ACTIVE"`
+	CreatedTimestamp time.Time `json:"created-timestamp" example:"2023-03-10T09:58:40.011177Z"`
+	UpdatedTimestamp time.Time `json:"updated-timestamp" example:"2023-03-10T09:58:40.011177Z"`
+}
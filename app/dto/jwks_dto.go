@@ -0,0 +1,19 @@
+package dto
+
+// JSONWebKey public key in JWK format (RFC 7517)
+type JSONWebKey struct {
+	KeyType   string `json:"kty" example:"RSA"`
+	KeyId     string `json:"kid" example:"key-2023-03"`
+	Use       string `json:"use" example:"sig"`
+	Algorithm string `json:"alg" example:"RS256"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty" example:"AQAB"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// JSONWebKeySet set of public keys used to verify the bearer tokens
+type JSONWebKeySet struct {
+	Keys []*JSONWebKey `json:"keys"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+import "time"
+
+// LoanApprovalDetails : approval of a loan by a staff user or api key, loans above the approval threshold are
+// approved once the quorum of distinct admins approved them
+type LoanApprovalDetails struct {
+	LoanId           string    `json:"loan-id" example:"b9348325-d798-4f81-85fc-336220380d4f"`
+	ApproverId       string    `json:"approver-id" example:"admin"`
+	ApproverType     string    `json:"approver-type" example:"admin"`
+	CreatedTimestamp time.Time `json:"created-timestamp" example:"2023-03-10T09:58:40.011177Z"`
+}
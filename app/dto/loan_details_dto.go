@@ -6,24 +6,75 @@ import (
 )
 
 const (
-	LoanStatusPending  = "PENDING"
-	LoanStatusApproved = "APPROVED"
-	LOAN_STATUS_PAID   = "PAID"
+	LoanStatusPending   = "PENDING"
+	LoanStatusOffered   = "OFFERED"
+	LoanStatusApproved  = "APPROVED"
+	LoanStatusDeclined  = "DECLINED"
+	LoanStatusExpired   = "EXPIRED"
+	LoanStatusRejected  = "REJECTED"
+	LoanStatusCancelled = "CANCELLED"
+	LoanStatusPaid      = "PAID"
 )
 
+// Reasons of a loan rejection, notes are required for LoanRejectionReasonOther
 const (
-	RepaymentStatusPending = "PENDING"
-	RepaymentStatusPaid    = "PAID"
+	LoanRejectionReasonCreditHistory         = "CREDIT_HISTORY"
+	LoanRejectionReasonInsufficientIncome    = "INSUFFICIENT_INCOME"
+	LoanRejectionReasonIncompleteApplication = "INCOMPLETE_APPLICATION"
+	LoanRejectionReasonPolicy                = "POLICY"
+	LoanRejectionReasonOther                 = "OTHER"
+)
+
+// Decisions of the customer on a counter-offer, an accepted offer approves the loan
+const (
+	LoanOfferDecisionAccept  = "ACCEPT"
+	LoanOfferDecisionDecline = "DECLINE"
+)
+
+// Interest methods of a loan, the interest is charged per repayment on the annual interest rate
+const (
+	// InterestMethodFlat charges the interest on the original principal, the principal is repaid in equal parts
+	InterestMethodFlat = "FLAT"
+	// InterestMethodDecliningBalance charges the interest on the outstanding principal with equated installments
+	InterestMethodDecliningBalance = "DECLINING_BALANCE"
+	// InterestMethodInterestOnly charges only the interest, the principal is repaid with the last installment (balloon)
+	InterestMethodInterestOnly = "INTEREST_ONLY"
+)
+
+// Repayment frequencies of a loan, monthly repayments are due on the day of the start date (clamped to the end of month)
+const (
+	RepaymentFrequencyWeekly      = "WEEKLY"
+	RepaymentFrequencyBiWeekly    = "BI_WEEKLY"
+	RepaymentFrequencySemiMonthly = "SEMI_MONTHLY"
+	RepaymentFrequencyMonthly     = "MONTHLY"
+)
+
+// Statuses of a repayment, a partially paid repayment has a paid amount below its due amount
+const (
+	RepaymentStatusPending       = "PENDING"
+	RepaymentStatusPartiallyPaid = "PARTIALLY_PAID"
+	RepaymentStatusPaid          = "PAID"
 )
 
 type LoanDetails struct {
 	LoanId           string              `json:"id" example:"b9348325-d798-4f81-85fc-336220380d4f"`
 	CustomerId       string              `json:"customer-id" example:"user1"`
+	ProductId        string              `json:"product-id,omitempty" example:"5f1c2f0e-3b7a-4d55-9d3c-6f4f0a8b2c11"`
+	ProductVersion   int                 `json:"product-version,omitempty" example:"1"`
 	TotalAmount      decimal.Decimal     `json:"total-amount" example:"100000"`
+	Currency         string              `json:"currency" example:"USD"`
+	InterestRate     decimal.Decimal     `json:"interest-rate" example:"12.5"`
+	InterestMethod   string              `json:"interest-method" example:"DECLINING_BALANCE"`
+	OriginationFee   decimal.Decimal     `json:"origination-fee" example:"1500"`
+	Outstanding      decimal.Decimal     `json:"outstanding-amount" example:"100000"`
 	Status           string              `json:"status" example:"PENDING"`
 	Term             int                 `json:"term" example:"1"`
+	Frequency        string              `json:"repayment-frequency" example:"MONTHLY"`
+	RejectionReason  string              `json:"rejection-reason,omitempty" example:"INSUFFICIENT_INCOME"`
+	RejectionNotes   string              `json:"rejection-notes,omitempty" example:"monthly income below the installment"`
 	Repayments       []*RepaymentDetails `json:"repayments"`
 	StartDate        time.Time           `json:"start-date" example:"2023-03-10T09:58:40.009375Z"`
+	ApprovedAt       *time.Time          `json:"approved-at,omitempty" example:"2023-03-11T09:58:40.009375Z"`
 	CreatedTimestamp time.Time           `json:"created-timestamp" example:"2023-03-10T09:58:40.011177Z"`
 	UpdatedTimestamp time.Time           `json:"updated-timestamp" example:"2023-03-10T09:58:40.011177Z"`
 }
@@ -33,8 +84,37 @@ type RepaymentDetails struct {
 	Number           int             `json:"number" example:"1"`
 	LoanId           string          `json:"loan-id,omitempty"`
 	Amount           decimal.Decimal `json:"due-amount" example:"100000"`
+	Principal        decimal.Decimal `json:"principal" example:"99000"`
+	Interest         decimal.Decimal `json:"interest" example:"1000"`
+	Fee              decimal.Decimal `json:"fee" example:"0"`
+	PaidAmount       decimal.Decimal `json:"paid-amount" example:"0"`
+	PaidPrincipal    decimal.Decimal `json:"paid-principal" example:"0"`
+	PaidInterest     decimal.Decimal `json:"paid-interest" example:"0"`
+	PaidFee          decimal.Decimal `json:"paid-fee" example:"0"`
+	Outstanding      decimal.Decimal `json:"outstanding-amount" example:"100000"`
 	Status           string          `json:"status" example:"PENDING"`
 	DueDate          time.Time       `json:"due-date" example:"2023-03-17T10:36:48.430739Z"`
 	CreatedTimestamp time.Time       `json:"created-timestamp" example:"2023-03-10T10:36:48.431463Z"`
 	UpdatedTimestamp time.Time       `json:"updated-timestamp" example:"2023-03-10T10:36:48.431463Z"`
 }
+
+// UpdateOutstanding : sets the outstanding amounts of the repayments from their paid amounts, the outstanding amount
+// of the loan is the sum of its repayments
+func (l *LoanDetails) UpdateOutstanding() {
+	l.Outstanding = decimal.Zero
+	for _, repayment := range l.Repayments {
+		repayment.Outstanding = repayment.Amount.Sub(repayment.PaidAmount)
+		l.Outstanding = l.Outstanding.Add(repayment.Outstanding)
+	}
+}
+
+// LoanQuoteDetails : preview of a loan and its repayment schedule, the loan is created at the quoted terms
+// with the quote id until the quote expires
+type LoanQuoteDetails struct {
+	QuoteId        string          `json:"quote-id" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	ExpiresAt      time.Time       `json:"expires-at" example:"2023-03-10T10:13:40.011177Z"`
+	TotalInterest  decimal.Decimal `json:"total-interest" example:"6593.24"`
+	TotalRepayment decimal.Decimal `json:"total-repayment" example:"107593.24"`
+	Apr            decimal.Decimal `json:"apr" example:"15.03"`
+	*LoanDetails
+}
@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+const (
+	LoanProductStatusActive   = "ACTIVE"
+	LoanProductStatusInactive = "INACTIVE"
+)
+
+// LoanProductDetails : terms of loans offered to customers, loans keep a snapshot of the terms at origination
+type LoanProductDetails struct {
+	ProductId          string          `json:"id" example:"5f1c2f0e-3b7a-4d55-9d3c-6f4f0a8b2c11"`
+	Name               string          `json:"name" example:"Personal Loan 12M"`
+	Description        string          `json:"description" example:"unsecured personal loan"`
+	Status             string          `json:"status" example:"ACTIVE"`
+	Currency           string          `json:"currency" example:"USD"`
+	MinAmount          decimal.Decimal `json:"min-amount" example:"1000"`
+	MaxAmount          decimal.Decimal `json:"max-amount" example:"50000"`
+	Terms              []int64         `json:"terms" example:"6,12"`
+	InterestRate       decimal.Decimal `json:"interest-rate" example:"12.5"`
+	InterestMethod     string          `json:"interest-method" example:"DECLINING_BALANCE"`
+	Frequency          string          `json:"repayment-frequency" example:"MONTHLY"`
+	OriginationFeeRate decimal.Decimal `json:"origination-fee-rate" example:"1.5"`
+	MaxOpenLoans       int             `json:"max-open-loans" example:"1"`
+	MinCustomerDays    int             `json:"min-customer-days" example:"30"`
+	Version            int             `json:"version" example:"1"`
+	CreatedTimestamp   time.Time       `json:"created-timestamp" example:"2023-03-10T09:58:40.011177Z"`
+	UpdatedTimestamp   time.Time       `json:"updated-timestamp" example:"2023-03-10T09:58:40.011177Z"`
+}
+
+// OffersTerm : checks if the product offers the term
+func (p *LoanProductDetails) OffersTerm(term int) bool {
+	for _, offeredTerm := range p.Terms {
+		if offeredTerm == int64(term) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+// LoanStatusHistory : transition of the loan status, FromStatus is empty for the creation of the loan.
+// ActorType is the login type of the actor (customer, admin or api-key)
+type LoanStatusHistory struct {
+	LoanId           string    `json:"loan-id" example:"b9348325-d798-4f81-85fc-336220380d4f"`
+	FromStatus       string    `json:"from-status" example:"PENDING"`
+	ToStatus         string    `json:"to-status" example:"APPROVED"`
+	ActorId          string    `json:"actor-id" example:"admin"`
+	ActorType        string    `json:"actor-type" example:"admin"`
+	Reason           string    `json:"reason" example:"CREDIT_HISTORY"`
+	CreatedTimestamp time.Time `json:"created-timestamp" example:"2023-03-10T09:58:40.011177Z"`
+}
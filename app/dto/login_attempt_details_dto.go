@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+const (
+	LoginAttemptScopeUsername = "USERNAME"
+	LoginAttemptScopeIp       = "IP"
+)
+
+type LoginAttemptDetails struct {
+	Scope        string
+	Subject      string
+	FailedCount  int
+	LastFailedAt time.Time
+	LockedUntil  *time.Time
+}
@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+type MfaChallengeDetails struct {
+	ChallengeId      string
+	Username         string
+	ChallengeHash    string
+	ExpiresAt        time.Time
+	Attempts         int
+	Used             bool
+	CreatedTimestamp time.Time
+}
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// Channels of a payment received from the customer
+const (
+	PaymentChannelCard         = "CARD"
+	PaymentChannelBankTransfer = "BANK_TRANSFER"
+	PaymentChannelDirectDebit  = "DIRECT_DEBIT"
+)
+
+// Components of a repayment, a payment pays them in the order of the configured waterfall
+const (
+	PaymentComponentFee       = "FEE"
+	PaymentComponentInterest  = "INTEREST"
+	PaymentComponentPrincipal = "PRINCIPAL"
+)
+
+// PaymentDetails : payment received from the customer for a loan, allocated to one or more repayments of the loan
+type PaymentDetails struct {
+	PaymentId        string               `json:"id" example:"2c1e6f4b-8d0a-4f6e-9a3b-5d7c8e9f0a1b"`
+	LoanId           string               `json:"loan-id" example:"b9348325-d798-4f81-85fc-336220380d4f"`
+	CustomerId       string               `json:"customer-id" example:"user1"`
+	Amount           decimal.Decimal      `json:"amount" example:"1100"`
+	Currency         string               `json:"currency" example:"USD"`
+	Channel          string               `json:"channel" example:"CARD"`
+	Allocations      []*PaymentAllocation `json:"allocations"`
+	CreatedTimestamp time.Time            `json:"created-timestamp" example:"2023-03-10T09:58:40.011177Z"`
+}
+
+// PaymentAllocation : part of a payment paying a repayment, the amount is split into the paid components
+type PaymentAllocation struct {
+	RepaymentId string          `json:"repayment-id" example:"9b02d974-2b09-4e42-8006-5e94ee93659a"`
+	Amount      decimal.Decimal `json:"amount" example:"600"`
+	Principal   decimal.Decimal `json:"principal" example:"500"`
+	Interest    decimal.Decimal `json:"interest" example:"100"`
+	Fee         decimal.Decimal `json:"fee" example:"0"`
+}
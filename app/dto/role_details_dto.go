@@ -0,0 +1,16 @@
+package dto
+
+import "time"
+
+type RoleDetails struct {
+	Name             string    `json:"name" example:"credit-officer"`
+	Description      string    `json:"description" example:"staff approving loans"`
+	Permissions      []string  `json:"permissions" example:"loan:approve,loan:read:any"`
+	CreatedTimestamp time.Time `json:"created-timestamp" example:"2023-03-10T09:58:40.011177Z"`
+	UpdatedTimestamp time.Time `json:"updated-timestamp" example:"2023-03-10T09:58:40.011177Z"`
+}
+
+type PermissionDetails struct {
+	Name        string `json:"name" example:"loan:approve"`
+	Description string `json:"description" example:"approve loans"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+import "time"
+
+type SessionDetails struct {
+	SessionId        string
+	Username         string
+	Role             string
+	RefreshTokenHash string
+	ExpiresAt        time.Time
+	Revoked          bool
+	MfaVerified      bool
+	// ImpersonatedBy is the admin viewing the app as the user, empty for a login of the user
+	ImpersonatedBy   string
+	CreatedTimestamp time.Time
+	UpdatedTimestamp time.Time
+}
@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// UserDetails : ApprovalLimit is the highest loan amount the admin can approve, nil for no limit
+type UserDetails struct {
+	Username         string
+	SecretHash       string
+	Roles            []string
+	TotpSecret       string
+	TotpEnabled      bool
+	TotpLastStep     int64
+	ApprovalLimit    *decimal.Decimal
+	CreatedTimestamp time.Time
+	UpdatedTimestamp time.Time
+}
+
+// HasRole : checks if the user is allowed to act with the provided role
+func (user *UserDetails) HasRole(role string) bool {
+	for _, userRole := range user.Roles {
+		if userRole == role {
+			return true
+		}
+	}
+	return false
+}
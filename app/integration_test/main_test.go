@@ -7,14 +7,15 @@ import (
 	"github.com/google/uuid"
 	"github.com/s8sg/mini-loan-app/app/config"
 	"github.com/s8sg/mini-loan-app/app/dto"
-	"github.com/s8sg/mini-loan-app/app/service"
 	"github.com/s8sg/mini-loan-app/app/util"
 	"github.com/shopspring/decimal"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
 const (
@@ -29,6 +30,12 @@ var (
 	ValidUser1 = "user1-" + uuid.New().String()
 	ValidUser2 = "user2-" + uuid.New().String()
 	ValidAdmin = "admin-" + uuid.New().String()
+	ValidStaff = "staff-" + uuid.New().String()
+	ValidUser3 = "user3-" + uuid.New().String()
+	// ValidOfficer approves loans with the admin for the approval quorum
+	ValidOfficer = "officer-" + uuid.New().String()
+
+	ValidSecret = "secret"
 
 	CustomerToken1        = ""
 	CustomerToken2        = ""
@@ -37,9 +44,18 @@ var (
 	User2LoanId           = ""
 	User1LoanRepaymentIds = []string{}
 	User2LoanRepaymentIds = []string{}
+
+	// TotpSecrets and TotpSteps of the admins, the server rejects reuse of a TOTP step
+	TotpSecrets = map[string]string{}
+	TotpSteps   = map[string]int64{}
 )
 
 func Init() {
+	// admin is bootstrapped by the server
+	os.Setenv("AUTH_ADMIN_USERNAME", ValidAdmin)
+	os.Setenv("AUTH_ADMIN_SECRET", ValidSecret)
+	os.Setenv("AUTH_MFA_ENCRYPTION_KEY", uuid.New().String())
+
 	server, err := config.InitializeServer()
 	if err != nil {
 		log.Fatalf("Failed to initialize server, error: %v", err)
@@ -54,17 +70,143 @@ func Init() {
 	}()
 }
 
+// login logs in the user and returns the bearer token and refresh token
+func login(t *testing.T, url, username string) (string, string) {
+	t.Helper()
+
+	body := []byte(fmt.Sprintf(`{"username":"%s","secret":"%s"}`, username, ValidSecret))
+	status, body := callAPI(t, "POST", url, body, "")
+	if status != 200 {
+		t.Fatalf("expected status 200 but got %d %v", status, string(body))
+	}
+
+	tokenStruct := struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh-token"`
+	}{}
+	if err := json.Unmarshal(body, &tokenStruct); err != nil {
+		t.Fatal(err)
+	}
+	if tokenStruct.Token == "" || tokenStruct.RefreshToken == "" {
+		t.Fatal(`response body doesn't contain "token" or "refresh-token" field'`)
+	}
+	return tokenStruct.Token, tokenStruct.RefreshToken
+}
+
+// totpCode generates the next unused TOTP code of the admin, waits for the next time step if required
+func totpCode(t *testing.T, username string) string {
+	t.Helper()
+
+	step := util.GetTotpStep(time.Now())
+	if lastStep, ok := TotpSteps[username]; ok && step <= lastStep {
+		step = lastStep + 1
+	}
+	for step > util.GetTotpStep(time.Now()) {
+		time.Sleep(time.Second)
+	}
+	TotpSteps[username] = step
+
+	code, err := util.GenerateTotpCode(TotpSecrets[username], step)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// enrollMfa enrolls and activates TOTP for the admin of the token, returns the recovery codes
+func enrollMfa(t *testing.T, username, token string) []string {
+	t.Helper()
+
+	status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/mfa/enroll", nil, token)
+	if status != 200 {
+		t.Fatalf("expected status 200 but got %d %v", status, string(body))
+	}
+	enrollStruct := struct {
+		Secret     string `json:"secret"`
+		OtpauthUri string `json:"otpauth-uri"`
+	}{}
+	if err := json.Unmarshal(body, &enrollStruct); err != nil {
+		t.Fatal(err)
+	}
+	if enrollStruct.Secret == "" || enrollStruct.OtpauthUri == "" {
+		t.Fatalf(`response body doesn't contain "secret" or "otpauth-uri" field, %v`, string(body))
+	}
+	TotpSecrets[username] = enrollStruct.Secret
+
+	body = []byte(fmt.Sprintf(`{"code":"%s"}`, totpCode(t, username)))
+	status, body = callAPI(t, "POST", "http://localhost:8085/api/v1/admin/mfa/activate", body, token)
+	if status != 200 {
+		t.Fatalf("expected status 200 but got %d %v", status, string(body))
+	}
+	recoveryCodesStruct := struct {
+		RecoveryCodes []string `json:"recovery-codes"`
+	}{}
+	if err := json.Unmarshal(body, &recoveryCodesStruct); err != nil {
+		t.Fatal(err)
+	}
+	if len(recoveryCodesStruct.RecoveryCodes) == 0 {
+		t.Fatalf(`response body doesn't contain "recovery-codes" field, %v`, string(body))
+	}
+	return recoveryCodesStruct.RecoveryCodes
+}
+
+// loginWithMfa logs in the admin with the second factor, code is generated if not provided
+func loginWithMfa(t *testing.T, username, code string) (int, string) {
+	t.Helper()
+
+	body := []byte(fmt.Sprintf(`{"username":"%s","secret":"%s"}`, username, ValidSecret))
+	status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/auth/admin/login", body, "")
+	if status != 200 {
+		t.Fatalf("expected status 200 but got %d %v", status, string(body))
+	}
+	mfaStruct := struct {
+		MfaRequired bool   `json:"mfa-required"`
+		MfaToken    string `json:"mfa-token"`
+	}{}
+	if err := json.Unmarshal(body, &mfaStruct); err != nil {
+		t.Fatal(err)
+	}
+	if !mfaStruct.MfaRequired || mfaStruct.MfaToken == "" {
+		t.Fatalf(`response body doesn't contain "mfa-required" or "mfa-token" field, %v`, string(body))
+	}
+
+	if code == "" {
+		code = totpCode(t, username)
+	}
+	body = []byte(fmt.Sprintf(`{"mfa-token":"%s","code":"%s"}`, mfaStruct.MfaToken, code))
+	status, body = callAPI(t, "POST", "http://localhost:8085/api/v1/auth/admin/login/mfa", body, "")
+	tokenStruct := struct {
+		Token string `json:"token"`
+	}{}
+	if status == 200 {
+		if err := json.Unmarshal(body, &tokenStruct); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return status, tokenStruct.Token
+}
+
 func callAPI(t *testing.T, method, url string, body []byte, token string) (int, []byte) {
 	t.Helper()
 
+	authorization := ""
+	if token != "" {
+		authorization = "Bearer " + token
+	}
+	return callAPIWithAuthorization(t, method, url, body, authorization)
+}
+
+func callAPIWithAuthorization(t *testing.T, method, url string, body []byte, authorization string) (int, []byte) {
+	t.Helper()
+
 	req, err := http.NewRequest(method, url, bytes.NewReader(body))
 	if err != nil {
 		t.Fatal(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	if token != "" {
-		req.Header.Set("Authorization", "Bearer "+token)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
 	}
 
 	res, err := http.DefaultClient.Do(req)
@@ -87,6 +229,58 @@ func callAPI(t *testing.T, method, url string, body []byte, token string) (int,
 func TestMainAPI(t *testing.T) {
 	Init()
 
+	t.Run("Customer Signup", func(t *testing.T) {
+		// invalid request with empty body
+		t.Run("POST /api/v1/auth/customer/signup 400", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{}`))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/auth/customer/signup", body, "")
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+		})
+
+		// invalid request with invalid email
+		t.Run("POST /api/v1/auth/customer/signup 400", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"username":"%s","secret":"%s","name":"user1","email":"invalid"}`,
+				ValidUser1, ValidSecret))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/auth/customer/signup", body, "")
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+		})
+
+		// valid request for user1 and user2
+		t.Run("POST /api/v1/auth/customer/signup 201", func(t *testing.T) {
+			for _, user := range []string{ValidUser1, ValidUser2} {
+				body := []byte(fmt.Sprintf(`{"username":"%s","secret":"%s","name":"%s","email":"%s@example.com"}`,
+					user, ValidSecret, user, user))
+				status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/auth/customer/signup", body, "")
+				if status != 201 {
+					t.Errorf("expected status 201 but got %d %v", status, string(body))
+				}
+
+				customerDetails := &dto.CustomerDetails{}
+				if err := json.Unmarshal(body, &customerDetails); err != nil {
+					t.Fatal(err)
+				}
+
+				if customerDetails.CustomerId != user || customerDetails.Status != "ACTIVE" {
+					t.Errorf("customer created with wrong details, %v", string(body))
+				}
+			}
+		})
+
+		// duplicate request for user1
+		t.Run("POST /api/v1/auth/customer/signup 409", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"username":"%s","secret":"%s","name":"user1","email":"user1@example.com"}`,
+				ValidUser1, ValidSecret))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/auth/customer/signup", body, "")
+			if status != 409 {
+				t.Errorf("expected status 409 but got %d", status)
+			}
+		})
+	})
+
 	t.Run("Customer Login", func(t *testing.T) {
 		// invalid request with empty body
 		t.Run("POST /api/v1/auth/customer/login 400", func(t *testing.T) {
@@ -97,9 +291,27 @@ func TestMainAPI(t *testing.T) {
 			}
 		})
 
+		// invalid secret for user1
+		t.Run("POST /api/v1/auth/customer/login 401", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"username":"%s","secret":"invalid"}`, ValidUser1))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/auth/customer/login", body, "")
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+		})
+
+		// unknown user
+		t.Run("POST /api/v1/auth/customer/login 401", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"username":"%s","secret":"%s"}`, uuid.New().String(), ValidSecret))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/auth/customer/login", body, "")
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+		})
+
 		// valid request for user1
 		t.Run("POST /api/v1/auth/customer/login 200", func(t *testing.T) {
-			body := []byte(fmt.Sprintf(`{"username":"%s","secret":"secret"}`, ValidUser1))
+			body := []byte(fmt.Sprintf(`{"username":"%s","secret":"%s"}`, ValidUser1, ValidSecret))
 			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/auth/customer/login", body, "")
 			if status != 200 {
 				t.Errorf("expected status 200 but got %d %v", status, string(body))
@@ -120,7 +332,7 @@ func TestMainAPI(t *testing.T) {
 
 		// valid request for user2
 		t.Run("POST /api/v1/auth/customer/login 200", func(t *testing.T) {
-			body := []byte(fmt.Sprintf(`{"username":"%s","secret":"secret"}`, ValidUser2))
+			body := []byte(fmt.Sprintf(`{"username":"%s","secret":"%s"}`, ValidUser2, ValidSecret))
 			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/auth/customer/login", body, "")
 			if status != 200 {
 				t.Errorf("expected status 200 but got %d %v", status, string(body))
@@ -150,9 +362,18 @@ func TestMainAPI(t *testing.T) {
 			}
 		})
 
+		// customer can't login as admin
+		t.Run("POST /api/v1/auth/admin/login 401", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"username":"%s","secret":"%s"}`, ValidUser1, ValidSecret))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/auth/admin/login", body, "")
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+		})
+
 		// valid request for admin1
 		t.Run("POST /api/v1/auth/admin/login 200", func(t *testing.T) {
-			body := []byte(fmt.Sprintf(`{"username":"%s","secret":"secret"}`, ValidAdmin))
+			body := []byte(fmt.Sprintf(`{"username":"%s","secret":"%s"}`, ValidAdmin, ValidSecret))
 			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/auth/admin/login", body, "")
 			if status != 200 {
 				t.Errorf("expected status 200 but got %d %v", status, string(body))
@@ -170,6 +391,97 @@ func TestMainAPI(t *testing.T) {
 
 			AdminToken = tokenStruct.Token
 		})
+
+		// admin routes require mfa, except the enrollment
+		t.Run("GET /api/v1/admin/customers 403", func(t *testing.T) {
+			status, _ := callAPI(t, "GET", "http://localhost:8085/api/v1/admin/customers", nil, AdminToken)
+			if status != 403 {
+				t.Errorf("expected status 403 but got %d", status)
+			}
+		})
+
+		// enroll mfa and login with the second factor
+		t.Run("POST /api/v1/auth/admin/login/mfa 200", func(t *testing.T) {
+			recoveryCodes := enrollMfa(t, ValidAdmin, AdminToken)
+
+			status, _ := loginWithMfa(t, ValidAdmin, "000000")
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+
+			// recovery codes can only be used once
+			status, _ = loginWithMfa(t, ValidAdmin, recoveryCodes[0])
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+			status, _ = loginWithMfa(t, ValidAdmin, recoveryCodes[0])
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+
+			status, token := loginWithMfa(t, ValidAdmin, "")
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d", status)
+			}
+
+			AdminToken = token
+		})
+	})
+
+	t.Run("Customer Management", func(t *testing.T) {
+		// request with customer token set
+		t.Run("GET /api/v1/admin/customers 401", func(t *testing.T) {
+			status, _ := callAPI(t, "GET", "http://localhost:8085/api/v1/admin/customers", nil, CustomerToken1)
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+		})
+
+		// request with admin token set and unknown customer
+		t.Run("GET /api/v1/admin/customer/{id} 404", func(t *testing.T) {
+			status, _ := callAPI(t, "GET", "http://localhost:8085/api/v1/admin/customer/"+uuid.New().String(), nil, AdminToken)
+			if status != 404 {
+				t.Errorf("expected status 404 but got %d", status)
+			}
+		})
+
+		// customer reads its own profile
+		t.Run("GET /api/v1/user/profile 200", func(t *testing.T) {
+			status, body := callAPI(t, "GET", "http://localhost:8085/api/v1/user/profile", nil, CustomerToken1)
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+
+			customerDetails := &dto.CustomerDetails{}
+			if err := json.Unmarshal(body, &customerDetails); err != nil {
+				t.Fatal(err)
+			}
+
+			if customerDetails.CustomerId != ValidUser1 {
+				t.Errorf("wrong customer profile, %v", string(body))
+			}
+		})
+
+		// disabled customer can't create loan until enabled again
+		t.Run("POST /api/v1/admin/customer/disable 200", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"customer-id": "%s"}`, ValidUser2))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/customer/disable", body, AdminToken)
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+
+			body = []byte(fmt.Sprintf(`{"amount": %d, "term": %d}`, LoanAmount2, Term2))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", body, CustomerToken2)
+			if status != 403 {
+				t.Errorf("expected status 403 but got %d", status)
+			}
+
+			body = []byte(fmt.Sprintf(`{"customer-id": "%s"}`, ValidUser2))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/admin/customer/enable", body, AdminToken)
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+		})
 	})
 
 	t.Run("Loan Create", func(t *testing.T) {
@@ -218,6 +530,42 @@ func TestMainAPI(t *testing.T) {
 			}
 		})
 
+		// request with user token set, but negative interest rate
+		t.Run("POST /api/v1/user/loan 400", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"amount": %d, "term": 2, "interest-rate": -1}`, LoanAmount1))
+			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", body, CustomerToken1)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d, %v", status, string(body))
+			}
+		})
+
+		// request with user token set, but amount with fractions of a cent
+		t.Run("POST /api/v1/user/loan 400", func(t *testing.T) {
+			body := []byte(`{"amount": 100.001, "term": 2}`)
+			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", body, CustomerToken1)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d, %v", status, string(body))
+			}
+		})
+
+		// request with user token set, but invalid interest method
+		t.Run("POST /api/v1/user/loan 400", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"amount": %d, "term": 2, "interest-method": "COMPOUND"}`, LoanAmount1))
+			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", body, CustomerToken1)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d, %v", status, string(body))
+			}
+		})
+
+		// request with user token set, but invalid repayment frequency
+		t.Run("POST /api/v1/user/loan 400", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"amount": %d, "term": 2, "repayment-frequency": "DAILY"}`, LoanAmount1))
+			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", body, CustomerToken1)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d, %v", status, string(body))
+			}
+		})
+
 		// request with user token set, and valid amount 10000 for customer 1
 		t.Run("POST /api/v1/user/loan 200", func(t *testing.T) {
 			body := []byte(fmt.Sprintf(`{"amount": %d, "term": %d}`, LoanAmount1, Term1))
@@ -286,7 +634,7 @@ func TestMainAPI(t *testing.T) {
 				t.Errorf("rerpayment created with wrong no, %v", string(body))
 			}
 
-			if repayment1.DueDate.Compare(loanDetails.StartDate.Add(service.RepaymentFrequency)) != 0 {
+			if repayment1.DueDate.Compare(loanDetails.StartDate.AddDate(0, 0, 7)) != 0 {
 				t.Errorf("rerpayment created with wrong no, %v", string(body))
 			}
 
@@ -308,7 +656,7 @@ func TestMainAPI(t *testing.T) {
 				t.Errorf("rerpayment created with wrong no, %v", string(body))
 			}
 
-			if repayment2.DueDate.Compare(loanDetails.StartDate.Add(service.RepaymentFrequency*2)) != 0 {
+			if repayment2.DueDate.Compare(loanDetails.StartDate.AddDate(0, 0, 14)) != 0 {
 				t.Errorf("rerpayment created with wrong no, %v", string(body))
 			}
 		})
@@ -373,7 +721,16 @@ func TestMainAPI(t *testing.T) {
 				t.Errorf("rerpayment created with wrong status, %v", string(body))
 			}
 
-			if !repayment1.Amount.Equal(decimal.NewFromInt(LoanAmount2).Div(decimal.NewFromInt(Term2))) {
+			// installments are rounded to the minor unit, the last installment takes the remainder
+			totalRepayment := decimal.Zero
+			for _, repayment := range loanDetails.Repayments {
+				totalRepayment = totalRepayment.Add(repayment.Amount)
+			}
+			if !totalRepayment.Equal(decimal.NewFromInt(LoanAmount2)) {
+				t.Errorf("rerpayments don't sum up to the loan amount, %v", string(body))
+			}
+
+			if !repayment1.Amount.Equal(decimal.NewFromInt(LoanAmount2).Div(decimal.NewFromInt(Term2)).Round(2)) {
 				t.Errorf("rerpayment created with wrong status, %v", string(body))
 			}
 
@@ -381,7 +738,7 @@ func TestMainAPI(t *testing.T) {
 				t.Errorf("rerpayment created with wrong no, %v", string(body))
 			}
 
-			if repayment1.DueDate.Compare(loanDetails.StartDate.Add(service.RepaymentFrequency)) != 0 {
+			if repayment1.DueDate.Compare(loanDetails.StartDate.AddDate(0, 0, 7)) != 0 {
 				t.Errorf("rerpayment created with wrong no, %v", string(body))
 			}
 
@@ -395,7 +752,7 @@ func TestMainAPI(t *testing.T) {
 				t.Errorf("rerpayment created with wrong status, %v", string(body))
 			}
 
-			if !repayment2.Amount.Equal(decimal.NewFromInt(LoanAmount2).Div(decimal.NewFromInt(Term2))) {
+			if !repayment2.Amount.Equal(decimal.NewFromInt(LoanAmount2).Div(decimal.NewFromInt(Term2)).Round(2)) {
 				t.Errorf("rerpayment created with wrong status, %v", string(body))
 			}
 
@@ -403,7 +760,7 @@ func TestMainAPI(t *testing.T) {
 				t.Errorf("rerpayment created with wrong no, %v", string(body))
 			}
 
-			if repayment2.DueDate.Compare(loanDetails.StartDate.Add(service.RepaymentFrequency*2)) != 0 {
+			if repayment2.DueDate.Compare(loanDetails.StartDate.AddDate(0, 0, 14)) != 0 {
 				t.Errorf("rerpayment created with wrong no, %v", string(body))
 			}
 		})
@@ -446,6 +803,9 @@ func TestMainAPI(t *testing.T) {
 			}
 		})
 
+		// the repayment schedule is re-based to the approval date
+		approvedAfter := time.Now().UTC().Truncate(time.Second)
+
 		// request with admin token set and valid body
 		t.Run("POST /api/v1/admin/loan/approve 200", func(t *testing.T) {
 			body := []byte(fmt.Sprintf(`{"loan-id": "%s"}`, User1LoanId))
@@ -464,6 +824,34 @@ func TestMainAPI(t *testing.T) {
 				t.Errorf("expected 'successfully completed' but got %s", response.Message)
 			}
 		})
+
+		// request with customer1 token set (validate the schedule starts at the approval)
+		t.Run("GET /api/v1/user/loans 200", func(t *testing.T) {
+			status, body := callAPI(t, "GET", "http://localhost:8085/api/v1/user/loans", nil, CustomerToken1)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d", status)
+			}
+			response := struct {
+				Loans []*dto.LoanDetails `json:"loans"`
+			}{}
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatal(err)
+			}
+			if len(response.Loans) != 1 || len(response.Loans[0].Repayments) == 0 {
+				t.Fatalf("expected the approved loan with repayments, %v", string(body))
+			}
+
+			loanDetails := response.Loans[0]
+			if loanDetails.StartDate.Before(approvedAfter) {
+				t.Errorf("expected start date after %v but got %v", approvedAfter, loanDetails.StartDate)
+			}
+			for _, repayment := range loanDetails.Repayments {
+				if !repayment.DueDate.After(loanDetails.StartDate) {
+					t.Errorf("expected due date after the start date %v but got %v", loanDetails.StartDate,
+						repayment.DueDate)
+				}
+			}
+		})
 	})
 
 	t.Run("Repay Loan", func(t *testing.T) {
@@ -609,4 +997,1461 @@ func TestMainAPI(t *testing.T) {
 			}
 		})
 	})
+	t.Run("Sessions", func(t *testing.T) {
+		// refresh with invalid token
+		t.Run("POST /api/v1/auth/refresh 401", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"refresh-token": "%s.invalid"}`, uuid.New().String()))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/auth/refresh", body, "")
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+		})
+
+		// refresh rotates the token, reusing the old refresh token revokes the session
+		t.Run("POST /api/v1/auth/refresh 200", func(t *testing.T) {
+			_, refreshToken := login(t, "http://localhost:8085/api/v1/auth/customer/login", ValidUser1)
+
+			body := []byte(fmt.Sprintf(`{"refresh-token": "%s"}`, refreshToken))
+			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/auth/refresh", body, "")
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d %v", status, string(body))
+			}
+			tokenStruct := struct {
+				Token        string `json:"token"`
+				RefreshToken string `json:"refresh-token"`
+			}{}
+			if err := json.Unmarshal(body, &tokenStruct); err != nil {
+				t.Fatal(err)
+			}
+			if tokenStruct.RefreshToken == refreshToken {
+				t.Errorf("refresh token is not rotated")
+			}
+
+			status, _ = callAPI(t, "GET", "http://localhost:8085/api/v1/user/loans", nil, tokenStruct.Token)
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+
+			body = []byte(fmt.Sprintf(`{"refresh-token": "%s"}`, refreshToken))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/auth/refresh", body, "")
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+
+			status, _ = callAPI(t, "GET", "http://localhost:8085/api/v1/user/loans", nil, tokenStruct.Token)
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+		})
+
+		// logout revokes the bearer token of the session
+		t.Run("POST /api/v1/auth/logout 200", func(t *testing.T) {
+			token, refreshToken := login(t, "http://localhost:8085/api/v1/auth/customer/login", ValidUser2)
+
+			body := []byte(fmt.Sprintf(`{"refresh-token": "%s"}`, refreshToken))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/auth/logout", body, "")
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+
+			status, _ = callAPI(t, "GET", "http://localhost:8085/api/v1/user/loans", nil, token)
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+		})
+
+		// revoking a bearer token rejects the token, the session keeps issuing new tokens
+		t.Run("POST /api/v1/auth/revoke 200", func(t *testing.T) {
+			token, refreshToken := login(t, "http://localhost:8085/api/v1/auth/customer/login", ValidUser2)
+
+			body := []byte(fmt.Sprintf(`{"token": "%s"}`, token))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/auth/revoke", body, "")
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+
+			status, _ = callAPI(t, "GET", "http://localhost:8085/api/v1/user/loans", nil, token)
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+
+			body = []byte(fmt.Sprintf(`{"refresh-token": "%s"}`, refreshToken))
+			status, body = callAPI(t, "POST", "http://localhost:8085/api/v1/auth/refresh", body, "")
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d", status)
+			}
+			tokenStruct := struct {
+				Token string `json:"token"`
+			}{}
+			if err := json.Unmarshal(body, &tokenStruct); err != nil {
+				t.Fatal(err)
+			}
+			status, _ = callAPI(t, "GET", "http://localhost:8085/api/v1/user/loans", nil, tokenStruct.Token)
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+		})
+
+		t.Run("POST /api/v1/auth/revoke 401", func(t *testing.T) {
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/auth/revoke", []byte(`{"token": "invalid"}`), "")
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+		})
+	})
+	t.Run("Impersonation", func(t *testing.T) {
+		// request with customer token set
+		t.Run("POST /api/v1/admin/impersonate 401", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"username": "%s"}`, ValidUser2))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/impersonate", body, CustomerToken1)
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+		})
+
+		// request with unknown customer
+		t.Run("POST /api/v1/admin/impersonate 404", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"username": "%s"}`, uuid.New().String()))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/impersonate", body, AdminToken)
+			if status != 404 {
+				t.Errorf("expected status 404 but got %d", status)
+			}
+		})
+
+		// the impersonation token reads as the customer, mutating requests are rejected
+		t.Run("POST /api/v1/admin/impersonate 200", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"username": "%s"}`, ValidUser1))
+			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/impersonate", body, AdminToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d %v", status, string(body))
+			}
+			tokenStruct := struct {
+				Token string `json:"token"`
+			}{}
+			if err := json.Unmarshal(body, &tokenStruct); err != nil {
+				t.Fatal(err)
+			}
+
+			status, body = callAPI(t, "GET", "http://localhost:8085/api/v1/user/loans", nil, tokenStruct.Token)
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+			response := struct {
+				Loans []*dto.LoanDetails `json:"loans"`
+			}{}
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatal(err)
+			}
+			if len(response.Loans) != 1 || response.Loans[0].LoanId != User1LoanId {
+				t.Errorf("expected loans of the customer, %v", string(body))
+			}
+
+			body = []byte(fmt.Sprintf(`{"repayment-id": "%s", "amount": 1}`, uuid.New().String()))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan/repayment", body, tokenStruct.Token)
+			if status != 403 {
+				t.Errorf("expected status 403 but got %d", status)
+			}
+
+			body = []byte(`{"name": "impersonated"}`)
+			status, _ = callAPI(t, "PUT", "http://localhost:8085/api/v1/user/profile", body, tokenStruct.Token)
+			if status != 403 {
+				t.Errorf("expected status 403 but got %d", status)
+			}
+
+			status, _ = callAPI(t, "GET", "http://localhost:8085/api/v1/admin/customers", nil, tokenStruct.Token)
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+		})
+	})
+	t.Run("Roles", func(t *testing.T) {
+		// request with customer token set
+		t.Run("GET /api/v1/admin/roles 401", func(t *testing.T) {
+			status, _ := callAPI(t, "GET", "http://localhost:8085/api/v1/admin/roles", nil, CustomerToken1)
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+		})
+
+		t.Run("GET /api/v1/admin/roles 200", func(t *testing.T) {
+			status, body := callAPI(t, "GET", "http://localhost:8085/api/v1/admin/roles", nil, AdminToken)
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+
+			rolesStruct := struct {
+				Roles []*dto.RoleDetails `json:"roles"`
+			}{}
+			if err := json.Unmarshal(body, &rolesStruct); err != nil {
+				t.Fatal(err)
+			}
+			if len(rolesStruct.Roles) == 0 {
+				t.Errorf("response body doesn't contain roles, %v", string(body))
+			}
+		})
+
+		// staff user can't be created with the customer role
+		t.Run("POST /api/v1/admin/user 400", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"username": "%s", "secret": "%s", "roles": ["customer"]}`, ValidStaff, ValidSecret))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/user", body, AdminToken)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+		})
+
+		// support staff can read customers but can't approve loans
+		t.Run("POST /api/v1/admin/user 201", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"username": "%s", "secret": "%s", "roles": ["support"]}`, ValidStaff, ValidSecret))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/user", body, AdminToken)
+			if status != 201 {
+				t.Errorf("expected status 201 but got %d", status)
+			}
+
+			staffToken, _ := login(t, "http://localhost:8085/api/v1/auth/admin/login", ValidStaff)
+
+			status, _ = callAPI(t, "GET", "http://localhost:8085/api/v1/admin/customers", nil, staffToken)
+			if status != 403 {
+				t.Errorf("expected status 403 but got %d", status)
+			}
+
+			enrollMfa(t, ValidStaff, staffToken)
+			status, staffToken = loginWithMfa(t, ValidStaff, "")
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d", status)
+			}
+
+			status, _ = callAPI(t, "GET", "http://localhost:8085/api/v1/admin/customers", nil, staffToken)
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+
+			body = []byte(fmt.Sprintf(`{"loan-id": "%s"}`, User2LoanId))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/approve", body, staffToken)
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+		})
+	})
+	t.Run("API Keys", func(t *testing.T) {
+		// api key can't be granted permissions the admin doesn't hold
+		t.Run("POST /api/v1/admin/api-key 403", func(t *testing.T) {
+			body := []byte(`{"name": "batch", "permissions": ["loan:create:own"], "expires-in-days": 1}`)
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/api-key", body, AdminToken)
+			if status != 403 {
+				t.Errorf("expected status 403 but got %d", status)
+			}
+		})
+
+		// api key is scoped to its permissions and rejected once revoked
+		t.Run("POST /api/v1/admin/api-key 201", func(t *testing.T) {
+			body := []byte(`{"name": "batch", "permissions": ["customer:read"], "expires-in-days": 1}`)
+			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/api-key", body, AdminToken)
+			if status != 201 {
+				t.Fatalf("expected status 201 but got %d %v", status, string(body))
+			}
+
+			apiKeyStruct := struct {
+				Key    string             `json:"key"`
+				ApiKey *dto.ApiKeyDetails `json:"api-key"`
+			}{}
+			if err := json.Unmarshal(body, &apiKeyStruct); err != nil {
+				t.Fatal(err)
+			}
+			if apiKeyStruct.Key == "" || apiKeyStruct.ApiKey == nil {
+				t.Fatalf("response body doesn't contain \"key\" or \"api-key\" field, %v", string(body))
+			}
+
+			status, _ = callAPIWithAuthorization(t, "GET", "http://localhost:8085/api/v1/admin/customers", nil,
+				"ApiKey "+apiKeyStruct.Key)
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+
+			body = []byte(fmt.Sprintf(`{"loan-id": "%s"}`, User2LoanId))
+			status, _ = callAPIWithAuthorization(t, "POST", "http://localhost:8085/api/v1/admin/loan/approve", body,
+				"ApiKey "+apiKeyStruct.Key)
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+
+			status, _ = callAPIWithAuthorization(t, "GET", "http://localhost:8085/api/v1/admin/customers", nil,
+				"ApiKey "+apiKeyStruct.ApiKey.ApiKeyId+".invalid")
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+
+			status, _ = callAPI(t, "DELETE", "http://localhost:8085/api/v1/admin/api-key/"+apiKeyStruct.ApiKey.ApiKeyId,
+				nil, AdminToken)
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+
+			status, _ = callAPIWithAuthorization(t, "GET", "http://localhost:8085/api/v1/admin/customers", nil,
+				"ApiKey "+apiKeyStruct.Key)
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+		})
+	})
+	t.Run("Login Throttling", func(t *testing.T) {
+		// repeated failures put the username in backoff even for the valid secret, until unlocked by an admin
+		t.Run("POST /api/v1/auth/customer/login 429", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"username":"%s","secret":"%s","name":"user3","email":"user3@example.com"}`,
+				ValidUser3, ValidSecret))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/auth/customer/signup", body, "")
+			if status != 201 {
+				t.Fatalf("expected status 201 but got %d", status)
+			}
+
+			for i := 0; i < 4; i++ {
+				body = []byte(fmt.Sprintf(`{"username":"%s","secret":"invalid"}`, ValidUser3))
+				status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/auth/customer/login", body, "")
+				if status != 401 {
+					t.Errorf("expected status 401 but got %d", status)
+				}
+			}
+
+			body = []byte(fmt.Sprintf(`{"username":"%s","secret":"%s"}`, ValidUser3, ValidSecret))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/auth/customer/login", body, "")
+			if status != 429 {
+				t.Errorf("expected status 429 but got %d", status)
+			}
+
+			body = []byte(fmt.Sprintf(`{"username":"%s"}`, ValidUser3))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/admin/login/unlock", body, CustomerToken1)
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/admin/login/unlock", body, AdminToken)
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+
+			login(t, "http://localhost:8085/api/v1/auth/customer/login", ValidUser3)
+		})
+	})
+	t.Run("Loan Products", func(t *testing.T) {
+		productRequest := `{"name": "personal-%s", "currency": "USD", "min-amount": 1000, "max-amount": 50000,
+			"terms": [6, 12], "interest-rate": %d, "interest-method": "DECLINING_BALANCE", "repayment-frequency": "MONTHLY",
+			"origination-fee-rate": 1.5, "max-open-loans": 1, "status": "%s"}`
+		productName := uuid.New().String()
+		loanProduct := &dto.LoanProductDetails{}
+		customerToken, _ := login(t, "http://localhost:8085/api/v1/auth/customer/login", ValidUser3)
+
+		// request with customer token set
+		t.Run("POST /api/v1/admin/loan-product 401", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(productRequest, productName, 12, "ACTIVE"))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan-product", body, CustomerToken1)
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+		})
+
+		// request with min amount greater than max amount
+		t.Run("POST /api/v1/admin/loan-product 400", func(t *testing.T) {
+			body := []byte(`{"name": "invalid", "min-amount": 1000, "max-amount": 100, "terms": [6]}`)
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan-product", body, AdminToken)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+		})
+
+		t.Run("POST /api/v1/admin/loan-product 201", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(productRequest, productName, 12, "ACTIVE"))
+			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan-product", body, AdminToken)
+			if status != 201 {
+				t.Fatalf("expected status 201 but got %d %v", status, string(body))
+			}
+			if err := json.Unmarshal(body, loanProduct); err != nil {
+				t.Fatal(err)
+			}
+			if loanProduct.ProductId == "" || loanProduct.Version != 1 {
+				t.Errorf("unexpected loan product %v", string(body))
+			}
+
+			status, body = callAPI(t, "GET", "http://localhost:8085/api/v1/user/loan-products", nil, customerToken)
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+			if !strings.Contains(string(body), loanProduct.ProductId) {
+				t.Errorf("loan product is not offered to the customer, %v", string(body))
+			}
+		})
+
+		// requests outside of the terms of the product
+		for _, loanRequest := range []string{
+			`{"product-id": "%s", "amount": 100, "term": 12}`,
+			`{"product-id": "%s", "amount": 12000, "term": 3}`,
+			`{"product-id": "%s", "amount": 12000, "term": 12, "interest-rate": 1}`,
+		} {
+			t.Run("POST /api/v1/user/loan 400", func(t *testing.T) {
+				body := []byte(fmt.Sprintf(loanRequest, loanProduct.ProductId))
+				status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", body, customerToken)
+				if status != 400 {
+					t.Errorf("expected status 400 but got %d, %v", status, string(body))
+				}
+			})
+		}
+
+		// the loan takes the terms of the product, the origination fee is charged with the first repayment
+		t.Run("POST /api/v1/user/loan 201", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"product-id": "%s", "amount": 12000, "term": 12}`, loanProduct.ProductId))
+			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", body, customerToken)
+			if status != 201 {
+				t.Fatalf("expected status 201 but got %d, %v", status, string(body))
+			}
+
+			loanDetails := &dto.LoanDetails{}
+			if err := json.Unmarshal(body, loanDetails); err != nil {
+				t.Fatal(err)
+			}
+			if loanDetails.ProductId != loanProduct.ProductId || loanDetails.ProductVersion != 1 ||
+				!loanDetails.InterestRate.Equal(decimal.NewFromInt(12)) || loanDetails.Frequency != "MONTHLY" {
+				t.Errorf("loan doesn't have the terms of the product, %v", string(body))
+			}
+			if !loanDetails.OriginationFee.Equal(decimal.NewFromInt(180)) {
+				t.Errorf("expected origination fee 180 but got %v", loanDetails.OriginationFee)
+			}
+			firstRepayment := loanDetails.Repayments[0]
+			if !firstRepayment.Fee.Equal(decimal.NewFromInt(180)) ||
+				!firstRepayment.Amount.Equal(firstRepayment.Principal.Add(firstRepayment.Interest).Add(firstRepayment.Fee)) {
+				t.Errorf("origination fee is not charged with the first repayment, %v", firstRepayment)
+			}
+		})
+
+		// product allows a single open loan
+		t.Run("POST /api/v1/user/loan 403", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"product-id": "%s", "amount": 12000, "term": 12}`, loanProduct.ProductId))
+			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", body, customerToken)
+			if status != 403 {
+				t.Errorf("expected status 403 but got %d, %v", status, string(body))
+			}
+		})
+
+		// updating the product doesn't change the existing loans, inactive products are not offered
+		t.Run("PUT /api/v1/admin/loan-product/{id} 200", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(productRequest, productName, 15, "INACTIVE"))
+			status, body := callAPI(t, "PUT", "http://localhost:8085/api/v1/admin/loan-product/"+loanProduct.ProductId,
+				body, AdminToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d %v", status, string(body))
+			}
+			updatedProduct := &dto.LoanProductDetails{}
+			if err := json.Unmarshal(body, updatedProduct); err != nil {
+				t.Fatal(err)
+			}
+			if updatedProduct.Version != 2 {
+				t.Errorf("expected version 2 but got %d", updatedProduct.Version)
+			}
+
+			status, body = callAPI(t, "GET", "http://localhost:8085/api/v1/user/loans", nil, customerToken)
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+			response := struct {
+				Loans []*dto.LoanDetails `json:"loans"`
+			}{}
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatal(err)
+			}
+			if len(response.Loans) != 1 || response.Loans[0].ProductVersion != 1 ||
+				!response.Loans[0].InterestRate.Equal(decimal.NewFromInt(12)) {
+				t.Errorf("existing loan is changed by the product update, %v", string(body))
+			}
+
+			body = []byte(fmt.Sprintf(`{"product-id": "%s", "amount": 12000, "term": 12}`, loanProduct.ProductId))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", body, customerToken)
+			if status != 404 {
+				t.Errorf("expected status 404 but got %d", status)
+			}
+
+			status, body = callAPI(t, "GET", "http://localhost:8085/api/v1/user/loan-products", nil, customerToken)
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+			if strings.Contains(string(body), loanProduct.ProductId) {
+				t.Errorf("inactive loan product is offered to the customer, %v", string(body))
+			}
+		})
+	})
+	t.Run("Loan Quote", func(t *testing.T) {
+		loanQuote := &dto.LoanQuoteDetails{}
+		customerToken, _ := login(t, "http://localhost:8085/api/v1/auth/customer/login", ValidUser3)
+
+		// request with invalid interest method
+		t.Run("POST /api/v1/user/loan/quote 400", func(t *testing.T) {
+			body := []byte(`{"amount": 1200, "term": 12, "interest-method": "COMPOUND"}`)
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan/quote", body, customerToken)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+		})
+
+		// quote responds with the schedule without creating the loan
+		t.Run("POST /api/v1/user/loan/quote 200", func(t *testing.T) {
+			body := []byte(`{"amount": 1200, "term": 12, "interest-rate": 12, "interest-method": "DECLINING_BALANCE",
+				"repayment-frequency": "MONTHLY"}`)
+			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan/quote", body, customerToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d %v", status, string(body))
+			}
+			if err := json.Unmarshal(body, loanQuote); err != nil {
+				t.Fatal(err)
+			}
+			if loanQuote.QuoteId == "" || loanQuote.LoanDetails == nil || len(loanQuote.Repayments) != 12 {
+				t.Fatalf("unexpected quote %v", string(body))
+			}
+			if !loanQuote.Apr.Equal(decimal.NewFromInt(12)) {
+				t.Errorf("expected apr 12 but got %v", loanQuote.Apr)
+			}
+			if !loanQuote.TotalRepayment.Equal(loanQuote.TotalAmount.Add(loanQuote.TotalInterest)) {
+				t.Errorf("total repayment %v isn't the amount with interest %v", loanQuote.TotalRepayment,
+					loanQuote.TotalInterest)
+			}
+
+			status, body = callAPI(t, "GET", "http://localhost:8085/api/v1/user/loans", nil, customerToken)
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+			response := struct {
+				Loans []*dto.LoanDetails `json:"loans"`
+			}{}
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatal(err)
+			}
+			if len(response.Loans) != 1 {
+				t.Errorf("quote created a loan, %v", string(body))
+			}
+		})
+
+		// quote can't be changed or used by another customer
+		for _, quoteRequest := range []struct {
+			body  string
+			token string
+		}{
+			{fmt.Sprintf(`{"quote-id": "%s", "amount": 2400}`, loanQuote.QuoteId), customerToken},
+			{fmt.Sprintf(`{"quote-id": "%s"}`, loanQuote.QuoteId+"invalid"), customerToken},
+			{fmt.Sprintf(`{"quote-id": "%s"}`, loanQuote.QuoteId), CustomerToken1},
+		} {
+			t.Run("POST /api/v1/user/loan 400", func(t *testing.T) {
+				status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", []byte(quoteRequest.body),
+					quoteRequest.token)
+				if status != 400 {
+					t.Errorf("expected status 400 but got %d, %v", status, string(body))
+				}
+			})
+		}
+
+		// loan is created at the terms of the quote
+		t.Run("POST /api/v1/user/loan 201", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"quote-id": "%s"}`, loanQuote.QuoteId))
+			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", body, customerToken)
+			if status != 201 {
+				t.Fatalf("expected status 201 but got %d, %v", status, string(body))
+			}
+
+			loanDetails := &dto.LoanDetails{}
+			if err := json.Unmarshal(body, loanDetails); err != nil {
+				t.Fatal(err)
+			}
+			if !loanDetails.TotalAmount.Equal(loanQuote.TotalAmount) || loanDetails.Term != loanQuote.Term ||
+				!loanDetails.InterestRate.Equal(loanQuote.InterestRate) || loanDetails.Frequency != loanQuote.Frequency {
+				t.Errorf("loan isn't created at the terms of the quote, %v", string(body))
+			}
+			for i, repayment := range loanDetails.Repayments {
+				if !repayment.Amount.Equal(loanQuote.Repayments[i].Amount) {
+					t.Errorf("expected repayment %d to be %v but got %v", i+1, loanQuote.Repayments[i].Amount,
+						repayment.Amount)
+				}
+			}
+		})
+
+		// quote creates one loan
+		t.Run("POST /api/v1/user/loan 409", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"quote-id": "%s"}`, loanQuote.QuoteId))
+			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", body, customerToken)
+			if status != 409 {
+				t.Errorf("expected status 409 but got %d, %v", status, string(body))
+			}
+		})
+	})
+	t.Run("Reject Loan", func(t *testing.T) {
+		customerToken, _ := login(t, "http://localhost:8085/api/v1/auth/customer/login", ValidUser3)
+		loanDetails := &dto.LoanDetails{}
+
+		t.Run("POST /api/v1/user/loan 201", func(t *testing.T) {
+			body := []byte(`{"amount": 5000, "term": 5}`)
+			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", body, customerToken)
+			if status != 201 {
+				t.Fatalf("expected status 201 but got %d, %v", status, string(body))
+			}
+			if err := json.Unmarshal(body, loanDetails); err != nil {
+				t.Fatal(err)
+			}
+		})
+
+		// request with customer token set
+		t.Run("POST /api/v1/admin/loan/reject 401", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"loan-id": "%s", "reason": "POLICY"}`, loanDetails.LoanId))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/reject", body, customerToken)
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+		})
+
+		// requests with unknown reason and without notes for reason OTHER
+		for _, rejectRequest := range []string{
+			`{"loan-id": "%s", "reason": "UNKNOWN"}`,
+			`{"loan-id": "%s", "reason": "OTHER"}`,
+		} {
+			t.Run("POST /api/v1/admin/loan/reject 400", func(t *testing.T) {
+				body := []byte(fmt.Sprintf(rejectRequest, loanDetails.LoanId))
+				status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/reject", body, AdminToken)
+				if status != 400 {
+					t.Errorf("expected status 400 but got %d", status)
+				}
+			})
+		}
+
+		// request with unknown loan
+		t.Run("POST /api/v1/admin/loan/reject 404", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"loan-id": "%s", "reason": "POLICY"}`, uuid.New().String()))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/reject", body, AdminToken)
+			if status != 404 {
+				t.Errorf("expected status 404 but got %d", status)
+			}
+		})
+
+		// rejected loan can't be approved, the customer sees the reason
+		t.Run("POST /api/v1/admin/loan/reject 200", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"loan-id": "%s", "reason": "INSUFFICIENT_INCOME", "notes": "income below the installment"}`,
+				loanDetails.LoanId))
+			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/reject", body, AdminToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d, %v", status, string(body))
+			}
+
+			body = []byte(fmt.Sprintf(`{"loan-id": "%s", "reason": "POLICY"}`, loanDetails.LoanId))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/reject", body, AdminToken)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+
+			body = []byte(fmt.Sprintf(`{"loan-id": "%s"}`, loanDetails.LoanId))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/approve", body, AdminToken)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+
+			status, body = callAPI(t, "GET", "http://localhost:8085/api/v1/user/loans", nil, customerToken)
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+			response := struct {
+				Loans []*dto.LoanDetails `json:"loans"`
+			}{}
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatal(err)
+			}
+			for _, loan := range response.Loans {
+				if loan.LoanId == loanDetails.LoanId && (loan.Status != dto.LoanStatusRejected ||
+					loan.RejectionReason != dto.LoanRejectionReasonInsufficientIncome ||
+					loan.RejectionNotes != "income below the installment") {
+					t.Errorf("rejection is not shown to the customer, %v", string(body))
+				}
+			}
+		})
+	})
+	t.Run("Cancel Loan", func(t *testing.T) {
+		customerToken, _ := login(t, "http://localhost:8085/api/v1/auth/customer/login", ValidUser3)
+
+		createLoan := func(t *testing.T, approve bool) *dto.LoanDetails {
+			body := []byte(`{"amount": 3000, "term": 3}`)
+			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", body, customerToken)
+			if status != 201 {
+				t.Fatalf("expected status 201 but got %d, %v", status, string(body))
+			}
+			loanDetails := &dto.LoanDetails{}
+			if err := json.Unmarshal(body, loanDetails); err != nil {
+				t.Fatal(err)
+			}
+			if approve {
+				body = []byte(fmt.Sprintf(`{"loan-id": "%s"}`, loanDetails.LoanId))
+				status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/approve", body, AdminToken)
+				if status != 200 {
+					t.Fatalf("expected status 200 but got %d", status)
+				}
+			}
+			return loanDetails
+		}
+
+		// pending loan can be cancelled by the customer only
+		t.Run("POST /api/v1/user/loan/cancel 200", func(t *testing.T) {
+			loanDetails := createLoan(t, false)
+
+			body := []byte(fmt.Sprintf(`{"loan-id": "%s"}`, loanDetails.LoanId))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan/cancel", body, CustomerToken1)
+			if status != 404 {
+				t.Errorf("expected status 404 but got %d", status)
+			}
+
+			status, body = callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan/cancel", body, customerToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d, %v", status, string(body))
+			}
+
+			body = []byte(fmt.Sprintf(`{"loan-id": "%s"}`, loanDetails.LoanId))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan/cancel", body, customerToken)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/approve", body, AdminToken)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+		})
+
+		// approved loan can be cancelled within the cooling-off period, payments are refused afterwards
+		t.Run("POST /api/v1/user/loan/cancel 200", func(t *testing.T) {
+			loanDetails := createLoan(t, true)
+
+			body := []byte(fmt.Sprintf(`{"loan-id": "%s"}`, loanDetails.LoanId))
+			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan/cancel", body, customerToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d, %v", status, string(body))
+			}
+
+			repayment := loanDetails.Repayments[0]
+			body = []byte(fmt.Sprintf(`{"repayment-id": "%s", "amount": %s}`, repayment.RepaymentId, repayment.Amount))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan/repayment", body, customerToken)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+
+			status, body = callAPI(t, "GET", "http://localhost:8085/api/v1/user/loans", nil, customerToken)
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+			response := struct {
+				Loans []*dto.LoanDetails `json:"loans"`
+			}{}
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatal(err)
+			}
+			for _, loan := range response.Loans {
+				if loan.LoanId == loanDetails.LoanId && (loan.Status != dto.LoanStatusCancelled || loan.ApprovedAt == nil) {
+					t.Errorf("expected approved loan to be cancelled, %v", string(body))
+				}
+			}
+		})
+
+		// approved loan can't be cancelled once repaid
+		t.Run("POST /api/v1/user/loan/cancel 400", func(t *testing.T) {
+			loanDetails := createLoan(t, true)
+
+			repayment := loanDetails.Repayments[0]
+			body := []byte(fmt.Sprintf(`{"repayment-id": "%s", "amount": %s}`, repayment.RepaymentId, repayment.Amount))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan/repayment", body, customerToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d", status)
+			}
+
+			body = []byte(fmt.Sprintf(`{"loan-id": "%s"}`, loanDetails.LoanId))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan/cancel", body, customerToken)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+		})
+	})
+	t.Run("Counter-Offer", func(t *testing.T) {
+		customerToken, _ := login(t, "http://localhost:8085/api/v1/auth/customer/login", ValidUser3)
+
+		createLoan := func(t *testing.T) *dto.LoanDetails {
+			body := []byte(`{"amount": 3000, "term": 3}`)
+			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", body, customerToken)
+			if status != 201 {
+				t.Fatalf("expected status 201 but got %d, %v", status, string(body))
+			}
+			loanDetails := &dto.LoanDetails{}
+			if err := json.Unmarshal(body, loanDetails); err != nil {
+				t.Fatal(err)
+			}
+			return loanDetails
+		}
+
+		getLoan := func(t *testing.T, loanId string) *dto.LoanDetails {
+			status, body := callAPI(t, "GET", "http://localhost:8085/api/v1/user/loans", nil, customerToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d", status)
+			}
+			response := struct {
+				Loans []*dto.LoanDetails `json:"loans"`
+			}{}
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatal(err)
+			}
+			for _, loan := range response.Loans {
+				if loan.LoanId == loanId {
+					return loan
+				}
+			}
+			t.Fatalf("loan %s not found", loanId)
+			return nil
+		}
+
+		t.Run("POST /api/v1/admin/loan/approve 400", func(t *testing.T) {
+			loanDetails := createLoan(t)
+			body := []byte(fmt.Sprintf(`{"loan-id": "%s", "amount": -1000}`, loanDetails.LoanId))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/approve", body, AdminToken)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+		})
+
+		// counter-offer with a smaller amount and a longer term, accepted by the customer
+		t.Run("POST /api/v1/user/loan/offer 200", func(t *testing.T) {
+			loanDetails := createLoan(t)
+
+			body := []byte(fmt.Sprintf(`{"loan-id": "%s", "amount": 2000, "term": 4}`, loanDetails.LoanId))
+			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/approve", body, AdminToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d, %v", status, string(body))
+			}
+
+			offeredLoan := getLoan(t, loanDetails.LoanId)
+			if offeredLoan.Status != dto.LoanStatusOffered || !offeredLoan.TotalAmount.Equal(decimal.NewFromInt(2000)) ||
+				offeredLoan.Term != 4 || len(offeredLoan.Repayments) != 4 {
+				t.Fatalf("expected the loan to be offered at the adjusted terms but got %v", offeredLoan)
+			}
+
+			// the repayments of the requested terms are replaced
+			repayment := loanDetails.Repayments[0]
+			body = []byte(fmt.Sprintf(`{"repayment-id": "%s", "amount": %s}`, repayment.RepaymentId, repayment.Amount))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan/repayment", body, customerToken)
+			if status != 404 {
+				t.Errorf("expected status 404 but got %d", status)
+			}
+
+			body = []byte(fmt.Sprintf(`{"loan-id": "%s"}`, loanDetails.LoanId))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/approve", body, AdminToken)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+
+			body = []byte(fmt.Sprintf(`{"loan-id": "%s", "decision": "MAYBE"}`, loanDetails.LoanId))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan/offer", body, customerToken)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+
+			body = []byte(fmt.Sprintf(`{"loan-id": "%s", "decision": "ACCEPT"}`, loanDetails.LoanId))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan/offer", body, CustomerToken1)
+			if status != 404 {
+				t.Errorf("expected status 404 but got %d", status)
+			}
+
+			status, body = callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan/offer", body, customerToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d, %v", status, string(body))
+			}
+
+			approvedLoan := getLoan(t, loanDetails.LoanId)
+			if approvedLoan.Status != dto.LoanStatusApproved || approvedLoan.ApprovedAt == nil {
+				t.Errorf("expected the loan to be approved but got %v", approvedLoan)
+			}
+		})
+
+		// counter-offer with a higher interest rate, declined by the customer
+		t.Run("POST /api/v1/user/loan/offer 200", func(t *testing.T) {
+			loanDetails := createLoan(t)
+
+			body := []byte(fmt.Sprintf(`{"loan-id": "%s", "interest-rate": 10}`, loanDetails.LoanId))
+			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/approve", body, AdminToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d, %v", status, string(body))
+			}
+
+			body = []byte(fmt.Sprintf(`{"loan-id": "%s", "decision": "DECLINE"}`, loanDetails.LoanId))
+			status, body = callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan/offer", body, customerToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d, %v", status, string(body))
+			}
+
+			body = []byte(fmt.Sprintf(`{"loan-id": "%s", "decision": "ACCEPT"}`, loanDetails.LoanId))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan/offer", body, customerToken)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+
+			if declinedLoan := getLoan(t, loanDetails.LoanId); declinedLoan.Status != dto.LoanStatusDeclined {
+				t.Errorf("expected the loan to be declined but got %v", declinedLoan.Status)
+			}
+		})
+	})
+
+	t.Run("Approval Quorum", func(t *testing.T) {
+		customerToken, _ := login(t, "http://localhost:8085/api/v1/auth/customer/login", ValidUser3)
+
+		body := []byte(fmt.Sprintf(`{"username": "%s", "secret": "%s", "roles": ["credit-officer"]}`, ValidOfficer, ValidSecret))
+		status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/user", body, AdminToken)
+		if status != 201 {
+			t.Fatalf("expected status 201 but got %d", status)
+		}
+		officerToken, _ := login(t, "http://localhost:8085/api/v1/auth/admin/login", ValidOfficer)
+		enrollMfa(t, ValidOfficer, officerToken)
+		status, officerToken = loginWithMfa(t, ValidOfficer, "")
+		if status != 200 {
+			t.Fatalf("expected status 200 but got %d", status)
+		}
+
+		// loan above the default approval threshold
+		body = []byte(`{"amount": 2000000, "term": 3}`)
+		status, body = callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", body, customerToken)
+		if status != 201 {
+			t.Fatalf("expected status 201 but got %d, %v", status, string(body))
+		}
+		loanDetails := &dto.LoanDetails{}
+		if err := json.Unmarshal(body, loanDetails); err != nil {
+			t.Fatal(err)
+		}
+
+		getStatusHistory := func(t *testing.T) []*dto.LoanStatusHistory {
+			status, body := callAPI(t, "GET", "http://localhost:8085/api/v1/admin/loan/"+loanDetails.LoanId+"/history", nil, AdminToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d", status)
+			}
+			response := struct {
+				History []*dto.LoanStatusHistory `json:"history"`
+			}{}
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatal(err)
+			}
+			return response.History
+		}
+
+		t.Run("POST /api/v1/admin/loan/approve 200", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"loan-id": "%s"}`, loanDetails.LoanId))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/approve", body, AdminToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d", status)
+			}
+
+			if history := getStatusHistory(t); len(history) != 1 {
+				t.Errorf("expected the loan to be pending until the quorum is reached, %v", history)
+			}
+		})
+
+		t.Run("POST /api/v1/admin/loan/approve 409", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"loan-id": "%s"}`, loanDetails.LoanId))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/approve", body, AdminToken)
+			if status != 409 {
+				t.Errorf("expected status 409 but got %d", status)
+			}
+		})
+
+		t.Run("POST /api/v1/admin/loan/approve 400", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"loan-id": "%s", "amount": 1500000}`, loanDetails.LoanId))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/approve", body, officerToken)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+		})
+
+		t.Run("POST /api/v1/admin/loan/approve 200", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"loan-id": "%s"}`, loanDetails.LoanId))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/approve", body, officerToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d", status)
+			}
+
+			history := getStatusHistory(t)
+			if len(history) != 2 || history[1].ToStatus != dto.LoanStatusApproved ||
+				!strings.Contains(history[1].Reason, ValidAdmin) || !strings.Contains(history[1].Reason, ValidOfficer) {
+				t.Errorf("expected the loan to be approved by the quorum, %v", history)
+			}
+		})
+
+		t.Run("GET /api/v1/admin/loan/:id/approvals 200", func(t *testing.T) {
+			status, body := callAPI(t, "GET", "http://localhost:8085/api/v1/admin/loan/"+loanDetails.LoanId+"/approvals", nil, AdminToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d", status)
+			}
+			response := struct {
+				Approvals []*dto.LoanApprovalDetails `json:"approvals"`
+			}{}
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatal(err)
+			}
+			if len(response.Approvals) != 2 || response.Approvals[0].ApproverId != ValidAdmin ||
+				response.Approvals[1].ApproverId != ValidOfficer {
+				t.Errorf("expected the approvals of the admin and the officer, %v", string(body))
+			}
+		})
+		// approvals of the quorum racing each other approve the loan, the approval losing the race is retried
+		t.Run("Concurrent Approvals", func(t *testing.T) {
+			body := []byte(`{"amount": 2000000, "term": 3}`)
+			status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", body, customerToken)
+			if status != 201 {
+				t.Fatalf("expected status 201 but got %d, %v", status, string(body))
+			}
+			*loanDetails = dto.LoanDetails{}
+			if err := json.Unmarshal(body, loanDetails); err != nil {
+				t.Fatal(err)
+			}
+
+			t.Run("POST /api/v1/admin/loan/approve", func(t *testing.T) {
+				for name, token := range map[string]string{ValidAdmin: AdminToken, ValidOfficer: officerToken} {
+					token := token
+					t.Run(name, func(t *testing.T) {
+						t.Parallel()
+						request := []byte(fmt.Sprintf(`{"loan-id": "%s"}`, loanDetails.LoanId))
+						status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/approve", request, token)
+						for retry := 0; status == 409 && retry < 3; retry++ {
+							status, body = callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/approve", request, token)
+						}
+						if status != 200 {
+							t.Errorf("expected status 200 but got %d, %v", status, string(body))
+						}
+					})
+				}
+			})
+
+			history := getStatusHistory(t)
+			if len(history) != 2 || history[1].ToStatus != dto.LoanStatusApproved {
+				t.Errorf("expected the loan to be approved by the quorum, %v", history)
+			}
+		})
+	})
+
+	t.Run("Approval Limit", func(t *testing.T) {
+		customerToken, _ := login(t, "http://localhost:8085/api/v1/auth/customer/login", ValidUser3)
+		status, officerToken := loginWithMfa(t, ValidOfficer, "")
+		if status != 200 {
+			t.Fatalf("expected status 200 but got %d", status)
+		}
+
+		t.Run("POST /api/v1/admin/user/approval-limit 400", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"username": "%s", "approval-limit": -1}`, ValidOfficer))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/user/approval-limit", body, AdminToken)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+
+			// customers don't approve loans
+			body = []byte(fmt.Sprintf(`{"username": "%s", "approval-limit": 50000}`, ValidUser3))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/admin/user/approval-limit", body, AdminToken)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+		})
+
+		t.Run("POST /api/v1/admin/user/approval-limit 404", func(t *testing.T) {
+			body := []byte(`{"username": "unknown-officer", "approval-limit": 50000}`)
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/user/approval-limit", body, AdminToken)
+			if status != 404 {
+				t.Errorf("expected status 404 but got %d", status)
+			}
+		})
+
+		t.Run("POST /api/v1/admin/user/approval-limit 200", func(t *testing.T) {
+			body := []byte(fmt.Sprintf(`{"username": "%s", "approval-limit": 50000}`, ValidOfficer))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/admin/user/approval-limit", body, AdminToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d", status)
+			}
+
+			body = []byte(`{"amount": 60000, "term": 3}`)
+			status, body = callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", body, customerToken)
+			if status != 201 {
+				t.Fatalf("expected status 201 but got %d, %v", status, string(body))
+			}
+			loanDetails := &dto.LoanDetails{}
+			if err := json.Unmarshal(body, loanDetails); err != nil {
+				t.Fatal(err)
+			}
+
+			body = []byte(fmt.Sprintf(`{"loan-id": "%s"}`, loanDetails.LoanId))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/approve", body, officerToken)
+			if status != 403 {
+				t.Errorf("expected status 403 but got %d", status)
+			}
+
+			// the officer can counter-offer within the limit
+			body = []byte(fmt.Sprintf(`{"loan-id": "%s", "amount": 50000}`, loanDetails.LoanId))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/approve", body, officerToken)
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+
+			// limit is removed
+			body = []byte(fmt.Sprintf(`{"username": "%s", "approval-limit": null}`, ValidOfficer))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/admin/user/approval-limit", body, AdminToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d", status)
+			}
+
+			body = []byte(`{"amount": 60000, "term": 3}`)
+			status, body = callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", body, customerToken)
+			if status != 201 {
+				t.Fatalf("expected status 201 but got %d, %v", status, string(body))
+			}
+			if err := json.Unmarshal(body, loanDetails); err != nil {
+				t.Fatal(err)
+			}
+
+			body = []byte(fmt.Sprintf(`{"loan-id": "%s"}`, loanDetails.LoanId))
+			status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/approve", body, officerToken)
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+		})
+	})
+
+	t.Run("Partial Repayment", func(t *testing.T) {
+		customerToken, _ := login(t, "http://localhost:8085/api/v1/auth/customer/login", ValidUser3)
+
+		body := []byte(`{"amount": 3000, "term": 3}`)
+		status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", body, customerToken)
+		if status != 201 {
+			t.Fatalf("expected status 201 but got %d, %v", status, string(body))
+		}
+		loanDetails := &dto.LoanDetails{}
+		if err := json.Unmarshal(body, loanDetails); err != nil {
+			t.Fatal(err)
+		}
+		if !loanDetails.Outstanding.Equal(decimal.NewFromInt(3000)) {
+			t.Errorf("expected outstanding amount 3000 but got %v", loanDetails.Outstanding)
+		}
+
+		body = []byte(fmt.Sprintf(`{"loan-id": "%s"}`, loanDetails.LoanId))
+		status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/approve", body, AdminToken)
+		if status != 200 {
+			t.Fatalf("expected status 200 but got %d", status)
+		}
+
+		repay := func(t *testing.T, repaymentId string, amount string) int {
+			body := []byte(fmt.Sprintf(`{"repayment-id": "%s", "amount": %s}`, repaymentId, amount))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan/repayment", body, customerToken)
+			return status
+		}
+
+		getLoan := func(t *testing.T) *dto.LoanDetails {
+			status, body := callAPI(t, "GET", "http://localhost:8085/api/v1/user/loans", nil, customerToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d", status)
+			}
+			response := struct {
+				Loans []*dto.LoanDetails `json:"loans"`
+			}{}
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatal(err)
+			}
+			for _, loan := range response.Loans {
+				if loan.LoanId == loanDetails.LoanId {
+					return loan
+				}
+			}
+			t.Fatalf("loan %s not found", loanDetails.LoanId)
+			return nil
+		}
+
+		// amount below the due amount
+		t.Run("POST /api/v1/user/loan/repayment 200", func(t *testing.T) {
+			if status := repay(t, loanDetails.Repayments[0].RepaymentId, "400"); status != 200 {
+				t.Fatalf("expected status 200 but got %d", status)
+			}
+
+			loan := getLoan(t)
+			repayment := loan.Repayments[0]
+			if repayment.Status != dto.RepaymentStatusPartiallyPaid || !repayment.PaidAmount.Equal(decimal.NewFromInt(400)) ||
+				!repayment.Outstanding.Equal(decimal.NewFromInt(600)) {
+				t.Errorf("expected the repayment to be partially paid but got %v", repayment)
+			}
+			if !loan.Outstanding.Equal(decimal.NewFromInt(2600)) {
+				t.Errorf("expected outstanding amount 2600 but got %v", loan.Outstanding)
+			}
+		})
+
+		// the excess is carried forward to the next repayment
+		t.Run("POST /api/v1/user/loan/repayment 200", func(t *testing.T) {
+			if status := repay(t, loanDetails.Repayments[0].RepaymentId, "1100"); status != 200 {
+				t.Fatalf("expected status 200 but got %d", status)
+			}
+
+			loan := getLoan(t)
+			if loan.Repayments[0].Status != dto.RepaymentStatusPaid ||
+				loan.Repayments[1].Status != dto.RepaymentStatusPartiallyPaid ||
+				!loan.Repayments[1].PaidAmount.Equal(decimal.NewFromInt(500)) {
+				t.Errorf("expected the excess to be carried forward but got %v", loan.Repayments)
+			}
+			if !loan.Outstanding.Equal(decimal.NewFromInt(1500)) {
+				t.Errorf("expected outstanding amount 1500 but got %v", loan.Outstanding)
+			}
+		})
+
+		// paid repayment and amount above the outstanding amount of the loan
+		t.Run("POST /api/v1/user/loan/repayment 400", func(t *testing.T) {
+			if status := repay(t, loanDetails.Repayments[0].RepaymentId, "100"); status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+			if status := repay(t, loanDetails.Repayments[1].RepaymentId, "1501"); status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+			if status := repay(t, loanDetails.Repayments[1].RepaymentId, "0.001"); status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+
+			body := []byte(fmt.Sprintf(`{"repayment-id": "%s", "amount": 100, "channel": "CHEQUE"}`,
+				loanDetails.Repayments[1].RepaymentId))
+			status, _ := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan/repayment", body, customerToken)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+		})
+
+		// the loan is paid once nothing is outstanding
+		t.Run("POST /api/v1/user/loan/repayment 200", func(t *testing.T) {
+			if status := repay(t, loanDetails.Repayments[1].RepaymentId, "1500"); status != 200 {
+				t.Fatalf("expected status 200 but got %d", status)
+			}
+
+			loan := getLoan(t)
+			if loan.Status != dto.LoanStatusPaid || !loan.Outstanding.IsZero() {
+				t.Errorf("expected the loan to be paid but got %v with outstanding amount %v", loan.Status,
+					loan.Outstanding)
+			}
+		})
+
+		// every payment is recorded with the repayments it paid
+		t.Run("GET /api/v1/user/loan/:id/payments 200", func(t *testing.T) {
+			status, body := callAPI(t, "GET", "http://localhost:8085/api/v1/user/loan/"+loanDetails.LoanId+"/payments", nil, customerToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d", status)
+			}
+			response := struct {
+				Payments []*dto.PaymentDetails `json:"payments"`
+			}{}
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatal(err)
+			}
+
+			expected := []struct {
+				amount      int64
+				allocations int
+			}{{400, 1}, {1100, 2}, {1500, 2}}
+			if len(response.Payments) != len(expected) {
+				t.Fatalf("expected %d payments but got %v", len(expected), string(body))
+			}
+			for i, payment := range response.Payments {
+				if !payment.Amount.Equal(decimal.NewFromInt(expected[i].amount)) ||
+					len(payment.Allocations) != expected[i].allocations || payment.Channel != dto.PaymentChannelCard {
+					t.Errorf("expected payment of %d with %d allocations but got %v", expected[i].amount,
+						expected[i].allocations, string(body))
+				}
+			}
+			if allocation := response.Payments[1].Allocations[1]; allocation.RepaymentId != loanDetails.Repayments[1].RepaymentId ||
+				!allocation.Amount.Equal(decimal.NewFromInt(500)) {
+				t.Errorf("expected the excess of 500 to pay the second repayment but got %v", allocation)
+			}
+
+			status, body = callAPI(t, "GET", "http://localhost:8085/api/v1/user/loan/"+loanDetails.LoanId+"/payments", nil, CustomerToken1)
+			if status != 200 || strings.Contains(string(body), loanDetails.LoanId) {
+				t.Errorf("expected no payments of the loan of another customer but got %d %v", status, string(body))
+			}
+		})
+
+		t.Run("GET /api/v1/admin/loan/:id/payments 200", func(t *testing.T) {
+			status, body := callAPI(t, "GET", "http://localhost:8085/api/v1/admin/loan/"+loanDetails.LoanId+"/payments", nil, AdminToken)
+			if status != 200 || !strings.Contains(string(body), loanDetails.LoanId) {
+				t.Errorf("expected the payments of the loan but got %d %v", status, string(body))
+			}
+		})
+	})
+
+	t.Run("Pay Loan", func(t *testing.T) {
+		customerToken, _ := login(t, "http://localhost:8085/api/v1/auth/customer/login", ValidUser3)
+
+		// 1% interest per weekly repayment, each repayment is 1000 principal and 30 interest
+		body := []byte(`{"amount": 3000, "term": 3, "interest-rate": 52, "interest-method": "FLAT"}`)
+		status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", body, customerToken)
+		if status != 201 {
+			t.Fatalf("expected status 201 but got %d, %v", status, string(body))
+		}
+		loanDetails := &dto.LoanDetails{}
+		if err := json.Unmarshal(body, loanDetails); err != nil {
+			t.Fatal(err)
+		}
+		payUrl := "http://localhost:8085/api/v1/user/loan/" + loanDetails.LoanId + "/pay"
+
+		// pending loans don't accept payments
+		status, _ = callAPI(t, "POST", payUrl, []byte(`{"amount": 1500}`), customerToken)
+		if status != 400 {
+			t.Errorf("expected status 400 but got %d", status)
+		}
+
+		body = []byte(fmt.Sprintf(`{"loan-id": "%s"}`, loanDetails.LoanId))
+		status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/approve", body, AdminToken)
+		if status != 200 {
+			t.Fatalf("expected status 200 but got %d", status)
+		}
+
+		t.Run("POST /api/v1/user/loan/:id/pay 404", func(t *testing.T) {
+			status, _ := callAPI(t, "POST", payUrl, []byte(`{"amount": 1500}`), CustomerToken1)
+			if status != 404 {
+				t.Errorf("expected status 404 but got %d", status)
+			}
+		})
+
+		t.Run("POST /api/v1/user/loan/:id/pay 400", func(t *testing.T) {
+			status, _ := callAPI(t, "POST", payUrl, []byte(`{"amount": 3091}`), customerToken)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+			status, _ = callAPI(t, "POST", payUrl, []byte(`{"amount": 0}`), customerToken)
+			if status != 400 {
+				t.Errorf("expected status 400 but got %d", status)
+			}
+		})
+
+		// the oldest repayment is paid first, the interest of each repayment before its principal
+		t.Run("POST /api/v1/user/loan/:id/pay 200", func(t *testing.T) {
+			status, body := callAPI(t, "POST", payUrl, []byte(`{"amount": 1500, "channel": "BANK_TRANSFER"}`), customerToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d, %v", status, string(body))
+			}
+			payment := &dto.PaymentDetails{}
+			if err := json.Unmarshal(body, payment); err != nil {
+				t.Fatal(err)
+			}
+			if !payment.Amount.Equal(decimal.NewFromInt(1500)) || payment.Channel != dto.PaymentChannelBankTransfer ||
+				len(payment.Allocations) != 2 {
+				t.Fatalf("expected the payment to pay two repayments but got %v", string(body))
+			}
+
+			expected := []struct {
+				repaymentId string
+				principal   int64
+				interest    int64
+			}{
+				{loanDetails.Repayments[0].RepaymentId, 1000, 30},
+				{loanDetails.Repayments[1].RepaymentId, 440, 30},
+			}
+			for i, allocation := range payment.Allocations {
+				if allocation.RepaymentId != expected[i].repaymentId ||
+					!allocation.Principal.Equal(decimal.NewFromInt(expected[i].principal)) ||
+					!allocation.Interest.Equal(decimal.NewFromInt(expected[i].interest)) {
+					t.Errorf("expected allocation %d of principal %d and interest %d but got %v", i+1,
+						expected[i].principal, expected[i].interest, string(body))
+				}
+			}
+		})
+
+		t.Run("POST /api/v1/user/loan/:id/pay 200", func(t *testing.T) {
+			status, body := callAPI(t, "POST", payUrl, []byte(`{"amount": 1590}`), customerToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d, %v", status, string(body))
+			}
+
+			status, body = callAPI(t, "GET", "http://localhost:8085/api/v1/user/loans", nil, customerToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d", status)
+			}
+			response := struct {
+				Loans []*dto.LoanDetails `json:"loans"`
+			}{}
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatal(err)
+			}
+			for _, loan := range response.Loans {
+				if loan.LoanId == loanDetails.LoanId && (loan.Status != dto.LoanStatusPaid || !loan.Outstanding.IsZero()) {
+					t.Errorf("expected the loan to be paid but got %v with outstanding amount %v", loan.Status,
+						loan.Outstanding)
+				}
+			}
+		})
+	})
+
+	t.Run("Loan Status History", func(t *testing.T) {
+		customerToken, _ := login(t, "http://localhost:8085/api/v1/auth/customer/login", ValidUser3)
+
+		body := []byte(`{"amount": 3000, "term": 3}`)
+		status, body := callAPI(t, "POST", "http://localhost:8085/api/v1/user/loan", body, customerToken)
+		if status != 201 {
+			t.Fatalf("expected status 201 but got %d, %v", status, string(body))
+		}
+		loanDetails := &dto.LoanDetails{}
+		if err := json.Unmarshal(body, loanDetails); err != nil {
+			t.Fatal(err)
+		}
+
+		body = []byte(fmt.Sprintf(`{"loan-id": "%s"}`, loanDetails.LoanId))
+		status, _ = callAPI(t, "POST", "http://localhost:8085/api/v1/admin/loan/approve", body, AdminToken)
+		if status != 200 {
+			t.Fatalf("expected status 200 but got %d", status)
+		}
+
+		t.Run("GET /api/v1/user/loan/:id/history 200", func(t *testing.T) {
+			status, body := callAPI(t, "GET", "http://localhost:8085/api/v1/user/loan/"+loanDetails.LoanId+"/history", nil, customerToken)
+			if status != 200 {
+				t.Fatalf("expected status 200 but got %d, %v", status, string(body))
+			}
+			response := struct {
+				History []*dto.LoanStatusHistory `json:"history"`
+			}{}
+			if err := json.Unmarshal(body, &response); err != nil {
+				t.Fatal(err)
+			}
+			if len(response.History) != 2 {
+				t.Fatalf("expected 2 transitions but got %v", string(body))
+			}
+			created, approved := response.History[0], response.History[1]
+			if created.FromStatus != "" || created.ToStatus != dto.LoanStatusPending || created.ActorType != "customer" {
+				t.Errorf("expected creation by the customer but got %v", created)
+			}
+			if approved.FromStatus != dto.LoanStatusPending || approved.ToStatus != dto.LoanStatusApproved ||
+				approved.ActorType != "admin" {
+				t.Errorf("expected approval by the admin but got %v", approved)
+			}
+		})
+
+		t.Run("GET /api/v1/user/loan/:id/history 404", func(t *testing.T) {
+			status, _ := callAPI(t, "GET", "http://localhost:8085/api/v1/user/loan/"+loanDetails.LoanId+"/history", nil, CustomerToken1)
+			if status != 404 {
+				t.Errorf("expected status 404 but got %d", status)
+			}
+		})
+
+		t.Run("GET /api/v1/admin/loan/:id/history 200", func(t *testing.T) {
+			status, body := callAPI(t, "GET", "http://localhost:8085/api/v1/admin/loan/"+loanDetails.LoanId+"/history", nil, AdminToken)
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d, %v", status, string(body))
+			}
+		})
+
+		t.Run("GET /api/v1/admin/loan/:id/history 401", func(t *testing.T) {
+			status, _ := callAPI(t, "GET", "http://localhost:8085/api/v1/admin/loan/"+loanDetails.LoanId+"/history", nil, customerToken)
+			if status != 401 {
+				t.Errorf("expected status 401 but got %d", status)
+			}
+		})
+	})
+
+	t.Run("JWKS", func(t *testing.T) {
+		t.Run("GET /.well-known/jwks.json 200", func(t *testing.T) {
+			status, body := callAPI(t, "GET", "http://localhost:8085/.well-known/jwks.json", nil, "")
+			if status != 200 {
+				t.Errorf("expected status 200 but got %d", status)
+			}
+
+			keySet := &dto.JSONWebKeySet{}
+			if err := json.Unmarshal(body, &keySet); err != nil {
+				t.Fatal(err)
+			}
+			if keySet.Keys == nil {
+				t.Errorf("response body doesn't contain \"keys\" field, %v", string(body))
+			}
+		})
+	})
 }
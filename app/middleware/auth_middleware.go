@@ -5,15 +5,19 @@ import (
 	"github.com/s8sg/mini-loan-app/app/app_errors"
 	"github.com/s8sg/mini-loan-app/app/service"
 	"log"
+	"net/http"
 	"strings"
 )
 
 const (
-	ROLE_KEY    = "role"
-	USER_ID_KEY = "id"
+	ROLE_KEY         = "role"
+	USER_ID_KEY      = "id"
+	AUTH_CONTEXT_KEY = "auth"
 )
 
-func AuthMiddleware(service service.AuthService, role string) gin.HandlerFunc {
+// AuthMiddleware : authenticates the bearer token of a user or the api key of a machine client,
+// routes declare the permission they need with PermissionMiddleware. Impersonation tokens can only read
+func AuthMiddleware(authService service.AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := c.GetHeader("Authorization")
 		if token == "" {
@@ -22,14 +26,17 @@ func AuthMiddleware(service service.AuthService, role string) gin.HandlerFunc {
 			return
 		}
 
-		splitToken := strings.Split(token, "Bearer ")
-		if len(splitToken) != 2 {
+		var authContext *service.AuthContext
+		var err error
+		if bearerToken, found := strings.CutPrefix(token, "Bearer "); found {
+			authContext, err = authService.ValidateToken(bearerToken)
+		} else if apiKey, found := strings.CutPrefix(token, "ApiKey "); found {
+			authContext, err = authService.ValidateApiKey(apiKey)
+		} else {
 			log.Println("Invalid token provided")
 			app_errors.RespondWithError(c, app_errors.Unauthorised)
 			return
 		}
-
-		authContext, err := service.ValidateToken(splitToken[1], role)
 		if err != nil {
 			log.Println(err)
 			app_errors.RespondWithError(c, app_errors.Unauthorised)
@@ -40,7 +47,69 @@ func AuthMiddleware(service service.AuthService, role string) gin.HandlerFunc {
 		c.Set(ROLE_KEY, authContext.Role)
 		// set userId in request context
 		c.Set(USER_ID_KEY, authContext.UserId)
+		// set auth context for the authorization of the route
+		c.Set(AUTH_CONTEXT_KEY, authContext)
+
+		// impersonation tokens are audited with both identities and are read only
+		if authContext.ImpersonatorId != "" {
+			log.Printf("AUDIT: admin %s as customer %s %s %s\n", authContext.ImpersonatorId, authContext.UserId,
+				c.Request.Method, c.Request.URL.Path)
+			if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+				app_errors.RespondWithError(c, service.ImpersonationReadOnly)
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// PermissionMiddleware : authorizes the authenticated caller for the permission required by the route
+func PermissionMiddleware(authService service.AuthService, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authContext, ok := getAuthContext(c)
+		if !ok {
+			log.Println("auth context not initialized")
+			app_errors.RespondWithError(c, app_errors.Unauthorised)
+			return
+		}
+
+		err := authService.Authorize(authContext, permission)
+		if err != nil {
+			app_errors.RespondWithError(c, err)
+			return
+		}
 
 		c.Next()
 	}
 }
+
+// MfaMiddleware : requires admin sessions to be created with a second factor,
+// customers are rejected by the permission of the route and api keys are exempt
+func MfaMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authContext, ok := getAuthContext(c)
+		if !ok {
+			log.Println("auth context not initialized")
+			app_errors.RespondWithError(c, app_errors.Unauthorised)
+			return
+		}
+
+		if authContext.Role == service.USER_TYPE_ADMIN && !authContext.MfaVerified {
+			log.Printf("User %s didn't login with mfa\n", authContext.UserId)
+			app_errors.RespondWithError(c, service.MfaRequired)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func getAuthContext(c *gin.Context) (*service.AuthContext, bool) {
+	authContextValue, ok := c.Get(AUTH_CONTEXT_KEY)
+	if !ok {
+		return nil, false
+	}
+	authContext, ok := authContextValue.(*service.AuthContext)
+	return authContext, ok
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"github.com/s8sg/mini-loan-app/app/dto"
+	"time"
+)
+
+type ApiKeyRepository interface {
+	CreateApiKey(apiKeyDetails *dto.ApiKeyDetails) error
+
+	GetApiKeyById(apiKeyId string) (*dto.ApiKeyDetails, error)
+
+	GetAllApiKeys() ([]*dto.ApiKeyDetails, error)
+
+	UpdateLastUsed(apiKeyId string, lastUsedAt time.Time) error
+
+	RevokeApiKey(apiKeyId string) error
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/lib/pq"
+	"github.com/s8sg/mini-loan-app/app/dto"
+	"github.com/s8sg/mini-loan-app/app/util"
+	"time"
+)
+
+type SqlApiKeyRepository struct {
+	*sql.DB
+}
+
+// GetApiKeyRepository : factory function initialize SqlApiKeyRepository
+func GetApiKeyRepository(db *sql.DB) ApiKeyRepository {
+	apiKeyRepository := &SqlApiKeyRepository{
+		DB: db,
+	}
+	return apiKeyRepository
+}
+
+const apiKeyColumns = "id, name, key_hash, permissions, created_by, expires_at, last_used_at, revoked, created_at, updated_at"
+
+func (db *SqlApiKeyRepository) CreateApiKey(apiKeyDetails *dto.ApiKeyDetails) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "INSERT INTO api_keys (id, name, key_hash, permissions, created_by, expires_at, created_at, updated_at) " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8)"
+	res, err := db.ExecContext(ctx, query, apiKeyDetails.ApiKeyId, apiKeyDetails.Name, apiKeyDetails.KeyHash,
+		pq.Array(apiKeyDetails.Permissions), apiKeyDetails.CreatedBy, apiKeyDetails.ExpiresAt,
+		apiKeyDetails.CreatedTimestamp, apiKeyDetails.UpdatedTimestamp)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlApiKeyRepository) GetApiKeyById(apiKeyId string) (*dto.ApiKeyDetails, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE id = $1"
+	row := db.QueryRowContext(ctx, query, apiKeyId)
+	return scanApiKey(row)
+}
+
+func (db *SqlApiKeyRepository) GetAllApiKeys() ([]*dto.ApiKeyDetails, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT " + apiKeyColumns + " FROM api_keys ORDER BY created_at"
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	apiKeyDetailsList := make([]*dto.ApiKeyDetails, 0)
+	for rows.Next() {
+		apiKeyDetails, err := scanApiKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		apiKeyDetailsList = append(apiKeyDetailsList, apiKeyDetails)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return apiKeyDetailsList, nil
+}
+
+func (db *SqlApiKeyRepository) UpdateLastUsed(apiKeyId string, lastUsedAt time.Time) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "UPDATE api_keys set last_used_at = $1 WHERE id = $2"
+	res, err := db.ExecContext(ctx, query, lastUsedAt, apiKeyId)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlApiKeyRepository) RevokeApiKey(apiKeyId string) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "UPDATE api_keys set revoked = TRUE, updated_at = $1 WHERE id = $2"
+	res, err := db.ExecContext(ctx, query, util.GetCurrentTimeInUtc(), apiKeyId)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func scanApiKey(row rowScanner) (*dto.ApiKeyDetails, error) {
+	apiKeyDetails := &dto.ApiKeyDetails{}
+	lastUsedAt := sql.NullTime{}
+	if err := row.Scan(&apiKeyDetails.ApiKeyId, &apiKeyDetails.Name, &apiKeyDetails.KeyHash,
+		pq.Array(&apiKeyDetails.Permissions), &apiKeyDetails.CreatedBy, &apiKeyDetails.ExpiresAt, &lastUsedAt,
+		&apiKeyDetails.Revoked, &apiKeyDetails.CreatedTimestamp, &apiKeyDetails.UpdatedTimestamp); err != nil {
+		return nil, err
+	}
+	if lastUsedAt.Valid {
+		apiKeyDetails.LastUsedAt = &lastUsedAt.Time
+	}
+	return apiKeyDetails, nil
+}
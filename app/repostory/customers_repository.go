@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/s8sg/mini-loan-app/app/dto"
+)
+
+type CustomerRepository interface {
+	CreateCustomer(customerDetails *dto.CustomerDetails, transactionalContext *Transaction) error
+
+	GetCustomerById(customerId string) (*dto.CustomerDetails, error)
+
+	GetAllCustomers() ([]*dto.CustomerDetails, error)
+
+	UpdateCustomerProfile(customerDetails *dto.CustomerDetails) error
+
+	UpdateCustomerStatus(customerId string, status string) error
+
+	DeleteCustomer(customerId string, transactionalContext *Transaction) error
+
+	CreateTransaction(ctx context.Context, opts *sql.TxOptions) (*Transaction, error)
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/s8sg/mini-loan-app/app/dto"
+	"github.com/s8sg/mini-loan-app/app/util"
+	"log"
+	"time"
+)
+
+type SqlCustomerRepository struct {
+	*sql.DB
+}
+
+// GetCustomerRepository : factory function initialize SqlCustomerRepository
+func GetCustomerRepository(db *sql.DB) CustomerRepository {
+	customerRepository := &SqlCustomerRepository{
+		DB: db,
+	}
+	return customerRepository
+}
+
+func (db *SqlCustomerRepository) CreateCustomer(customerDetails *dto.CustomerDetails, transactionalContext *Transaction) error {
+	query := "INSERT INTO customers (id, name, email, phone, status) VALUES ($1, $2, $3, $4, $5)"
+	res, err := transactionalContext.tx.ExecContext(transactionalContext.ctx, query, customerDetails.CustomerId,
+		customerDetails.Name, customerDetails.Email, customerDetails.Phone, customerDetails.Status)
+	if err != nil {
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count != 1 {
+		err = fmt.Errorf("no rows updated when inserting row into customers table")
+		return err
+	}
+
+	return nil
+}
+
+func (db *SqlCustomerRepository) GetCustomerById(customerId string) (*dto.CustomerDetails, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT id, name, email, phone, status, created_at, updated_at FROM customers WHERE id = $1"
+	row := db.QueryRowContext(ctx, query, customerId)
+	customerDetails := &dto.CustomerDetails{}
+	if err := row.Scan(&customerDetails.CustomerId, &customerDetails.Name, &customerDetails.Email, &customerDetails.Phone,
+		&customerDetails.Status, &customerDetails.CreatedTimestamp, &customerDetails.UpdatedTimestamp); err != nil {
+		return nil, err
+	}
+	return customerDetails, nil
+}
+
+func (db *SqlCustomerRepository) GetAllCustomers() ([]*dto.CustomerDetails, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT id, name, email, phone, status, created_at, updated_at FROM customers ORDER BY created_at"
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		log.Printf("Error %s when preparing SQL statement", err)
+		return nil, err
+	}
+	defer stmt.Close()
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	customerDetailsList := make([]*dto.CustomerDetails, 0)
+	for rows.Next() {
+		customerDetails := &dto.CustomerDetails{}
+		if err := rows.Scan(&customerDetails.CustomerId, &customerDetails.Name, &customerDetails.Email, &customerDetails.Phone,
+			&customerDetails.Status, &customerDetails.CreatedTimestamp, &customerDetails.UpdatedTimestamp); err != nil {
+			return nil, err
+		}
+		customerDetailsList = append(customerDetailsList, customerDetails)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return customerDetailsList, nil
+}
+
+func (db *SqlCustomerRepository) UpdateCustomerProfile(customerDetails *dto.CustomerDetails) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "UPDATE customers set name = $1, email = $2, phone = $3, updated_at = $4 WHERE id = $5"
+	res, err := db.ExecContext(ctx, query, customerDetails.Name, customerDetails.Email, customerDetails.Phone,
+		util.GetCurrentTimeInUtc(), customerDetails.CustomerId)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlCustomerRepository) UpdateCustomerStatus(customerId string, status string) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "UPDATE customers set status = $1, updated_at = $2 WHERE id = $3"
+	res, err := db.ExecContext(ctx, query, status, util.GetCurrentTimeInUtc(), customerId)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlCustomerRepository) DeleteCustomer(customerId string, transactionalContext *Transaction) error {
+	query := "DELETE FROM customers WHERE id = $1"
+	res, err := transactionalContext.tx.ExecContext(transactionalContext.ctx, query, customerId)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlCustomerRepository) CreateTransaction(ctx context.Context, opts *sql.TxOptions) (*Transaction, error) {
+	return beginTransaction(db.DB, ctx, opts)
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"github.com/s8sg/mini-loan-app/app/dto"
+)
+
+type LoanProductRepository interface {
+	// CreateLoanProduct creates the product and records its terms as the first version
+	CreateLoanProduct(loanProductDetails *dto.LoanProductDetails) error
+
+	// UpdateLoanProduct replaces the terms of the product, increments its version and records the terms of the version
+	UpdateLoanProduct(loanProductDetails *dto.LoanProductDetails) error
+
+	GetLoanProductById(productId string) (*dto.LoanProductDetails, error)
+
+	// GetLoanProductVersion responds with the terms of the product at the version, other fields are not set
+	GetLoanProductVersion(productId string, version int) (*dto.LoanProductDetails, error)
+
+	GetAllLoanProducts() ([]*dto.LoanProductDetails, error)
+
+	GetLoanProductsByStatus(status string) ([]*dto.LoanProductDetails, error)
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/lib/pq"
+	"github.com/s8sg/mini-loan-app/app/dto"
+	"time"
+)
+
+type SqlLoanProductRepository struct {
+	*sql.DB
+}
+
+// GetLoanProductRepository : factory function initialize SqlLoanProductRepository
+func GetLoanProductRepository(db *sql.DB) LoanProductRepository {
+	loanProductRepository := &SqlLoanProductRepository{
+		DB: db,
+	}
+	return loanProductRepository
+}
+
+const loanProductColumns = "id, name, description, status, currency, min_amount, max_amount, terms, interest_rate, " +
+	"interest_method, repayment_frequency, origination_fee_rate, max_open_loans, min_customer_days, version, " +
+	"created_at, updated_at"
+
+const loanProductVersionColumns = "product_id, version, currency, min_amount, max_amount, terms, interest_rate, " +
+	"interest_method, repayment_frequency, origination_fee_rate, created_at"
+
+func (db *SqlLoanProductRepository) CreateLoanProduct(loanProductDetails *dto.LoanProductDetails) (err error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	query := "INSERT INTO loan_products (" + loanProductColumns + ") " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)"
+	res, err := tx.ExecContext(ctx, query, loanProductDetails.ProductId, loanProductDetails.Name,
+		loanProductDetails.Description, loanProductDetails.Status, loanProductDetails.Currency,
+		loanProductDetails.MinAmount, loanProductDetails.MaxAmount, pq.Array(loanProductDetails.Terms),
+		loanProductDetails.InterestRate, loanProductDetails.InterestMethod, loanProductDetails.Frequency,
+		loanProductDetails.OriginationFeeRate, loanProductDetails.MaxOpenLoans, loanProductDetails.MinCustomerDays,
+		loanProductDetails.Version, loanProductDetails.CreatedTimestamp, loanProductDetails.UpdatedTimestamp)
+	if err != nil {
+		return err
+	}
+	if err = checkSingleRowUpdated(res); err != nil {
+		return err
+	}
+	return createLoanProductVersion(ctx, tx, loanProductDetails)
+}
+
+func (db *SqlLoanProductRepository) UpdateLoanProduct(loanProductDetails *dto.LoanProductDetails) (err error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	query := "UPDATE loan_products set name = $1, description = $2, status = $3, currency = $4, min_amount = $5, " +
+		"max_amount = $6, terms = $7, interest_rate = $8, interest_method = $9, repayment_frequency = $10, " +
+		"origination_fee_rate = $11, max_open_loans = $12, min_customer_days = $13, version = version + 1, " +
+		"updated_at = $14 WHERE id = $15 RETURNING version"
+	row := tx.QueryRowContext(ctx, query, loanProductDetails.Name, loanProductDetails.Description,
+		loanProductDetails.Status, loanProductDetails.Currency, loanProductDetails.MinAmount,
+		loanProductDetails.MaxAmount, pq.Array(loanProductDetails.Terms), loanProductDetails.InterestRate,
+		loanProductDetails.InterestMethod, loanProductDetails.Frequency, loanProductDetails.OriginationFeeRate,
+		loanProductDetails.MaxOpenLoans, loanProductDetails.MinCustomerDays, loanProductDetails.UpdatedTimestamp,
+		loanProductDetails.ProductId)
+	if err = row.Scan(&loanProductDetails.Version); err != nil {
+		return err
+	}
+	return createLoanProductVersion(ctx, tx, loanProductDetails)
+}
+
+// createLoanProductVersion : records the terms of the current version of the product
+func createLoanProductVersion(ctx context.Context, tx *sql.Tx, loanProductDetails *dto.LoanProductDetails) error {
+	query := "INSERT INTO loan_product_versions (" + loanProductVersionColumns + ") " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)"
+	res, err := tx.ExecContext(ctx, query, loanProductDetails.ProductId, loanProductDetails.Version,
+		loanProductDetails.Currency, loanProductDetails.MinAmount, loanProductDetails.MaxAmount,
+		pq.Array(loanProductDetails.Terms), loanProductDetails.InterestRate, loanProductDetails.InterestMethod,
+		loanProductDetails.Frequency, loanProductDetails.OriginationFeeRate, loanProductDetails.UpdatedTimestamp)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlLoanProductRepository) GetLoanProductVersion(productId string, version int) (*dto.LoanProductDetails, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT " + loanProductVersionColumns + " FROM loan_product_versions WHERE product_id = $1 AND version = $2"
+	row := db.QueryRowContext(ctx, query, productId, version)
+	loanProductDetails := &dto.LoanProductDetails{}
+	if err := row.Scan(&loanProductDetails.ProductId, &loanProductDetails.Version, &loanProductDetails.Currency,
+		&loanProductDetails.MinAmount, &loanProductDetails.MaxAmount, pq.Array(&loanProductDetails.Terms),
+		&loanProductDetails.InterestRate, &loanProductDetails.InterestMethod, &loanProductDetails.Frequency,
+		&loanProductDetails.OriginationFeeRate, &loanProductDetails.CreatedTimestamp); err != nil {
+		return nil, err
+	}
+	return loanProductDetails, nil
+}
+
+func (db *SqlLoanProductRepository) GetLoanProductById(productId string) (*dto.LoanProductDetails, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT " + loanProductColumns + " FROM loan_products WHERE id = $1"
+	row := db.QueryRowContext(ctx, query, productId)
+	return scanLoanProduct(row)
+}
+
+func (db *SqlLoanProductRepository) GetAllLoanProducts() ([]*dto.LoanProductDetails, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT " + loanProductColumns + " FROM loan_products ORDER BY name"
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanLoanProducts(rows)
+}
+
+func (db *SqlLoanProductRepository) GetLoanProductsByStatus(status string) ([]*dto.LoanProductDetails, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT " + loanProductColumns + " FROM loan_products WHERE status = $1 ORDER BY name"
+	rows, err := db.QueryContext(ctx, query, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanLoanProducts(rows)
+}
+
+func scanLoanProducts(rows *sql.Rows) ([]*dto.LoanProductDetails, error) {
+	loanProductDetailsList := make([]*dto.LoanProductDetails, 0)
+	for rows.Next() {
+		loanProductDetails, err := scanLoanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		loanProductDetailsList = append(loanProductDetailsList, loanProductDetails)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return loanProductDetailsList, nil
+}
+
+func scanLoanProduct(row rowScanner) (*dto.LoanProductDetails, error) {
+	loanProductDetails := &dto.LoanProductDetails{}
+	if err := row.Scan(&loanProductDetails.ProductId, &loanProductDetails.Name, &loanProductDetails.Description,
+		&loanProductDetails.Status, &loanProductDetails.Currency, &loanProductDetails.MinAmount,
+		&loanProductDetails.MaxAmount, pq.Array(&loanProductDetails.Terms), &loanProductDetails.InterestRate,
+		&loanProductDetails.InterestMethod, &loanProductDetails.Frequency, &loanProductDetails.OriginationFeeRate,
+		&loanProductDetails.MaxOpenLoans, &loanProductDetails.MinCustomerDays, &loanProductDetails.Version,
+		&loanProductDetails.CreatedTimestamp, &loanProductDetails.UpdatedTimestamp); err != nil {
+		return nil, err
+	}
+	return loanProductDetails, nil
+}
@@ -3,23 +3,76 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"github.com/s8sg/mini-loan-app/app/dto"
+	"time"
 )
 
 type LoanRepository interface {
-	CreateLoan(loanDetails *dto.LoanDetails) (*dto.LoanDetails, error)
+	// CreateLoan creates the loan with its repayments and records the creation as the first entry of its status history
+	// CreateLoan creates the loan with its repayments, quoteId is the id of the quote the loan is created at (empty
+	// for loans without a quote). A quote creates one loan, reusing it fails with a unique violation
+	CreateLoan(loanDetails *dto.LoanDetails, history *dto.LoanStatusHistory, quoteId string) (*dto.LoanDetails, error)
 
 	GetAllLoansByCustomerId(customerId string) ([]*dto.LoanDetails, error)
 
+	// GetStaleLoanIds responds with the ids of the loans in the status created before createdBefore
+	GetStaleLoanIds(status string, createdBefore time.Time) ([]string, error)
+
+	// CountLoansByCustomerId counts the loans of the customer in any of the statuses
+	CountLoansByCustomerId(customerId string, statuses []string) (int, error)
+
+	// GetLoanById locks the loan until the end of the transaction
 	GetLoanById(loanId string, transactionalContext *Transaction) (*dto.LoanDetails, error)
 
 	UpdateLoanStatus(loanId string, status string, transactionalContext *Transaction) error
 
+	// ApproveLoan updates the status of the loan to APPROVED at approvedAt
+	ApproveLoan(loanId string, approvedAt time.Time, transactionalContext *Transaction) error
+
+	// RejectLoan updates the status of the loan to REJECTED with the reason of the rejection
+	RejectLoan(loanId string, reason string, notes string, transactionalContext *Transaction) error
+
+	// RescheduleLoan updates the start date of the loan and the due dates of its repayments
+	RescheduleLoan(loanDetails *dto.LoanDetails, transactionalContext *Transaction) error
+
+	// OfferLoan updates the status of the loan to OFFERED with the terms of the counter-offer and replaces
+	// its repayment schedule
+	OfferLoan(loanDetails *dto.LoanDetails, transactionalContext *Transaction) error
+
+	// CreateLoanApproval records the approval of the loan by a staff user or api key
+	CreateLoanApproval(approval *dto.LoanApprovalDetails, transactionalContext *Transaction) error
+
+	// TouchLoan updates the loan without changing it, concurrent transactions locking the loan fail to serialize
+	TouchLoan(loanId string, transactionalContext *Transaction) error
+
+	// GetLoanApproverIds responds with the staff users and api keys that approved the loan
+	GetLoanApproverIds(loanId string, transactionalContext *Transaction) ([]string, error)
+
+	// GetLoanApprovals responds with the approvals of the loan in the order they were made
+	GetLoanApprovals(loanId string) ([]*dto.LoanApprovalDetails, error)
+
+	// CreateLoanStatusHistory records a transition of the loan status in the transaction of the transition
+	CreateLoanStatusHistory(history *dto.LoanStatusHistory, transactionalContext *Transaction) error
+
+	// GetLoanStatusHistory responds with the transitions of the loan status in the order they were made,
+	// only for the loans of the customer if customerId is not empty
+	GetLoanStatusHistory(loanId string, customerId string) ([]*dto.LoanStatusHistory, error)
+
 	GetRepaymentsByLoanId(loanId string, transactionalContext *Transaction) ([]*dto.RepaymentDetails, error)
 
 	GetRepaymentById(repaymentId string, transactionalContext *Transaction) (*dto.RepaymentDetails, error)
 
-	UpdateRepaymentStatus(id string, status string, tx *Transaction) error
+	// UpdateRepaymentPayment updates the amounts paid of the repayment and its status
+	UpdateRepaymentPayment(repaymentDetails *dto.RepaymentDetails, tx *Transaction) error
+
+	// CreatePayment records the payment with its allocations to the repayments in the transaction updating
+	// the repayments
+	CreatePayment(payment *dto.PaymentDetails, transactionalContext *Transaction) error
+
+	// GetPaymentsByLoanId responds with the payments of the loan in the order they were received,
+	// only for the loans of the customer if customerId is not empty
+	GetPaymentsByLoanId(loanId string, customerId string) ([]*dto.PaymentDetails, error)
 
 	CreateTransaction(ctx context.Context, opts *sql.TxOptions) (*Transaction, error)
 }
@@ -36,3 +89,34 @@ func (t *Transaction) Rollback() error {
 func (t *Transaction) Commit() error {
 	return t.tx.Commit()
 }
+
+// beginTransaction : starts a transaction on db, shared by all sql repositories
+func beginTransaction(db *sql.DB, ctx context.Context, opts *sql.TxOptions) (*Transaction, error) {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transactio: %w", err)
+	}
+	return &Transaction{
+		tx:  tx,
+		ctx: ctx,
+	}, nil
+}
+
+// checkSingleRowUpdated : validates that exactly one row was affected by the statement
+func checkSingleRowUpdated(res sql.Result) error {
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count != 1 {
+		return fmt.Errorf("no rows updated")
+	}
+
+	return nil
+}
+
+// rowScanner : common interface of sql.Row and sql.Rows to share the scanning of a row
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
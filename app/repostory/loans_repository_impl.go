@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"github.com/lib/pq"
 	"github.com/s8sg/mini-loan-app/app/dto"
 	"github.com/s8sg/mini-loan-app/app/util"
 	"log"
@@ -26,7 +27,8 @@ func GetLoanRepository(db *sql.DB) LoanRepository {
 	return loanRepository
 }
 
-func (db *SqlLoanRepository) CreateLoan(loanDetails *dto.LoanDetails) (*dto.LoanDetails, error) {
+func (db *SqlLoanRepository) CreateLoan(loanDetails *dto.LoanDetails, history *dto.LoanStatusHistory,
+	quoteId string) (*dto.LoanDetails, error) {
 
 	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
 	defer cancelFunc()
@@ -48,10 +50,15 @@ func (db *SqlLoanRepository) CreateLoan(loanDetails *dto.LoanDetails) (*dto.Loan
 		tx.Commit()
 	}()
 
-	query := "INSERT INTO loans (id, customer_id, amount, term, status, start_date) VALUES ($1, $2, $3, $4, $5, $6)"
+	query := "INSERT INTO loans (id, customer_id, amount, currency, term, repayment_frequency, status, start_date, " +
+		"interest_rate, interest_method, origination_fee, product_id, product_version, quote_id) " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)"
 
 	res, err := tx.ExecContext(ctx, query, loanDetails.LoanId, loanDetails.CustomerId, loanDetails.TotalAmount,
-		loanDetails.Term, loanDetails.Status, loanDetails.StartDate)
+		loanDetails.Currency, loanDetails.Term, loanDetails.Frequency, loanDetails.Status, loanDetails.StartDate,
+		loanDetails.InterestRate, loanDetails.InterestMethod, loanDetails.OriginationFee,
+		sql.NullString{String: loanDetails.ProductId, Valid: loanDetails.ProductId != ""}, loanDetails.ProductVersion,
+		sql.NullString{String: quoteId, Valid: quoteId != ""})
 	if err != nil {
 		log.Printf("Error %s when inserting row into loans table", err)
 		return nil, err
@@ -66,23 +73,15 @@ func (db *SqlLoanRepository) CreateLoan(loanDetails *dto.LoanDetails) (*dto.Loan
 		return nil, err
 	}
 
-	for _, repayment := range loanDetails.Repayments {
-		query = "INSERT INTO repayments(id, num, loan_id, amount, status, due_date) VALUES ($1, $2, $3, $4, $5, $6)"
-
-		_, err = tx.ExecContext(ctx, query, repayment.RepaymentId, repayment.Number, loanDetails.LoanId, repayment.Amount, repayment.Status, repayment.DueDate)
-		if err != nil {
-			log.Printf("Error %s when inserting row into repayments table", err)
-			return nil, err
-		}
-		count, err = res.RowsAffected()
-		if err != nil {
-			return nil, err
-		}
+	err = insertRepayments(ctx, tx, loanDetails.LoanId, loanDetails.Repayments)
+	if err != nil {
+		return nil, err
+	}
 
-		if count != 1 {
-			err = fmt.Errorf("no rows updated when inserting row into repayment table")
-			return nil, err
-		}
+	err = insertLoanStatusHistory(ctx, tx, history)
+	if err != nil {
+		log.Printf("Error %s when inserting row into loan_status_history table", err)
+		return nil, err
 	}
 
 	return loanDetails, nil
@@ -94,7 +93,9 @@ func (db *SqlLoanRepository) GetAllLoansByCustomerId(customer string) ([]*dto.Lo
 
 	// TODO: This can later be done with a single query with join statement
 
-	query := "SELECT id, customer_id, amount, term, status, start_date, created_at, updated_at FROM loans WHERE customer_id = $1"
+	query := "SELECT id, customer_id, amount, currency, term, repayment_frequency, status, start_date, interest_rate, interest_method, " +
+		"origination_fee, COALESCE(product_id, ''), product_version, rejection_reason, rejection_notes, approved_at, created_at, updated_at " +
+		"FROM loans WHERE customer_id = $1"
 	stmt, err := db.PrepareContext(ctx, query)
 	if err != nil {
 		log.Printf("Error %s when preparing SQL statement", err)
@@ -111,12 +112,20 @@ func (db *SqlLoanRepository) GetAllLoansByCustomerId(customer string) ([]*dto.Lo
 
 	for rows.Next() {
 		loanDetails := &dto.LoanDetails{}
-		if err := rows.Scan(&loanDetails.LoanId, &loanDetails.CustomerId, &loanDetails.TotalAmount, &loanDetails.Term,
-			&loanDetails.Status, &loanDetails.StartDate, &loanDetails.CreatedTimestamp, &loanDetails.UpdatedTimestamp); err != nil {
+		approvedAt := sql.NullTime{}
+		if err := rows.Scan(&loanDetails.LoanId, &loanDetails.CustomerId, &loanDetails.TotalAmount, &loanDetails.Currency, &loanDetails.Term, &loanDetails.Frequency,
+			&loanDetails.Status, &loanDetails.StartDate, &loanDetails.InterestRate, &loanDetails.InterestMethod,
+			&loanDetails.OriginationFee, &loanDetails.ProductId, &loanDetails.ProductVersion, &loanDetails.RejectionReason,
+			&loanDetails.RejectionNotes, &approvedAt, &loanDetails.CreatedTimestamp, &loanDetails.UpdatedTimestamp); err != nil {
 			return nil, err
 		}
+		if approvedAt.Valid {
+			loanDetails.ApprovedAt = &approvedAt.Time
+		}
 
-		query = "SELECT id, num, amount, status, due_date, created_at, updated_at FROM repayments WHERE loan_id = $1"
+		query = "SELECT id, num, amount, principal, interest, fee, paid_amount, paid_principal, paid_interest, paid_fee, status, " +
+			"due_date, created_at, updated_at " +
+			"FROM repayments WHERE loan_id = $1 ORDER BY num"
 		stmt2, err := db.PrepareContext(ctx, query)
 		if err != nil {
 			log.Printf("Error %s when preparing SQL statement", err)
@@ -134,6 +143,8 @@ func (db *SqlLoanRepository) GetAllLoansByCustomerId(customer string) ([]*dto.Lo
 		for rows2.Next() {
 			repaymentDetails := &dto.RepaymentDetails{}
 			if err := rows2.Scan(&repaymentDetails.RepaymentId, &repaymentDetails.Number, &repaymentDetails.Amount,
+				&repaymentDetails.Principal, &repaymentDetails.Interest, &repaymentDetails.Fee, &repaymentDetails.PaidAmount,
+				&repaymentDetails.PaidPrincipal, &repaymentDetails.PaidInterest, &repaymentDetails.PaidFee,
 				&repaymentDetails.Status, &repaymentDetails.DueDate, &repaymentDetails.CreatedTimestamp,
 				&repaymentDetails.UpdatedTimestamp); err != nil {
 				return nil, err
@@ -145,6 +156,7 @@ func (db *SqlLoanRepository) GetAllLoansByCustomerId(customer string) ([]*dto.Lo
 		}
 
 		loanDetails.Repayments = repaymentDetailsList
+		loanDetails.UpdateOutstanding()
 
 		loanDetailsList = append(loanDetailsList, loanDetails)
 	}
@@ -154,6 +166,44 @@ func (db *SqlLoanRepository) GetAllLoansByCustomerId(customer string) ([]*dto.Lo
 	return loanDetailsList, nil
 }
 
+func (db *SqlLoanRepository) GetStaleLoanIds(status string, createdBefore time.Time) ([]string, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT id FROM loans WHERE status = $1 AND created_at < $2 ORDER BY created_at"
+	rows, err := db.QueryContext(ctx, query, status, createdBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	loanIds := make([]string, 0)
+	for rows.Next() {
+		loanId := ""
+		if err := rows.Scan(&loanId); err != nil {
+			return nil, err
+		}
+		loanIds = append(loanIds, loanId)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return loanIds, nil
+}
+
+func (db *SqlLoanRepository) CountLoansByCustomerId(customerId string, statuses []string) (int, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT COUNT(*) FROM loans WHERE customer_id = $1 AND status = ANY($2)"
+	row := db.QueryRowContext(ctx, query, customerId, pq.Array(statuses))
+	count := 0
+	if err := row.Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 func (db *SqlLoanRepository) UpdateLoanStatus(loanId string, status string, transactionalContext *Transaction) error {
 
 	query := "UPDATE loans set status = $1, updated_at = $2 WHERE id = $3"
@@ -175,14 +225,223 @@ func (db *SqlLoanRepository) UpdateLoanStatus(loanId string, status string, tran
 	return nil
 }
 
+func (db *SqlLoanRepository) ApproveLoan(loanId string, approvedAt time.Time, transactionalContext *Transaction) error {
+	query := "UPDATE loans set status = $1, approved_at = $2, updated_at = $3 WHERE id = $4"
+	res, err := transactionalContext.tx.ExecContext(transactionalContext.ctx, query, dto.LoanStatusApproved, approvedAt,
+		util.GetCurrentTimeInUtc(), loanId)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlLoanRepository) TouchLoan(loanId string, transactionalContext *Transaction) error {
+	query := "UPDATE loans set updated_at = $1 WHERE id = $2"
+	res, err := transactionalContext.tx.ExecContext(transactionalContext.ctx, query, util.GetCurrentTimeInUtc(), loanId)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlLoanRepository) RejectLoan(loanId string, reason string, notes string, transactionalContext *Transaction) error {
+	query := "UPDATE loans set status = $1, rejection_reason = $2, rejection_notes = $3, updated_at = $4 WHERE id = $5"
+	res, err := transactionalContext.tx.ExecContext(transactionalContext.ctx, query, dto.LoanStatusRejected, reason, notes,
+		util.GetCurrentTimeInUtc(), loanId)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlLoanRepository) RescheduleLoan(loanDetails *dto.LoanDetails, transactionalContext *Transaction) error {
+	query := "UPDATE loans set start_date = $1, updated_at = $2 WHERE id = $3"
+	res, err := transactionalContext.tx.ExecContext(transactionalContext.ctx, query, loanDetails.StartDate,
+		util.GetCurrentTimeInUtc(), loanDetails.LoanId)
+	if err != nil {
+		return err
+	}
+	err = checkSingleRowUpdated(res)
+	if err != nil {
+		return err
+	}
+
+	for _, repayment := range loanDetails.Repayments {
+		query = "UPDATE repayments set due_date = $1, updated_at = $2 WHERE id = $3"
+		res, err = transactionalContext.tx.ExecContext(transactionalContext.ctx, query, repayment.DueDate,
+			util.GetCurrentTimeInUtc(), repayment.RepaymentId)
+		if err != nil {
+			return err
+		}
+		err = checkSingleRowUpdated(res)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *SqlLoanRepository) OfferLoan(loanDetails *dto.LoanDetails, transactionalContext *Transaction) error {
+	query := "UPDATE loans set status = $1, amount = $2, term = $3, interest_rate = $4, origination_fee = $5, " +
+		"updated_at = $6 WHERE id = $7"
+	res, err := transactionalContext.tx.ExecContext(transactionalContext.ctx, query, dto.LoanStatusOffered,
+		loanDetails.TotalAmount, loanDetails.Term, loanDetails.InterestRate, loanDetails.OriginationFee,
+		util.GetCurrentTimeInUtc(), loanDetails.LoanId)
+	if err != nil {
+		return err
+	}
+	err = checkSingleRowUpdated(res)
+	if err != nil {
+		return err
+	}
+
+	query = "DELETE FROM repayments WHERE loan_id = $1"
+	_, err = transactionalContext.tx.ExecContext(transactionalContext.ctx, query, loanDetails.LoanId)
+	if err != nil {
+		return err
+	}
+	return insertRepayments(transactionalContext.ctx, transactionalContext.tx, loanDetails.LoanId, loanDetails.Repayments)
+}
+
+// insertRepayments : inserts the repayment schedule of the loan
+func insertRepayments(ctx context.Context, tx *sql.Tx, loanId string, repayments []*dto.RepaymentDetails) error {
+	for _, repayment := range repayments {
+		query := "INSERT INTO repayments(id, num, loan_id, amount, principal, interest, fee, status, due_date) " +
+			"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)"
+
+		res, err := tx.ExecContext(ctx, query, repayment.RepaymentId, repayment.Number, loanId, repayment.Amount,
+			repayment.Principal, repayment.Interest, repayment.Fee, repayment.Status, repayment.DueDate)
+		if err != nil {
+			log.Printf("Error %s when inserting row into repayments table", err)
+			return err
+		}
+		err = checkSingleRowUpdated(res)
+		if err != nil {
+			return fmt.Errorf("no rows updated when inserting row into repayment table")
+		}
+	}
+	return nil
+}
+
+func (db *SqlLoanRepository) CreateLoanApproval(approval *dto.LoanApprovalDetails, transactionalContext *Transaction) error {
+	query := "INSERT INTO loan_approvals (loan_id, approver_id, approver_type, created_at) VALUES ($1, $2, $3, $4)"
+	res, err := transactionalContext.tx.ExecContext(transactionalContext.ctx, query, approval.LoanId, approval.ApproverId,
+		approval.ApproverType, approval.CreatedTimestamp)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlLoanRepository) GetLoanApproverIds(loanId string, transactionalContext *Transaction) ([]string, error) {
+	query := "SELECT approver_id FROM loan_approvals WHERE loan_id = $1"
+	rows, err := transactionalContext.tx.QueryContext(transactionalContext.ctx, query, loanId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	approverIds := make([]string, 0)
+	for rows.Next() {
+		approverId := ""
+		if err := rows.Scan(&approverId); err != nil {
+			return nil, err
+		}
+		approverIds = append(approverIds, approverId)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return approverIds, nil
+}
+
+func (db *SqlLoanRepository) GetLoanApprovals(loanId string) ([]*dto.LoanApprovalDetails, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT loan_id, approver_id, approver_type, created_at FROM loan_approvals " +
+		"WHERE loan_id = $1 ORDER BY created_at"
+	rows, err := db.QueryContext(ctx, query, loanId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	approvals := make([]*dto.LoanApprovalDetails, 0)
+	for rows.Next() {
+		approval := &dto.LoanApprovalDetails{}
+		if err := rows.Scan(&approval.LoanId, &approval.ApproverId, &approval.ApproverType,
+			&approval.CreatedTimestamp); err != nil {
+			return nil, err
+		}
+		approvals = append(approvals, approval)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return approvals, nil
+}
+
+func (db *SqlLoanRepository) CreateLoanStatusHistory(history *dto.LoanStatusHistory, transactionalContext *Transaction) error {
+	return insertLoanStatusHistory(transactionalContext.ctx, transactionalContext.tx, history)
+}
+
+func (db *SqlLoanRepository) GetLoanStatusHistory(loanId string, customerId string) ([]*dto.LoanStatusHistory, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT h.loan_id, h.from_status, h.to_status, h.actor_id, h.actor_type, h.reason, h.created_at " +
+		"FROM loan_status_history h JOIN loans l ON l.id = h.loan_id " +
+		"WHERE h.loan_id = $1 AND ($2 = '' OR l.customer_id = $2) ORDER BY h.id"
+	rows, err := db.QueryContext(ctx, query, loanId, customerId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	historyList := make([]*dto.LoanStatusHistory, 0)
+	for rows.Next() {
+		history := &dto.LoanStatusHistory{}
+		if err := rows.Scan(&history.LoanId, &history.FromStatus, &history.ToStatus, &history.ActorId,
+			&history.ActorType, &history.Reason, &history.CreatedTimestamp); err != nil {
+			return nil, err
+		}
+		historyList = append(historyList, history)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return historyList, nil
+}
+
+// insertLoanStatusHistory : records a transition of the loan status within the transaction of the transition
+func insertLoanStatusHistory(ctx context.Context, tx *sql.Tx, history *dto.LoanStatusHistory) error {
+	query := "INSERT INTO loan_status_history (loan_id, from_status, to_status, actor_id, actor_type, reason, created_at) " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7)"
+	res, err := tx.ExecContext(ctx, query, history.LoanId, history.FromStatus, history.ToStatus, history.ActorId,
+		history.ActorType, history.Reason, history.CreatedTimestamp)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
 func (db *SqlLoanRepository) GetLoanById(loanId string, transactionalContext *Transaction) (*dto.LoanDetails, error) {
-	query := "SELECT id, customer_id, amount, term, status, start_date, created_at, updated_at FROM loans WHERE id = $1"
+	query := "SELECT id, customer_id, amount, currency, term, repayment_frequency, status, start_date, interest_rate, interest_method, " +
+		"origination_fee, COALESCE(product_id, ''), product_version, rejection_reason, rejection_notes, approved_at, created_at, updated_at " +
+		"FROM loans WHERE id = $1 FOR UPDATE"
 	row := transactionalContext.tx.QueryRowContext(transactionalContext.ctx, query, loanId)
 	loanDetails := &dto.LoanDetails{}
-	if err := row.Scan(&loanDetails.LoanId, &loanDetails.CustomerId, &loanDetails.TotalAmount, &loanDetails.Term,
-		&loanDetails.Status, &loanDetails.StartDate, &loanDetails.CreatedTimestamp, &loanDetails.UpdatedTimestamp); err != nil {
+	approvedAt := sql.NullTime{}
+	if err := row.Scan(&loanDetails.LoanId, &loanDetails.CustomerId, &loanDetails.TotalAmount, &loanDetails.Currency, &loanDetails.Term, &loanDetails.Frequency,
+		&loanDetails.Status, &loanDetails.StartDate, &loanDetails.InterestRate, &loanDetails.InterestMethod,
+		&loanDetails.OriginationFee, &loanDetails.ProductId, &loanDetails.ProductVersion, &loanDetails.RejectionReason,
+		&loanDetails.RejectionNotes, &approvedAt, &loanDetails.CreatedTimestamp, &loanDetails.UpdatedTimestamp); err != nil {
 		return nil, err
 	}
+	if approvedAt.Valid {
+		loanDetails.ApprovedAt = &approvedAt.Time
+	}
 
 	// TODO: This can later be done with a single query with join statement
 	repaymentDetailsList, err := db.GetRepaymentsByLoanId(loanId, transactionalContext)
@@ -190,12 +449,15 @@ func (db *SqlLoanRepository) GetLoanById(loanId string, transactionalContext *Tr
 		return nil, err
 	}
 	loanDetails.Repayments = repaymentDetailsList
+	loanDetails.UpdateOutstanding()
 
 	return loanDetails, nil
 }
 
 func (db *SqlLoanRepository) GetRepaymentsByLoanId(loanId string, transactionalContext *Transaction) ([]*dto.RepaymentDetails, error) {
-	query := "SELECT id, num, amount, status, due_date, created_at, updated_at FROM repayments WHERE loan_id = $1"
+	query := "SELECT id, num, amount, principal, interest, fee, paid_amount, paid_principal, paid_interest, paid_fee, status, " +
+		"due_date, created_at, updated_at " +
+		"FROM repayments WHERE loan_id = $1 ORDER BY num"
 	stmt, err := transactionalContext.tx.PrepareContext(transactionalContext.ctx, query)
 	if err != nil {
 		log.Printf("Error %s when preparing SQL statement", err)
@@ -210,8 +472,11 @@ func (db *SqlLoanRepository) GetRepaymentsByLoanId(loanId string, transactionalC
 	repaymentDetailsList := make([]*dto.RepaymentDetails, 0)
 	for rows.Next() {
 		repaymentDetails := &dto.RepaymentDetails{}
-		if err := rows.Scan(&repaymentDetails.RepaymentId, &repaymentDetails.Number, &repaymentDetails.Amount, &repaymentDetails.Status,
-			&repaymentDetails.DueDate, &repaymentDetails.CreatedTimestamp, &repaymentDetails.UpdatedTimestamp); err != nil {
+		if err := rows.Scan(&repaymentDetails.RepaymentId, &repaymentDetails.Number, &repaymentDetails.Amount,
+			&repaymentDetails.Principal, &repaymentDetails.Interest, &repaymentDetails.Fee, &repaymentDetails.PaidAmount,
+			&repaymentDetails.PaidPrincipal, &repaymentDetails.PaidInterest, &repaymentDetails.PaidFee,
+			&repaymentDetails.Status, &repaymentDetails.DueDate, &repaymentDetails.CreatedTimestamp,
+			&repaymentDetails.UpdatedTimestamp); err != nil {
 			return nil, err
 		}
 		repaymentDetailsList = append(repaymentDetailsList, repaymentDetails)
@@ -223,20 +488,30 @@ func (db *SqlLoanRepository) GetRepaymentsByLoanId(loanId string, transactionalC
 }
 
 func (db *SqlLoanRepository) GetRepaymentById(repaymentId string, transactionalContext *Transaction) (*dto.RepaymentDetails, error) {
-	query := "SELECT id, num, loan_id, amount, status, due_date, created_at, updated_at FROM repayments WHERE id = $1"
+	query := "SELECT id, num, loan_id, amount, principal, interest, fee, paid_amount, paid_principal, paid_interest, paid_fee, status, " +
+		"due_date, created_at, updated_at " +
+		"FROM repayments WHERE id = $1"
 	row := transactionalContext.tx.QueryRowContext(transactionalContext.ctx, query, repaymentId)
 	repaymentDetails := &dto.RepaymentDetails{}
-	if err := row.Scan(&repaymentDetails.RepaymentId, &repaymentDetails.Number, &repaymentDetails.LoanId, &repaymentDetails.Amount, &repaymentDetails.Status,
-		&repaymentDetails.DueDate, &repaymentDetails.CreatedTimestamp, &repaymentDetails.UpdatedTimestamp); err != nil {
+	if err := row.Scan(&repaymentDetails.RepaymentId, &repaymentDetails.Number, &repaymentDetails.LoanId, &repaymentDetails.Amount,
+		&repaymentDetails.Principal, &repaymentDetails.Interest, &repaymentDetails.Fee, &repaymentDetails.PaidAmount,
+		&repaymentDetails.PaidPrincipal, &repaymentDetails.PaidInterest, &repaymentDetails.PaidFee,
+		&repaymentDetails.Status, &repaymentDetails.DueDate, &repaymentDetails.CreatedTimestamp,
+		&repaymentDetails.UpdatedTimestamp); err != nil {
 		return nil, err
 
 	}
+	repaymentDetails.Outstanding = repaymentDetails.Amount.Sub(repaymentDetails.PaidAmount)
 	return repaymentDetails, nil
 }
 
-func (db *SqlLoanRepository) UpdateRepaymentStatus(repaymentId string, status string, transactionalContext *Transaction) error {
-	query := "UPDATE repayments set status = $1, updated_at = $2 WHERE id = $3"
-	res, err := transactionalContext.tx.ExecContext(transactionalContext.ctx, query, status, util.GetCurrentTimeInUtc(), repaymentId)
+func (db *SqlLoanRepository) UpdateRepaymentPayment(repaymentDetails *dto.RepaymentDetails,
+	transactionalContext *Transaction) error {
+	query := "UPDATE repayments set paid_amount = $1, paid_principal = $2, paid_interest = $3, paid_fee = $4, " +
+		"status = $5, updated_at = $6 WHERE id = $7"
+	res, err := transactionalContext.tx.ExecContext(transactionalContext.ctx, query, repaymentDetails.PaidAmount,
+		repaymentDetails.PaidPrincipal, repaymentDetails.PaidInterest, repaymentDetails.PaidFee, repaymentDetails.Status,
+		util.GetCurrentTimeInUtc(), repaymentDetails.RepaymentId)
 	if err != nil {
 		return err
 	}
@@ -254,13 +529,92 @@ func (db *SqlLoanRepository) UpdateRepaymentStatus(repaymentId string, status st
 	return nil
 }
 
-func (db *SqlLoanRepository) CreateTransaction(ctx context.Context, opts *sql.TxOptions) (*Transaction, error) {
-	tx, err := db.BeginTx(ctx, opts)
+func (db *SqlLoanRepository) CreatePayment(payment *dto.PaymentDetails, transactionalContext *Transaction) error {
+	query := "INSERT INTO payments (id, loan_id, customer_id, amount, currency, channel, created_at) " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7)"
+	res, err := transactionalContext.tx.ExecContext(transactionalContext.ctx, query, payment.PaymentId, payment.LoanId,
+		payment.CustomerId, payment.Amount, payment.Currency, payment.Channel, payment.CreatedTimestamp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create transactio: %w", err)
+		return err
+	}
+	err = checkSingleRowUpdated(res)
+	if err != nil {
+		return err
+	}
+
+	for _, allocation := range payment.Allocations {
+		query = "INSERT INTO payment_allocations (payment_id, repayment_id, amount, principal, interest, fee) " +
+			"VALUES ($1, $2, $3, $4, $5, $6)"
+		res, err = transactionalContext.tx.ExecContext(transactionalContext.ctx, query, payment.PaymentId,
+			allocation.RepaymentId, allocation.Amount, allocation.Principal, allocation.Interest, allocation.Fee)
+		if err != nil {
+			return err
+		}
+		err = checkSingleRowUpdated(res)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *SqlLoanRepository) GetPaymentsByLoanId(loanId string, customerId string) ([]*dto.PaymentDetails, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT p.id, p.loan_id, p.customer_id, p.amount, p.currency, p.channel, p.created_at " +
+		"FROM payments p JOIN loans l ON l.id = p.loan_id " +
+		"WHERE p.loan_id = $1 AND ($2 = '' OR l.customer_id = $2) ORDER BY p.created_at"
+	rows, err := db.QueryContext(ctx, query, loanId, customerId)
+	if err != nil {
+		return nil, err
 	}
-	return &Transaction{
-		tx:  tx,
-		ctx: ctx,
-	}, nil
+	defer rows.Close()
+
+	paymentList := make([]*dto.PaymentDetails, 0)
+	payments := map[string]*dto.PaymentDetails{}
+	for rows.Next() {
+		payment := &dto.PaymentDetails{Allocations: make([]*dto.PaymentAllocation, 0)}
+		if err := rows.Scan(&payment.PaymentId, &payment.LoanId, &payment.CustomerId, &payment.Amount,
+			&payment.Currency, &payment.Channel, &payment.CreatedTimestamp); err != nil {
+			return nil, err
+		}
+		paymentList = append(paymentList, payment)
+		payments[payment.PaymentId] = payment
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(paymentList) == 0 {
+		return paymentList, nil
+	}
+
+	query = "SELECT a.payment_id, a.repayment_id, a.amount, a.principal, a.interest, a.fee " +
+		"FROM payment_allocations a JOIN payments p ON p.id = a.payment_id JOIN repayments r ON r.id = a.repayment_id " +
+		"WHERE p.loan_id = $1 ORDER BY r.num"
+	allocationRows, err := db.QueryContext(ctx, query, loanId)
+	if err != nil {
+		return nil, err
+	}
+	defer allocationRows.Close()
+
+	for allocationRows.Next() {
+		paymentId := ""
+		allocation := &dto.PaymentAllocation{}
+		if err := allocationRows.Scan(&paymentId, &allocation.RepaymentId, &allocation.Amount, &allocation.Principal,
+			&allocation.Interest, &allocation.Fee); err != nil {
+			return nil, err
+		}
+		if payment, ok := payments[paymentId]; ok {
+			payment.Allocations = append(payment.Allocations, allocation)
+		}
+	}
+	if err := allocationRows.Err(); err != nil {
+		return nil, err
+	}
+	return paymentList, nil
+}
+
+func (db *SqlLoanRepository) CreateTransaction(ctx context.Context, opts *sql.TxOptions) (*Transaction, error) {
+	return beginTransaction(db.DB, ctx, opts)
 }
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"github.com/s8sg/mini-loan-app/app/dto"
+	"time"
+)
+
+type LoginAttemptRepository interface {
+	GetLoginAttempts(scope string, subject string) (*dto.LoginAttemptDetails, error)
+
+	// RecordFailedLogin increments the failed count, the count restarts when the last failure is before windowStart
+	RecordFailedLogin(scope string, subject string, failedAt time.Time, windowStart time.Time) (*dto.LoginAttemptDetails, error)
+
+	LockLogin(scope string, subject string, lockedUntil time.Time) error
+
+	// ResetLoginAttempts clears the failed attempts and the lock, returns false if nothing was tracked
+	ResetLoginAttempts(scope string, subject string) (bool, error)
+}
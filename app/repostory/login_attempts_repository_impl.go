@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/s8sg/mini-loan-app/app/dto"
+	"time"
+)
+
+type SqlLoginAttemptRepository struct {
+	*sql.DB
+}
+
+// GetLoginAttemptRepository : factory function initialize SqlLoginAttemptRepository
+func GetLoginAttemptRepository(db *sql.DB) LoginAttemptRepository {
+	loginAttemptRepository := &SqlLoginAttemptRepository{
+		DB: db,
+	}
+	return loginAttemptRepository
+}
+
+func (db *SqlLoginAttemptRepository) GetLoginAttempts(scope string, subject string) (*dto.LoginAttemptDetails, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT scope, subject, failed_count, last_failed_at, locked_until FROM login_attempts " +
+		"WHERE scope = $1 AND subject = $2"
+	row := db.QueryRowContext(ctx, query, scope, subject)
+	return scanLoginAttempts(row)
+}
+
+func (db *SqlLoginAttemptRepository) RecordFailedLogin(scope string, subject string, failedAt time.Time,
+	windowStart time.Time) (*dto.LoginAttemptDetails, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "INSERT INTO login_attempts (scope, subject, failed_count, last_failed_at) VALUES ($1, $2, 1, $3) " +
+		"ON CONFLICT (scope, subject) DO UPDATE SET " +
+		"failed_count = CASE WHEN login_attempts.last_failed_at < $4 THEN 1 ELSE login_attempts.failed_count + 1 END, " +
+		"last_failed_at = EXCLUDED.last_failed_at " +
+		"RETURNING scope, subject, failed_count, last_failed_at, locked_until"
+	row := db.QueryRowContext(ctx, query, scope, subject, failedAt, windowStart)
+	return scanLoginAttempts(row)
+}
+
+func (db *SqlLoginAttemptRepository) LockLogin(scope string, subject string, lockedUntil time.Time) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "UPDATE login_attempts set locked_until = $1 WHERE scope = $2 AND subject = $3"
+	res, err := db.ExecContext(ctx, query, lockedUntil, scope, subject)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlLoginAttemptRepository) ResetLoginAttempts(scope string, subject string) (bool, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "DELETE FROM login_attempts WHERE scope = $1 AND subject = $2"
+	res, err := db.ExecContext(ctx, query, scope, subject)
+	if err != nil {
+		return false, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func scanLoginAttempts(row rowScanner) (*dto.LoginAttemptDetails, error) {
+	loginAttemptDetails := &dto.LoginAttemptDetails{}
+	lockedUntil := sql.NullTime{}
+	if err := row.Scan(&loginAttemptDetails.Scope, &loginAttemptDetails.Subject, &loginAttemptDetails.FailedCount,
+		&loginAttemptDetails.LastFailedAt, &lockedUntil); err != nil {
+		return nil, err
+	}
+	if lockedUntil.Valid {
+		loginAttemptDetails.LockedUntil = &lockedUntil.Time
+	}
+	return loginAttemptDetails, nil
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/s8sg/mini-loan-app/app/dto"
+)
+
+type MfaRepository interface {
+	CreateMfaChallenge(challengeDetails *dto.MfaChallengeDetails) error
+
+	GetMfaChallengeById(challengeId string) (*dto.MfaChallengeDetails, error)
+
+	IncrementMfaChallengeAttempts(challengeId string) error
+
+	// UseMfaChallenge marks the challenge used, fails if it was already used
+	UseMfaChallenge(challengeId string) error
+
+	// ReplaceRecoveryCodes removes the existing recovery codes of the user and stores the new ones
+	ReplaceRecoveryCodes(username string, codeHashes []string, transactionalContext *Transaction) error
+
+	// UseRecoveryCode marks the recovery code used, fails if it doesn't exist or was already used
+	UseRecoveryCode(username string, codeHash string) error
+
+	CreateTransaction(ctx context.Context, opts *sql.TxOptions) (*Transaction, error)
+}
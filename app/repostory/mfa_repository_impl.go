@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/s8sg/mini-loan-app/app/dto"
+	"github.com/s8sg/mini-loan-app/app/util"
+	"time"
+)
+
+type SqlMfaRepository struct {
+	*sql.DB
+}
+
+// GetMfaRepository : factory function initialize SqlMfaRepository
+func GetMfaRepository(db *sql.DB) MfaRepository {
+	mfaRepository := &SqlMfaRepository{
+		DB: db,
+	}
+	return mfaRepository
+}
+
+func (db *SqlMfaRepository) CreateMfaChallenge(challengeDetails *dto.MfaChallengeDetails) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "INSERT INTO mfa_challenges (id, username, challenge_hash, expires_at) VALUES ($1, $2, $3, $4)"
+	res, err := db.ExecContext(ctx, query, challengeDetails.ChallengeId, challengeDetails.Username,
+		challengeDetails.ChallengeHash, challengeDetails.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlMfaRepository) GetMfaChallengeById(challengeId string) (*dto.MfaChallengeDetails, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT id, username, challenge_hash, expires_at, attempts, used, created_at FROM mfa_challenges WHERE id = $1"
+	row := db.QueryRowContext(ctx, query, challengeId)
+	challengeDetails := &dto.MfaChallengeDetails{}
+	if err := row.Scan(&challengeDetails.ChallengeId, &challengeDetails.Username, &challengeDetails.ChallengeHash,
+		&challengeDetails.ExpiresAt, &challengeDetails.Attempts, &challengeDetails.Used,
+		&challengeDetails.CreatedTimestamp); err != nil {
+		return nil, err
+	}
+	return challengeDetails, nil
+}
+
+func (db *SqlMfaRepository) IncrementMfaChallengeAttempts(challengeId string) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "UPDATE mfa_challenges set attempts = attempts + 1 WHERE id = $1"
+	res, err := db.ExecContext(ctx, query, challengeId)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlMfaRepository) UseMfaChallenge(challengeId string) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "UPDATE mfa_challenges set used = TRUE WHERE id = $1 AND used = FALSE"
+	res, err := db.ExecContext(ctx, query, challengeId)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlMfaRepository) ReplaceRecoveryCodes(username string, codeHashes []string,
+	transactionalContext *Transaction) error {
+	query := "DELETE FROM recovery_codes WHERE username = $1"
+	_, err := transactionalContext.tx.ExecContext(transactionalContext.ctx, query, username)
+	if err != nil {
+		return err
+	}
+
+	for _, codeHash := range codeHashes {
+		query = "INSERT INTO recovery_codes (username, code_hash) VALUES ($1, $2)"
+		res, err := transactionalContext.tx.ExecContext(transactionalContext.ctx, query, username, codeHash)
+		if err != nil {
+			return err
+		}
+		if err = checkSingleRowUpdated(res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *SqlMfaRepository) UseRecoveryCode(username string, codeHash string) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "UPDATE recovery_codes set used_at = $1 WHERE username = $2 AND code_hash = $3 AND used_at IS NULL"
+	res, err := db.ExecContext(ctx, query, util.GetCurrentTimeInUtc(), username, codeHash)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlMfaRepository) CreateTransaction(ctx context.Context, opts *sql.TxOptions) (*Transaction, error) {
+	return beginTransaction(db.DB, ctx, opts)
+}
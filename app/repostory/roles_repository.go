@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/s8sg/mini-loan-app/app/dto"
+)
+
+type RoleRepository interface {
+	HasPermission(roles []string, permission string) (bool, error)
+
+	GetAllRoles() ([]*dto.RoleDetails, error)
+
+	GetAllPermissions() ([]*dto.PermissionDetails, error)
+
+	// SaveRole creates or updates the role and replaces its permissions
+	SaveRole(roleDetails *dto.RoleDetails, transactionalContext *Transaction) error
+
+	CountRoles(roles []string) (int, error)
+
+	CreateTransaction(ctx context.Context, opts *sql.TxOptions) (*Transaction, error)
+}
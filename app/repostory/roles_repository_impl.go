@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/lib/pq"
+	"github.com/s8sg/mini-loan-app/app/dto"
+	"github.com/s8sg/mini-loan-app/app/util"
+	"log"
+	"time"
+)
+
+type SqlRoleRepository struct {
+	*sql.DB
+}
+
+// GetRoleRepository : factory function initialize SqlRoleRepository
+func GetRoleRepository(db *sql.DB) RoleRepository {
+	roleRepository := &SqlRoleRepository{
+		DB: db,
+	}
+	return roleRepository
+}
+
+func (db *SqlRoleRepository) HasPermission(roles []string, permission string) (bool, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT EXISTS (SELECT 1 FROM role_permissions WHERE role = ANY($1) AND permission = $2)"
+	row := db.QueryRowContext(ctx, query, pq.Array(roles), permission)
+	allowed := false
+	if err := row.Scan(&allowed); err != nil {
+		return false, err
+	}
+	return allowed, nil
+}
+
+func (db *SqlRoleRepository) GetAllRoles() ([]*dto.RoleDetails, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT r.name, r.description, COALESCE(ARRAY_AGG(rp.permission ORDER BY rp.permission) " +
+		"FILTER (WHERE rp.permission IS NOT NULL), '{}'), r.created_at, r.updated_at " +
+		"FROM roles r LEFT JOIN role_permissions rp ON rp.role = r.name GROUP BY r.name ORDER BY r.name"
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		log.Printf("Error %s when preparing SQL statement", err)
+		return nil, err
+	}
+	defer stmt.Close()
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roleDetailsList := make([]*dto.RoleDetails, 0)
+	for rows.Next() {
+		roleDetails := &dto.RoleDetails{}
+		if err := rows.Scan(&roleDetails.Name, &roleDetails.Description, pq.Array(&roleDetails.Permissions),
+			&roleDetails.CreatedTimestamp, &roleDetails.UpdatedTimestamp); err != nil {
+			return nil, err
+		}
+		roleDetailsList = append(roleDetailsList, roleDetails)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return roleDetailsList, nil
+}
+
+func (db *SqlRoleRepository) GetAllPermissions() ([]*dto.PermissionDetails, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT name, description FROM permissions ORDER BY name"
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissionDetailsList := make([]*dto.PermissionDetails, 0)
+	for rows.Next() {
+		permissionDetails := &dto.PermissionDetails{}
+		if err := rows.Scan(&permissionDetails.Name, &permissionDetails.Description); err != nil {
+			return nil, err
+		}
+		permissionDetailsList = append(permissionDetailsList, permissionDetails)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return permissionDetailsList, nil
+}
+
+func (db *SqlRoleRepository) SaveRole(roleDetails *dto.RoleDetails, transactionalContext *Transaction) error {
+	query := "INSERT INTO roles (name, description) VALUES ($1, $2) " +
+		"ON CONFLICT (name) DO UPDATE SET description = EXCLUDED.description, updated_at = $3"
+	_, err := transactionalContext.tx.ExecContext(transactionalContext.ctx, query, roleDetails.Name,
+		roleDetails.Description, util.GetCurrentTimeInUtc())
+	if err != nil {
+		return err
+	}
+
+	query = "DELETE FROM role_permissions WHERE role = $1"
+	_, err = transactionalContext.tx.ExecContext(transactionalContext.ctx, query, roleDetails.Name)
+	if err != nil {
+		return err
+	}
+
+	for _, permission := range roleDetails.Permissions {
+		query = "INSERT INTO role_permissions (role, permission) VALUES ($1, $2)"
+		res, err := transactionalContext.tx.ExecContext(transactionalContext.ctx, query, roleDetails.Name, permission)
+		if err != nil {
+			return err
+		}
+		if err = checkSingleRowUpdated(res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *SqlRoleRepository) CountRoles(roles []string) (int, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT COUNT(*) FROM roles WHERE name = ANY($1)"
+	row := db.QueryRowContext(ctx, query, pq.Array(roles))
+	count := 0
+	if err := row.Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (db *SqlRoleRepository) CreateTransaction(ctx context.Context, opts *sql.TxOptions) (*Transaction, error) {
+	return beginTransaction(db.DB, ctx, opts)
+}
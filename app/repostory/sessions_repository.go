@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"github.com/s8sg/mini-loan-app/app/dto"
+	"time"
+)
+
+type SessionRepository interface {
+	CreateSession(sessionDetails *dto.SessionDetails) error
+
+	GetSessionById(sessionId string) (*dto.SessionDetails, error)
+
+	// RotateRefreshToken replaces the refresh token only if the current one matches and the session is active
+	RotateRefreshToken(sessionId string, currentHash string, newHash string, expiresAt time.Time) error
+
+	RevokeSession(sessionId string) error
+
+	// RevokeToken adds the jti of an access token to the denylist until the token expires
+	RevokeToken(jti string, expiresAt time.Time) error
+
+	IsTokenRevoked(jti string) (bool, error)
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/s8sg/mini-loan-app/app/dto"
+	"github.com/s8sg/mini-loan-app/app/util"
+	"time"
+)
+
+type SqlSessionRepository struct {
+	*sql.DB
+}
+
+// GetSessionRepository : factory function initialize SqlSessionRepository
+func GetSessionRepository(db *sql.DB) SessionRepository {
+	sessionRepository := &SqlSessionRepository{
+		DB: db,
+	}
+	return sessionRepository
+}
+
+func (db *SqlSessionRepository) CreateSession(sessionDetails *dto.SessionDetails) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	impersonatedBy := sql.NullString{String: sessionDetails.ImpersonatedBy, Valid: sessionDetails.ImpersonatedBy != ""}
+
+	query := "INSERT INTO sessions (id, username, role, refresh_token_hash, expires_at, mfa_verified, impersonated_by) " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7)"
+	res, err := db.ExecContext(ctx, query, sessionDetails.SessionId, sessionDetails.Username, sessionDetails.Role,
+		sessionDetails.RefreshTokenHash, sessionDetails.ExpiresAt, sessionDetails.MfaVerified, impersonatedBy)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlSessionRepository) GetSessionById(sessionId string) (*dto.SessionDetails, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT id, username, role, refresh_token_hash, expires_at, revoked, mfa_verified, impersonated_by, " +
+		"created_at, updated_at FROM sessions WHERE id = $1"
+	row := db.QueryRowContext(ctx, query, sessionId)
+	sessionDetails := &dto.SessionDetails{}
+	var impersonatedBy sql.NullString
+	if err := row.Scan(&sessionDetails.SessionId, &sessionDetails.Username, &sessionDetails.Role,
+		&sessionDetails.RefreshTokenHash, &sessionDetails.ExpiresAt, &sessionDetails.Revoked, &sessionDetails.MfaVerified,
+		&impersonatedBy, &sessionDetails.CreatedTimestamp, &sessionDetails.UpdatedTimestamp); err != nil {
+		return nil, err
+	}
+	sessionDetails.ImpersonatedBy = impersonatedBy.String
+	return sessionDetails, nil
+}
+
+func (db *SqlSessionRepository) RotateRefreshToken(sessionId string, currentHash string, newHash string, expiresAt time.Time) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "UPDATE sessions set refresh_token_hash = $1, expires_at = $2, updated_at = $3 " +
+		"WHERE id = $4 AND refresh_token_hash = $5 AND revoked = FALSE"
+	res, err := db.ExecContext(ctx, query, newHash, expiresAt, util.GetCurrentTimeInUtc(), sessionId, currentHash)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlSessionRepository) RevokeSession(sessionId string) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "UPDATE sessions set revoked = TRUE, updated_at = $1 WHERE id = $2"
+	res, err := db.ExecContext(ctx, query, util.GetCurrentTimeInUtc(), sessionId)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlSessionRepository) RevokeToken(jti string, expiresAt time.Time) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	// expired tokens are rejected anyway, so they are removed from the denylist
+	_, err := db.ExecContext(ctx, "DELETE FROM revoked_tokens WHERE expires_at < $1", util.GetCurrentTimeInUtc())
+	if err != nil {
+		return err
+	}
+
+	query := "INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING"
+	_, err = db.ExecContext(ctx, query, jti, expiresAt)
+	return err
+}
+
+func (db *SqlSessionRepository) IsTokenRevoked(jti string) (bool, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)"
+	revoked := false
+	if err := db.QueryRowContext(ctx, query, jti).Scan(&revoked); err != nil {
+		return false, err
+	}
+	return revoked, nil
+}
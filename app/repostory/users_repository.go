@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/s8sg/mini-loan-app/app/dto"
+	"github.com/shopspring/decimal"
+)
+
+type UserRepository interface {
+	CreateUser(userDetails *dto.UserDetails, transactionalContext *Transaction) error
+
+	GetUserByUsername(username string) (*dto.UserDetails, error)
+
+	DeleteUser(username string, transactionalContext *Transaction) error
+
+	UpdateUserRoles(username string, roles []string) error
+
+	// UpdateApprovalLimit sets the highest loan amount the admin can approve, nil removes the limit
+	UpdateApprovalLimit(username string, approvalLimit *decimal.Decimal) error
+
+	// SetTotpSecret stores the encrypted TOTP secret of a pending enrollment, TOTP stays disabled until activated
+	SetTotpSecret(username string, encryptedSecret string) error
+
+	EnableTotp(username string, lastStep int64, transactionalContext *Transaction) error
+
+	// UpdateTotpLastStep records the step of the used TOTP code, fails if the step was already used (replay)
+	UpdateTotpLastStep(username string, step int64) error
+
+	ResetTotp(username string, transactionalContext *Transaction) error
+
+	CreateTransaction(ctx context.Context, opts *sql.TxOptions) (*Transaction, error)
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/s8sg/mini-loan-app/app/dto"
+	"github.com/s8sg/mini-loan-app/app/util"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+type SqlUserRepository struct {
+	*sql.DB
+}
+
+// GetUserRepository : factory function initialize SqlUserRepository
+func GetUserRepository(db *sql.DB) UserRepository {
+	userRepository := &SqlUserRepository{
+		DB: db,
+	}
+	return userRepository
+}
+
+func (db *SqlUserRepository) CreateUser(userDetails *dto.UserDetails, transactionalContext *Transaction) error {
+	query := "INSERT INTO users (username, secret_hash, roles) VALUES ($1, $2, $3)"
+	res, err := transactionalContext.tx.ExecContext(transactionalContext.ctx, query, userDetails.Username,
+		userDetails.SecretHash, pq.Array(userDetails.Roles))
+	if err != nil {
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count != 1 {
+		err = fmt.Errorf("no rows updated when inserting row into users table")
+		return err
+	}
+
+	return nil
+}
+
+func (db *SqlUserRepository) GetUserByUsername(username string) (*dto.UserDetails, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "SELECT username, secret_hash, roles, totp_secret, totp_enabled, totp_last_step, approval_limit, " +
+		"created_at, updated_at FROM users WHERE username = $1"
+	row := db.QueryRowContext(ctx, query, username)
+	userDetails := &dto.UserDetails{}
+	approvalLimit := decimal.NullDecimal{}
+	if err := row.Scan(&userDetails.Username, &userDetails.SecretHash, pq.Array(&userDetails.Roles),
+		&userDetails.TotpSecret, &userDetails.TotpEnabled, &userDetails.TotpLastStep, &approvalLimit,
+		&userDetails.CreatedTimestamp, &userDetails.UpdatedTimestamp); err != nil {
+		return nil, err
+	}
+	if approvalLimit.Valid {
+		userDetails.ApprovalLimit = &approvalLimit.Decimal
+	}
+	return userDetails, nil
+}
+
+func (db *SqlUserRepository) DeleteUser(username string, transactionalContext *Transaction) error {
+	query := "DELETE FROM users WHERE username = $1"
+	res, err := transactionalContext.tx.ExecContext(transactionalContext.ctx, query, username)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlUserRepository) UpdateUserRoles(username string, roles []string) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "UPDATE users set roles = $1, updated_at = $2 WHERE username = $3"
+	res, err := db.ExecContext(ctx, query, pq.Array(roles), util.GetCurrentTimeInUtc(), username)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlUserRepository) UpdateApprovalLimit(username string, approvalLimit *decimal.Decimal) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	limit := decimal.NullDecimal{}
+	if approvalLimit != nil {
+		limit = decimal.NewNullDecimal(*approvalLimit)
+	}
+	query := "UPDATE users set approval_limit = $1, updated_at = $2 WHERE username = $3"
+	res, err := db.ExecContext(ctx, query, limit, util.GetCurrentTimeInUtc(), username)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlUserRepository) SetTotpSecret(username string, encryptedSecret string) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "UPDATE users set totp_secret = $1, totp_enabled = FALSE, updated_at = $2 WHERE username = $3"
+	res, err := db.ExecContext(ctx, query, encryptedSecret, util.GetCurrentTimeInUtc(), username)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlUserRepository) EnableTotp(username string, lastStep int64, transactionalContext *Transaction) error {
+	query := "UPDATE users set totp_enabled = TRUE, totp_last_step = $1, updated_at = $2 " +
+		"WHERE username = $3 AND totp_secret != ''"
+	res, err := transactionalContext.tx.ExecContext(transactionalContext.ctx, query, lastStep,
+		util.GetCurrentTimeInUtc(), username)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlUserRepository) UpdateTotpLastStep(username string, step int64) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	query := "UPDATE users set totp_last_step = $1 WHERE username = $2 AND totp_last_step < $1"
+	res, err := db.ExecContext(ctx, query, step, username)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlUserRepository) ResetTotp(username string, transactionalContext *Transaction) error {
+	query := "UPDATE users set totp_secret = '', totp_enabled = FALSE, totp_last_step = 0, updated_at = $1 " +
+		"WHERE username = $2"
+	res, err := transactionalContext.tx.ExecContext(transactionalContext.ctx, query, util.GetCurrentTimeInUtc(), username)
+	if err != nil {
+		return err
+	}
+	return checkSingleRowUpdated(res)
+}
+
+func (db *SqlUserRepository) CreateTransaction(ctx context.Context, opts *sql.TxOptions) (*Transaction, error) {
+	return beginTransaction(db.DB, ctx, opts)
+}
@@ -24,36 +24,99 @@ func GetServer(port string) *Server {
 	}
 }
 
+// SetTrustedProxies : proxies allowed to set the client ip (X-Forwarded-For), used to throttle logins per ip
+func (server *Server) SetTrustedProxies(trustedProxies []string) error {
+	return server.router.SetTrustedProxies(trustedProxies)
+}
+
 // InitRoute : takes a list of controller and initialize the routes for the server
 func (server *Server) InitRoute(
 	authService service.AuthService,
 	loanController *controller.LoanController,
 	authController *controller.AuthController,
-	repaymentController *controller.RepaymentController) {
+	repaymentController *controller.RepaymentController,
+	customerController *controller.CustomerController,
+	roleController *controller.RoleController,
+	apiKeyController *controller.ApiKeyController,
+	mfaController *controller.MfaController,
+	loanProductController *controller.LoanProductController) {
 
 	router := server.router
 	// Host swagger
 	router.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// all /v1/user is authenticated and authorized for customer
-	userRoute := router.Group("/api/v1/user",
-		middleware.AuthMiddleware(authService, service.USER_TYPE_CUSTOMER))
+	// public keys to verify the bearer tokens are open
+	router.GET("/.well-known/jwks.json", authController.GetJWKS)
+
+	// requires : authorizes the route for the permission, permissions are granted to roles in db
+	requires := func(permission string) gin.HandlerFunc {
+		return middleware.PermissionMiddleware(authService, permission)
+	}
+
+	// all /v1/user is authenticated, customer routes are authorized with the own scoped permissions
+	userRoute := router.Group("/api/v1/user", middleware.AuthMiddleware(authService))
+
+	userRoute.POST("/loan", requires(service.PERMISSION_LOAN_CREATE_OWN), loanController.CreateLoanHandler)
+	userRoute.POST("/loan/quote", requires(service.PERMISSION_LOAN_CREATE_OWN), loanController.QuoteLoanHandler)
+	userRoute.POST("/loan/offer", requires(service.PERMISSION_LOAN_CREATE_OWN), loanController.RespondToLoanOfferHandler)
+	userRoute.POST("/loan/cancel", requires(service.PERMISSION_LOAN_CANCEL_OWN), loanController.CancelLoanHandler)
+	userRoute.GET("/loans", requires(service.PERMISSION_LOAN_READ_OWN), loanController.GetLoansHandler)
+	userRoute.GET("/loan/:id/history", requires(service.PERMISSION_LOAN_READ_OWN), loanController.GetLoanStatusHistoryHandler)
+	userRoute.GET("/loan-products", requires(service.PERMISSION_LOAN_CREATE_OWN), loanProductController.GetLoanProductsHandler)
+	userRoute.POST("/loan/repayment", requires(service.PERMISSION_REPAYMENT_CREATE_OWN), repaymentController.RepayLoanHandler)
+	userRoute.POST("/loan/:id/pay", requires(service.PERMISSION_REPAYMENT_CREATE_OWN), repaymentController.PayLoanHandler)
+	userRoute.GET("/loan/:id/payments", requires(service.PERMISSION_LOAN_READ_OWN), repaymentController.GetLoanPaymentsHandler)
+	userRoute.GET("/profile", requires(service.PERMISSION_PROFILE_READ_OWN), customerController.GetProfileHandler)
+	userRoute.PUT("/profile", requires(service.PERMISSION_PROFILE_UPDATE_OWN), customerController.UpdateProfileHandler)
+
+	// /v1/admin/mfa is authenticated without mfa, so admins can enroll the second factor
+	adminMfaRoute := router.Group("/api/v1/admin/mfa", middleware.AuthMiddleware(authService))
 
-	userRoute.POST("/loan", loanController.CreateLoanHandler)
-	userRoute.GET("/loans", loanController.GetLoansHandler)
-	userRoute.POST("/loan/repayment", repaymentController.RepayLoanHandler)
+	adminMfaRoute.POST("/enroll", mfaController.EnrollHandler)
+	adminMfaRoute.POST("/activate", mfaController.ActivateHandler)
 
-	// all /v1/admin is authenticated and authorized for admin
-	adminRoute := router.Group("/api/v1/admin",
-		middleware.AuthMiddleware(authService, service.USER_TYPE_ADMIN))
+	// all /v1/admin is authenticated (bearer token or api key) and requires mfa for bearer tokens,
+	// staff routes are authorized with the permission of the route
+	adminRoute := router.Group("/api/v1/admin", middleware.AuthMiddleware(authService), middleware.MfaMiddleware())
 
-	adminRoute.POST("/loan/approve", loanController.ApproveLoanHandler)
+	adminRoute.POST("/loan/approve", requires(service.PERMISSION_LOAN_APPROVE), loanController.ApproveLoanHandler)
+	adminRoute.POST("/loan/reject", requires(service.PERMISSION_LOAN_APPROVE), loanController.RejectLoanHandler)
+	adminRoute.GET("/loan/:id/approvals", requires(service.PERMISSION_LOAN_READ_ANY), loanController.GetLoanApprovalsHandler)
+	adminRoute.GET("/loan/:id/history", requires(service.PERMISSION_LOAN_READ_ANY), loanController.GetAnyLoanStatusHistoryHandler)
+	adminRoute.GET("/loan/:id/payments", requires(service.PERMISSION_LOAN_READ_ANY), repaymentController.GetAnyLoanPaymentsHandler)
+	adminRoute.POST("/loan-product", requires(service.PERMISSION_LOAN_PRODUCT_MANAGE), loanProductController.CreateLoanProductHandler)
+	adminRoute.PUT("/loan-product/:id", requires(service.PERMISSION_LOAN_PRODUCT_MANAGE), loanProductController.UpdateLoanProductHandler)
+	adminRoute.GET("/loan-products", requires(service.PERMISSION_LOAN_PRODUCT_MANAGE), loanProductController.GetAllLoanProductsHandler)
+	adminRoute.GET("/customers", requires(service.PERMISSION_CUSTOMER_READ), customerController.GetCustomersHandler)
+	adminRoute.GET("/customer/:id", requires(service.PERMISSION_CUSTOMER_READ), customerController.GetCustomerHandler)
+	adminRoute.GET("/customer/:id/loans", requires(service.PERMISSION_LOAN_READ_ANY), loanController.GetCustomerLoansHandler)
+	adminRoute.DELETE("/customer/:id", requires(service.PERMISSION_CUSTOMER_MANAGE), customerController.DeleteCustomerHandler)
+	adminRoute.POST("/customer/disable", requires(service.PERMISSION_CUSTOMER_MANAGE), customerController.DisableCustomerHandler)
+	adminRoute.POST("/customer/enable", requires(service.PERMISSION_CUSTOMER_MANAGE), customerController.EnableCustomerHandler)
+	adminRoute.GET("/roles", requires(service.PERMISSION_ROLE_MANAGE), roleController.GetRolesHandler)
+	adminRoute.GET("/permissions", requires(service.PERMISSION_ROLE_MANAGE), roleController.GetPermissionsHandler)
+	adminRoute.PUT("/role", requires(service.PERMISSION_ROLE_MANAGE), roleController.SaveRoleHandler)
+	adminRoute.POST("/user", requires(service.PERMISSION_USER_MANAGE), roleController.CreateStaffUserHandler)
+	adminRoute.POST("/user/roles", requires(service.PERMISSION_USER_MANAGE), roleController.UpdateUserRolesHandler)
+	adminRoute.POST("/user/approval-limit", requires(service.PERMISSION_USER_MANAGE), roleController.UpdateApprovalLimitHandler)
+	adminRoute.POST("/user/mfa/reset", requires(service.PERMISSION_USER_MANAGE), mfaController.ResetMfaHandler)
+	adminRoute.POST("/mfa/recovery-codes", mfaController.RegenerateRecoveryCodesHandler)
+	adminRoute.POST("/login/unlock", requires(service.PERMISSION_USER_MANAGE), authController.UnlockLoginHandler)
+	adminRoute.POST("/impersonate", requires(service.PERMISSION_CUSTOMER_IMPERSONATE), authController.ImpersonateHandler)
+	adminRoute.POST("/api-key", requires(service.PERMISSION_API_KEY_MANAGE), apiKeyController.CreateApiKeyHandler)
+	adminRoute.GET("/api-keys", requires(service.PERMISSION_API_KEY_MANAGE), apiKeyController.GetApiKeysHandler)
+	adminRoute.DELETE("/api-key/:id", requires(service.PERMISSION_API_KEY_MANAGE), apiKeyController.RevokeApiKeyHandler)
 
 	// all /v1/auth is open
 	authRoute := router.Group("/api/v1/auth")
 
+	authRoute.POST("/customer/signup", customerController.SignUpHandler)
 	authRoute.POST("/customer/login", authController.LoginAsCustomer)
 	authRoute.POST("/admin/login", authController.LoginAsAdmin)
+	authRoute.POST("/admin/login/mfa", authController.LoginAsAdminWithMfa)
+	authRoute.POST("/refresh", authController.Refresh)
+	authRoute.POST("/logout", authController.Logout)
+	authRoute.POST("/revoke", authController.RevokeToken)
 }
 
 // Start : starts the server on the provided port (listen to signals)
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"github.com/s8sg/mini-loan-app/app/app_errors"
+	"github.com/s8sg/mini-loan-app/app/controller/dto"
+	responseDto "github.com/s8sg/mini-loan-app/app/dto"
+	repository "github.com/s8sg/mini-loan-app/app/repostory"
+	"github.com/s8sg/mini-loan-app/app/util"
+	"log"
+	"time"
+)
+
+// MaxApiKeyExpiryInDays is the longest lifetime an api key can be issued with
+var MaxApiKeyExpiryInDays = 365
+
+var (
+	apiKeyNameNotProvided  = &app_errors.AppError{Code: 400, Message: "api key name must be provided"}
+	apiKeyPermissionsEmpty = &app_errors.AppError{Code: 400, Message: "at least one permission must be provided"}
+	apiKeyExpiryNotValid   = &app_errors.AppError{Code: 400, Message: "expires-in-days must be between 1 and the allowed maximum"}
+	apiKeyPermissionDenied = &app_errors.AppError{Code: 403, Message: "api key can only be granted permissions held by the issuer"}
+	apiKeyNotFound         = &app_errors.AppError{Code: 404, Message: "api key not found"}
+)
+
+type ApiKeyService interface {
+	CreateApiKey(issuer *AuthContext, request *dto.ApiKeyCreateRequest) (*dto.ApiKeyCreateResponse, error)
+	GetAllApiKeys() ([]*responseDto.ApiKeyDetails, error)
+	RevokeApiKey(apiKeyId string) error
+}
+
+type ApiKeyServiceImplementation struct {
+	repo        repository.ApiKeyRepository
+	authService AuthService
+}
+
+// GetApiKeyService : Initialise api-key-service, uses dependency apiKeyRepository and authService
+func GetApiKeyService(apiKeyRepository repository.ApiKeyRepository, authService AuthService) ApiKeyService {
+	apiKeyService := &ApiKeyServiceImplementation{
+		repo:        apiKeyRepository,
+		authService: authService,
+	}
+	return apiKeyService
+}
+
+// CreateApiKey : issues an api key scoped to the permissions, the key is stored as a hash and only returned once
+func (a ApiKeyServiceImplementation) CreateApiKey(issuer *AuthContext,
+	request *dto.ApiKeyCreateRequest) (*dto.ApiKeyCreateResponse, error) {
+
+	if request.Name == "" {
+		return nil, apiKeyNameNotProvided
+	}
+
+	permissions := uniqueValues(request.Permissions)
+	if len(permissions) == 0 {
+		return nil, apiKeyPermissionsEmpty
+	}
+
+	if request.ExpiresInDays <= 0 || request.ExpiresInDays > MaxApiKeyExpiryInDays {
+		return nil, apiKeyExpiryNotValid
+	}
+
+	// api keys are issued by users, so a key can never hold more than the user issuing it
+	if issuer.Role == USER_TYPE_API_KEY {
+		log.Printf("api key %s can't issue api keys\n", issuer.UserId)
+		return nil, apiKeyPermissionDenied
+	}
+	// requests with the key are exempt from mfa, so only admins who logged in with mfa can issue keys
+	if !issuer.MfaVerified {
+		log.Printf("user %s can't issue api keys without mfa\n", issuer.UserId)
+		return nil, MfaRequired
+	}
+	for _, permission := range permissions {
+		if err := a.authService.Authorize(issuer, permission); err != nil {
+			log.Printf("user %s can't grant permission %s to api key\n", issuer.UserId, permission)
+			return nil, apiKeyPermissionDenied
+		}
+	}
+
+	apiKeyId := util.GenerateApiKeyID()
+	secret, err := util.GenerateRandomToken(32)
+	if err != nil {
+		log.Println("failed to generate api key, error ", err)
+		return nil, app_errors.InternalServerError
+	}
+	// api key is in format <key-id>.<random-secret>
+	key := apiKeyId + "." + secret
+
+	now := util.GetCurrentTimeInUtc()
+	apiKeyDetails := &responseDto.ApiKeyDetails{
+		ApiKeyId:         apiKeyId,
+		Name:             request.Name,
+		KeyHash:          util.HashToken(key),
+		Permissions:      permissions,
+		CreatedBy:        issuer.UserId,
+		ExpiresAt:        now.Add(time.Duration(request.ExpiresInDays) * 24 * time.Hour),
+		CreatedTimestamp: now,
+		UpdatedTimestamp: now,
+	}
+
+	err = a.repo.CreateApiKey(apiKeyDetails)
+	if err != nil {
+		log.Printf("failed to create api key %s, error %v\n", request.Name, err)
+		return nil, app_errors.InternalServerError
+	}
+
+	return &dto.ApiKeyCreateResponse{Key: key, ApiKey: apiKeyDetails}, nil
+}
+
+func (a ApiKeyServiceImplementation) GetAllApiKeys() ([]*responseDto.ApiKeyDetails, error) {
+	apiKeyDetailsList, err := a.repo.GetAllApiKeys()
+	if err != nil {
+		log.Printf("failed to get api keys, error %v\n", err)
+		return nil, app_errors.InternalServerError
+	}
+	return apiKeyDetailsList, nil
+}
+
+// RevokeApiKey : revokes the api key, requests with the key are rejected afterwards
+func (a ApiKeyServiceImplementation) RevokeApiKey(apiKeyId string) error {
+	_, err := a.repo.GetApiKeyById(apiKeyId)
+	if err != nil {
+		log.Printf("api key %s can not be fetched, error %v\n", apiKeyId, err)
+		return apiKeyNotFound
+	}
+
+	err = a.repo.RevokeApiKey(apiKeyId)
+	if err != nil {
+		log.Printf("failed to revoke api key %s, error %v\n", apiKeyId, err)
+		return app_errors.InternalServerError
+	}
+	return nil
+}
@@ -1,38 +1,120 @@
 package service
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/s8sg/mini-loan-app/app/app_errors"
+	"github.com/s8sg/mini-loan-app/app/dto"
+	repository "github.com/s8sg/mini-loan-app/app/repostory"
+	"github.com/s8sg/mini-loan-app/app/util"
 	"log"
+	"strings"
 	"time"
 )
 
 const (
 	USER_TYPE_CUSTOMER = "customer"
 	USER_TYPE_ADMIN    = "admin"
+	USER_TYPE_API_KEY  = "api-key"
+)
+
+// Permissions required by the routes, permissions are granted to roles in the role_permissions table
+const (
+	PERMISSION_LOAN_CREATE_OWN      = "loan:create:own"
+	PERMISSION_LOAN_CANCEL_OWN      = "loan:cancel:own"
+	PERMISSION_LOAN_READ_OWN        = "loan:read:own"
+	PERMISSION_REPAYMENT_CREATE_OWN = "repayment:create:own"
+	PERMISSION_PROFILE_READ_OWN     = "profile:read:own"
+	PERMISSION_PROFILE_UPDATE_OWN   = "profile:update:own"
+	PERMISSION_LOAN_APPROVE         = "loan:approve"
+	PERMISSION_LOAN_READ_ANY        = "loan:read:any"
+	PERMISSION_CUSTOMER_READ        = "customer:read"
+	PERMISSION_CUSTOMER_MANAGE      = "customer:manage"
+	PERMISSION_ROLE_MANAGE          = "role:manage"
+	PERMISSION_USER_MANAGE          = "user:manage"
+	PERMISSION_API_KEY_MANAGE       = "api-key:manage"
+	PERMISSION_CUSTOMER_IMPERSONATE = "customer:impersonate"
+	PERMISSION_LOAN_PRODUCT_MANAGE  = "loan-product:manage"
+)
+
+var (
+	// AccessTokenExpiry is the lifetime of the bearer token
+	AccessTokenExpiry = time.Minute * 30
+	// RefreshTokenExpiry is the lifetime of the session, extended on every refresh
+	RefreshTokenExpiry = (time.Hour * 24) * 7
+	// ImpersonationTokenExpiry is the lifetime of the read only token issued to view the app as a customer
+	ImpersonationTokenExpiry = time.Minute * 15
 )
 
 var (
-	InvalidToken = fmt.Errorf("token is not valid")
+	InvalidToken      = fmt.Errorf("token is not valid")
+	UserAlreadyExists = &app_errors.AppError{Code: 409, Message: "user already exists"}
+	// ImpersonationReadOnly is returned for mutating requests made with an impersonation token
+	ImpersonationReadOnly = &app_errors.AppError{Code: 403, Message: "impersonation is read only"}
 )
 
 var (
 	userIdMustBeProvided = &app_errors.AppError{Code: 400, Message: "username must be provided"}
+	secretMustBeProvided = &app_errors.AppError{Code: 400, Message: "secret must be provided"}
+	invalidCredentials   = &app_errors.AppError{Code: 401, Message: "invalid username or secret"}
+
+	refreshTokenMustBeProvided = &app_errors.AppError{Code: 400, Message: "refresh token must be provided"}
+	accessTokenMustBeProvided  = &app_errors.AppError{Code: 400, Message: "token must be provided"}
+	invalidRefreshToken        = &app_errors.AppError{Code: 401, Message: "refresh token is not valid"}
+
+	impersonationNotAllowed = &app_errors.AppError{Code: 401, Message: "only admins can impersonate"}
 )
 
+// AuthContext : identity of the caller, Role is the login type (customer, admin or api-key)
+// and Roles are the roles granted to the session, api keys are granted Permissions directly.
+// MfaVerified is set when the session was created with a second factor, ImpersonatorId is the admin
+// viewing the app as the customer (read only)
 type AuthContext struct {
-	UserId string
-	Role   string
+	UserId         string
+	Role           string
+	Roles          []string
+	Permissions    []string
+	SessionId      string
+	MfaVerified    bool
+	ImpersonatorId string
+}
+
+// AuthTokens : tokens of the session, only MfaToken is set when the login requires the second factor
+type AuthTokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
+	MfaToken     string
 }
 
 type AuthService interface {
-	Login(userid string, userType string, secret string) (string, error)
-	ValidateToken(token string, role string) (*AuthContext, error)
+	Login(userid string, userType string, secret string, clientIp string) (*AuthTokens, error)
+	LoginWithMfa(mfaToken string, code string, clientIp string) (*AuthTokens, error)
+	Refresh(refreshToken string) (*AuthTokens, error)
+	Logout(refreshToken string) error
+	RevokeToken(accessToken string) error
+	ValidateToken(token string) (*AuthContext, error)
+	ValidateApiKey(apiKey string) (*AuthContext, error)
+	Authorize(authContext *AuthContext, permission string) error
+	RegisterUser(userid string, secret string, roles []string) error
+	GetJWKS() *dto.JSONWebKeySet
+	UnlockLogin(userid string, clientIp string) error
+	Impersonate(impersonator *AuthContext, customerId string) (*AuthTokens, error)
 }
 
 type AuthServiceImplementation struct {
-	secretKey string
+	signingKeys *SigningKeys
+	userRepo    repository.UserRepository
+	sessionRepo repository.SessionRepository
+	roleRepo    repository.RoleRepository
+	apiKeyRepo  repository.ApiKeyRepository
+
+	loginAttemptRepo repository.LoginAttemptRepository
+	mfaRepo          repository.MfaRepository
+	mfaEncryptionKey string
 }
 
 type Claims struct {
@@ -41,35 +123,411 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
-func GetAuthService(secretKey string) AuthService {
+// GetAuthService : Initialise auth-service, uses dependency userRepository, sessionRepository, roleRepository,
+// apiKeyRepository, loginAttemptRepository and mfaRepository, mfaEncryptionKey decrypts the TOTP secrets
+func GetAuthService(signingKeys *SigningKeys, userRepository repository.UserRepository,
+	sessionRepository repository.SessionRepository, roleRepository repository.RoleRepository,
+	apiKeyRepository repository.ApiKeyRepository, loginAttemptRepository repository.LoginAttemptRepository,
+	mfaRepository repository.MfaRepository, mfaEncryptionKey string) AuthService {
 	return &AuthServiceImplementation{
-		secretKey: secretKey,
+		signingKeys:      signingKeys,
+		userRepo:         userRepository,
+		sessionRepo:      sessionRepository,
+		roleRepo:         roleRepository,
+		apiKeyRepo:       apiKeyRepository,
+		loginAttemptRepo: loginAttemptRepository,
+		mfaRepo:          mfaRepository,
+		mfaEncryptionKey: mfaEncryptionKey,
 	}
 }
 
-func (service *AuthServiceImplementation) Login(userid string, userType string, secret string) (string, error) {
+// Login : validates the credentials, failed attempts are throttled per username and per client ip
+func (service *AuthServiceImplementation) Login(userid string, userType string, secret string,
+	clientIp string) (*AuthTokens, error) {
 
 	// check if userID not provided
 	if userid == "" {
-		return "", userIdMustBeProvided
+		return nil, userIdMustBeProvided
+	}
+
+	// check if secret not provided
+	if secret == "" {
+		return nil, secretMustBeProvided
+	}
+
+	// reject while the username or the ip is locked or in backoff, even if the secret is valid
+	if err := service.checkLoginThrottle(usernameThrottlePolicy, userid); err != nil {
+		return nil, err
+	}
+	if err := service.checkLoginThrottle(ipThrottlePolicy, clientIp); err != nil {
+		return nil, err
+	}
+
+	userDetails, roles, err := service.verifyCredentials(userid, userType, secret)
+	if err != nil {
+		if err == invalidCredentials {
+			service.recordFailedLogin(usernameThrottlePolicy, userid)
+			service.recordFailedLogin(ipThrottlePolicy, clientIp)
+		}
+		return nil, err
+	}
+
+	// admins with MFA complete the login with LoginWithMfa, failed attempts are reset once the second factor is verified
+	if userType == USER_TYPE_ADMIN && userDetails.TotpEnabled {
+		return service.createMfaChallenge(userid)
+	}
+
+	service.resetFailedLogins(userid)
+
+	return service.createSession(userid, userType, roles, false)
+}
+
+// LoginWithMfa : second step of the admin login, validates the TOTP or recovery code for the mfa token
+// issued by Login, the session is marked as MFA verified
+func (service *AuthServiceImplementation) LoginWithMfa(mfaToken string, code string, clientIp string) (*AuthTokens, error) {
+	if mfaToken == "" {
+		return nil, mfaTokenNotProvided
+	}
+
+	if code == "" {
+		return nil, mfaCodeNotProvided
+	}
+
+	challengeDetails, err := service.getMfaChallenge(mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	userid := challengeDetails.Username
+
+	if err := service.checkLoginThrottle(usernameThrottlePolicy, userid); err != nil {
+		return nil, err
+	}
+	if err := service.checkLoginThrottle(ipThrottlePolicy, clientIp); err != nil {
+		return nil, err
+	}
+
+	userDetails, err := service.userRepo.GetUserByUsername(userid)
+	if err != nil {
+		log.Printf("failed to fetch user %s, error %v\n", userid, err)
+		return nil, invalidMfaToken
+	}
+	if !userDetails.TotpEnabled {
+		log.Printf("mfa of user %s was reset during the login\n", userid)
+		return nil, invalidMfaToken
+	}
+
+	err = verifySecondFactor(service.userRepo, service.mfaRepo, service.mfaEncryptionKey, userDetails, code)
+	if err != nil {
+		if err == invalidMfaCode {
+			if err := service.mfaRepo.IncrementMfaChallengeAttempts(challengeDetails.ChallengeId); err != nil {
+				log.Printf("failed to update attempts of mfa challenge %s, error %v\n", challengeDetails.ChallengeId, err)
+			}
+			service.recordFailedLogin(usernameThrottlePolicy, userid)
+			service.recordFailedLogin(ipThrottlePolicy, clientIp)
+		}
+		return nil, err
+	}
+
+	err = service.mfaRepo.UseMfaChallenge(challengeDetails.ChallengeId)
+	if err != nil {
+		log.Printf("mfa challenge %s was already used, error %v\n", challengeDetails.ChallengeId, err)
+		return nil, invalidMfaToken
+	}
+
+	roles := getRolesForUserType(userDetails, USER_TYPE_ADMIN)
+	if len(roles) == 0 {
+		log.Printf("login failed, user %s doesn't have any role for %s\n", userid, USER_TYPE_ADMIN)
+		return nil, invalidCredentials
+	}
+
+	service.resetFailedLogins(userid)
+
+	return service.createSession(userid, USER_TYPE_ADMIN, roles, true)
+}
+
+// verifyCredentials : validates the secret and responds with the roles the user can login with for the user type
+func (service *AuthServiceImplementation) verifyCredentials(userid string, userType string,
+	secret string) (*dto.UserDetails, []string, error) {
+	userDetails, err := service.userRepo.GetUserByUsername(userid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("login failed, user %s not found\n", userid)
+			// compare anyway so the response time doesn't reveal that the user doesn't exist
+			util.CompareDummySecret(secret)
+			return nil, nil, invalidCredentials
+		}
+		log.Printf("failed to fetch user %s, error %v\n", userid, err)
+		return nil, nil, app_errors.InternalServerError
+	}
+
+	// validate the secret against the stored hash
+	if !util.CompareSecret(userDetails.SecretHash, secret) {
+		log.Printf("login failed, invalid secret for user %s\n", userid)
+		return nil, nil, invalidCredentials
+	}
+
+	// validate the user is allowed to login with the role
+	roles := getRolesForUserType(userDetails, userType)
+	if len(roles) == 0 {
+		log.Printf("login failed, user %s doesn't have any role for %s\n", userid, userType)
+		return nil, nil, invalidCredentials
+	}
+	return userDetails, roles, nil
+}
+
+// createMfaChallenge : issues the short-lived mfa token for the second step of the login
+func (service *AuthServiceImplementation) createMfaChallenge(userid string) (*AuthTokens, error) {
+	challengeId := util.GenerateMfaChallengeID()
+
+	secret, err := util.GenerateRandomToken(32)
+	if err != nil {
+		log.Println("failed to generate mfa token, error ", err)
+		return nil, app_errors.InternalServerError
+	}
+	// mfa token is in format <challenge-id>.<random-secret>
+	mfaToken := challengeId + "." + secret
+
+	err = service.mfaRepo.CreateMfaChallenge(&dto.MfaChallengeDetails{
+		ChallengeId:   challengeId,
+		Username:      userid,
+		ChallengeHash: util.HashToken(mfaToken),
+		ExpiresAt:     util.GetCurrentTimeInUtc().Add(MfaChallengeExpiry),
+	})
+	if err != nil {
+		log.Printf("failed to create mfa challenge for user %s, error %v\n", userid, err)
+		return nil, app_errors.InternalServerError
+	}
+
+	return &AuthTokens{MfaToken: mfaToken}, nil
+}
+
+// getMfaChallenge : validates the mfa token against an active challenge
+func (service *AuthServiceImplementation) getMfaChallenge(mfaToken string) (*dto.MfaChallengeDetails, error) {
+	challengeId, _, found := strings.Cut(mfaToken, ".")
+	if !found {
+		log.Println("invalid mfa token format")
+		return nil, invalidMfaToken
+	}
+
+	challengeDetails, err := service.mfaRepo.GetMfaChallengeById(challengeId)
+	if err != nil {
+		log.Printf("failed to fetch mfa challenge %s, error %v\n", challengeId, err)
+		return nil, invalidMfaToken
+	}
+
+	if challengeDetails.ChallengeHash != util.HashToken(mfaToken) {
+		log.Printf("invalid secret for mfa challenge %s\n", challengeId)
+		return nil, invalidMfaToken
+	}
+
+	if challengeDetails.Used || challengeDetails.ExpiresAt.Before(util.GetCurrentTimeInUtc()) ||
+		challengeDetails.Attempts >= MfaChallengeMaxAttempts {
+		log.Printf("mfa challenge %s is used, expired or out of attempts\n", challengeId)
+		return nil, invalidMfaToken
+	}
+	return challengeDetails, nil
+}
+
+// Refresh : rotates the refresh token of the session and issues a new access token,
+// reuse of an already rotated refresh token revokes the whole session
+func (service *AuthServiceImplementation) Refresh(refreshToken string) (*AuthTokens, error) {
+	sessionDetails, err := service.getSessionForRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// impersonation sessions can't be extended
+	if sessionDetails.ImpersonatedBy != "" {
+		log.Printf("session %s is an impersonation, refresh is not allowed\n", sessionDetails.SessionId)
+		return nil, invalidRefreshToken
+	}
+
+	// roles are re-evaluated on refresh, so role changes apply to existing sessions
+	userDetails, err := service.userRepo.GetUserByUsername(sessionDetails.Username)
+	if err != nil {
+		log.Printf("failed to fetch user %s, error %v\n", sessionDetails.Username, err)
+		return nil, invalidRefreshToken
+	}
+	roles := getRolesForUserType(userDetails, sessionDetails.Role)
+	if len(roles) == 0 {
+		log.Printf("user %s doesn't have any role for %s anymore\n", sessionDetails.Username, sessionDetails.Role)
+		return nil, invalidRefreshToken
+	}
+
+	newRefreshToken, err := generateRefreshToken(sessionDetails.SessionId)
+	if err != nil {
+		log.Println("failed to generate refresh token, error ", err)
+		return nil, app_errors.InternalServerError
+	}
+
+	expiresAt := util.GetCurrentTimeInUtc().Add(RefreshTokenExpiry)
+	err = service.sessionRepo.RotateRefreshToken(sessionDetails.SessionId, sessionDetails.RefreshTokenHash,
+		util.HashToken(newRefreshToken), expiresAt)
+	if err != nil {
+		// the refresh token was rotated or revoked concurrently
+		log.Printf("failed to rotate refresh token for session %s, error %v\n", sessionDetails.SessionId, err)
+		return nil, invalidRefreshToken
+	}
+	sessionDetails.ExpiresAt = expiresAt
+
+	accessToken, err := service.signAccessToken(sessionDetails, roles)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+		ExpiresIn:    int64(AccessTokenExpiry.Seconds()),
+	}, nil
+}
+
+// Logout : revokes the session of the refresh token, access tokens issued for the session are rejected afterwards
+func (service *AuthServiceImplementation) Logout(refreshToken string) error {
+	sessionDetails, err := service.getSessionForRefreshToken(refreshToken)
+	if err != nil {
+		return err
+	}
+
+	err = service.sessionRepo.RevokeSession(sessionDetails.SessionId)
+	if err != nil {
+		log.Printf("failed to revoke session %s, error %v\n", sessionDetails.SessionId, err)
+		return app_errors.InternalServerError
+	}
+	return nil
+}
+
+// RevokeToken : revokes a single access token, the token is rejected afterwards while its session stays active
+func (service *AuthServiceImplementation) RevokeToken(accessToken string) error {
+	if accessToken == "" {
+		log.Println("access token not provided")
+		return accessTokenMustBeProvided
+	}
+
+	parsedToken, err := jwt.Parse(accessToken, service.signingKeys.keyFunc)
+	if err != nil {
+		log.Printf("token parse failed %v\n", err)
+		return app_errors.Unauthorised
+	}
+	claims, ok := parsedToken.Claims.(jwt.MapClaims)
+	if !ok || !parsedToken.Valid || claims["jti"] == nil {
+		log.Println("token parse failed: jti is empty")
+		return app_errors.Unauthorised
+	}
+
+	// the token is denied until it expires, tokens without an expiry are denied for the lifetime of the session
+	expiresAt := util.GetCurrentTimeInUtc().Add(RefreshTokenExpiry)
+	if exp, ok := claims["exp"].(float64); ok {
+		expiresAt = time.Unix(int64(exp), 0).UTC()
+	}
+
+	jti := fmt.Sprint(claims["jti"])
+	err = service.sessionRepo.RevokeToken(jti, expiresAt)
+	if err != nil {
+		log.Printf("failed to revoke token %s, error %v\n", jti, err)
+		return app_errors.InternalServerError
+	}
+	return nil
+}
+
+// createSession : creates a server side session and issues the access and refresh token for it
+func (service *AuthServiceImplementation) createSession(userid string, role string, roles []string,
+	mfaVerified bool) (*AuthTokens, error) {
+	sessionId := util.GenerateSessionID()
+
+	refreshToken, err := generateRefreshToken(sessionId)
+	if err != nil {
+		log.Println("failed to generate refresh token, error ", err)
+		return nil, app_errors.InternalServerError
+	}
+
+	sessionDetails := &dto.SessionDetails{
+		SessionId:        sessionId,
+		Username:         userid,
+		Role:             role,
+		RefreshTokenHash: util.HashToken(refreshToken),
+		ExpiresAt:        util.GetCurrentTimeInUtc().Add(RefreshTokenExpiry),
+		MfaVerified:      mfaVerified,
+	}
+	err = service.sessionRepo.CreateSession(sessionDetails)
+	if err != nil {
+		log.Printf("failed to create session for user %s, error %v\n", userid, err)
+		return nil, app_errors.InternalServerError
+	}
+
+	accessToken, err := service.signAccessToken(sessionDetails, roles)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(AccessTokenExpiry.Seconds()),
+	}, nil
+}
+
+// getSessionForRefreshToken : validates the refresh token against an active session
+func (service *AuthServiceImplementation) getSessionForRefreshToken(refreshToken string) (*dto.SessionDetails, error) {
+	if refreshToken == "" {
+		return nil, refreshTokenMustBeProvided
+	}
+
+	// refresh token is in format <session-id>.<random-secret>
+	sessionId, _, found := strings.Cut(refreshToken, ".")
+	if !found {
+		log.Println("invalid refresh token format")
+		return nil, invalidRefreshToken
 	}
 
-	// TODO: Login doesn't do any validation now,
-	// it can be enhanced to add validation of the secret passed
+	sessionDetails, err := service.sessionRepo.GetSessionById(sessionId)
+	if err != nil {
+		log.Printf("failed to fetch session %s, error %v\n", sessionId, err)
+		return nil, invalidRefreshToken
+	}
 
-	// Declare the token with the algorithm used for signing
-	// TODO: We are using HMAC secure, this can be changed to RSA
-	token := jwt.New(jwt.SigningMethodHS256)
+	if sessionDetails.Revoked || sessionDetails.ExpiresAt.Before(util.GetCurrentTimeInUtc()) {
+		log.Printf("session %s is revoked or expired\n", sessionId)
+		return nil, invalidRefreshToken
+	}
+
+	if sessionDetails.RefreshTokenHash != util.HashToken(refreshToken) {
+		// an already rotated refresh token is being reused, the token might be stolen
+		log.Printf("refresh token reuse detected for session %s, revoking session\n", sessionId)
+		if err := service.sessionRepo.RevokeSession(sessionId); err != nil {
+			log.Printf("failed to revoke session %s, error %v\n", sessionId, err)
+		}
+		return nil, invalidRefreshToken
+	}
+
+	return sessionDetails, nil
+}
+
+// signAccessToken : signs the bearer token of the session, the token doesn't outlive the session
+func (service *AuthServiceImplementation) signAccessToken(sessionDetails *dto.SessionDetails,
+	roles []string) (string, error) {
+	expiresAt := time.Now().Add(AccessTokenExpiry)
+	if sessionDetails.ExpiresAt.Before(expiresAt) {
+		expiresAt = sessionDetails.ExpiresAt
+	}
 
 	// Use jwt.MapClaims
-	claims := token.Claims.(jwt.MapClaims)
+	claims := jwt.MapClaims{}
 	claims["authorized"] = true
-	claims["id"] = userid
-	claims["role"] = userType
-	claims["exp"] = time.Now().Add(time.Minute * 30).Unix()
+	claims["jti"] = util.GenerateTokenID()
+	claims["sid"] = sessionDetails.SessionId
+	claims["id"] = sessionDetails.Username
+	claims["role"] = sessionDetails.Role
+	claims["roles"] = roles
+	claims["mfa"] = sessionDetails.MfaVerified
+	claims["exp"] = expiresAt.Unix()
+	if sessionDetails.ImpersonatedBy != "" {
+		// actor claim (RFC 8693), the admin acting as the customer
+		claims["act"] = map[string]string{"sub": sessionDetails.ImpersonatedBy}
+	}
 
-	// Create the JWT string
-	tokenString, err := token.SignedString([]byte(service.secretKey))
+	// Create the JWT string, signed with the configured algorithm
+	tokenString, err := service.signingKeys.sign(claims)
 	if err != nil {
 		log.Println("failed to generate token string, error ", err)
 		return "", app_errors.InternalServerError
@@ -78,14 +536,86 @@ func (service *AuthServiceImplementation) Login(userid string, userType string,
 	return tokenString, nil
 }
 
-func (service *AuthServiceImplementation) ValidateToken(token string, role string) (*AuthContext, error) {
+// getRolesForUserType : customers login with the customer role, admins login with all their staff roles
+func getRolesForUserType(userDetails *dto.UserDetails, userType string) []string {
+	roles := make([]string, 0)
+	for _, role := range userDetails.Roles {
+		isCustomerRole := role == USER_TYPE_CUSTOMER
+		if (userType == USER_TYPE_CUSTOMER && isCustomerRole) || (userType == USER_TYPE_ADMIN && !isCustomerRole) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+func generateRefreshToken(sessionId string) (string, error) {
+	secret, err := util.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	return sessionId + "." + secret, nil
+}
 
-	parsedToken, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("there was an error in parsing")
+// RegisterUser : creates a user with the allowed roles, the secret is stored as a salted hash
+func (service *AuthServiceImplementation) RegisterUser(userid string, secret string, roles []string) error {
+
+	if userid == "" {
+		return userIdMustBeProvided
+	}
+
+	if secret == "" {
+		return secretMustBeProvided
+	}
+
+	secretHash, err := util.HashSecret(secret)
+	if err != nil {
+		log.Println("failed to hash secret, error ", err)
+		return app_errors.InternalServerError
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	tx, err := service.userRepo.CreateTransaction(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		log.Println("failed to initiate transaction")
+		return app_errors.InternalServerError
+	}
+
+	defer func() {
+		if err != nil {
+			log.Println("calling rollback for error " + err.Error())
+			_ = tx.Rollback()
+			return
 		}
-		return []byte(service.secretKey), nil
-	})
+		_ = tx.Commit()
+	}()
+
+	err = service.userRepo.CreateUser(&dto.UserDetails{
+		Username:   userid,
+		SecretHash: secretHash,
+		Roles:      roles,
+	}, tx)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return UserAlreadyExists
+		}
+		log.Printf("failed to create user %s, error %v\n", userid, err)
+		return app_errors.InternalServerError
+	}
+
+	return nil
+}
+
+// GetJWKS : public keys to verify the bearer tokens, other services can verify tokens without the signing secret
+func (service *AuthServiceImplementation) GetJWKS() *dto.JSONWebKeySet {
+	return service.signingKeys.jwks()
+}
+
+// ValidateToken : authenticates the bearer token, use Authorize to check the permissions
+func (service *AuthServiceImplementation) ValidateToken(token string) (*AuthContext, error) {
+
+	parsedToken, err := jwt.Parse(token, service.signingKeys.keyFunc)
 
 	if err != nil {
 		log.Printf("token parse failed %v\n", err)
@@ -101,15 +631,148 @@ func (service *AuthServiceImplementation) ValidateToken(token string, role strin
 			log.Printf("token parse failed: id is empty")
 			return nil, app_errors.Unauthorised
 		}
+		if claims["sid"] == nil {
+			log.Printf("token parse failed: sid is empty")
+			return nil, app_errors.Unauthorised
+		}
+		if claims["jti"] == nil {
+			log.Printf("token parse failed: jti is empty")
+			return nil, app_errors.Unauthorised
+		}
 
-		// validate role
-		if claims["role"] != role {
-			log.Printf("User role doesn't match, provided %v, required %v\n", claims["role"], role)
+		roles := make([]string, 0)
+		if roleClaims, ok := claims["roles"].([]interface{}); ok {
+			for _, roleClaim := range roleClaims {
+				roles = append(roles, fmt.Sprint(roleClaim))
+			}
+		}
+
+		// validate the session is still active
+		sessionId := fmt.Sprint(claims["sid"])
+		sessionDetails, err := service.sessionRepo.GetSessionById(sessionId)
+		if err != nil {
+			log.Printf("failed to fetch session %s, error %v\n", sessionId, err)
+			return nil, app_errors.Unauthorised
+		}
+		if sessionDetails.Revoked {
+			log.Printf("token %v belongs to revoked session %s\n", claims["jti"], sessionId)
 			return nil, app_errors.Unauthorised
 		}
 
-		return &AuthContext{UserId: fmt.Sprint(claims["id"]), Role: role}, nil
+		// validate the token itself is not revoked
+		jti := fmt.Sprint(claims["jti"])
+		revoked, err := service.sessionRepo.IsTokenRevoked(jti)
+		if err != nil {
+			log.Printf("failed to check token %s, error %v\n", jti, err)
+			return nil, app_errors.Unauthorised
+		}
+		if revoked {
+			log.Printf("token %s is revoked\n", jti)
+			return nil, app_errors.Unauthorised
+		}
+
+		mfaVerified, _ := claims["mfa"].(bool)
+
+		// the impersonator is taken from the session, so it can't be dropped from the token
+		if actorClaim, ok := claims["act"].(map[string]interface{}); ok && actorClaim["sub"] != sessionDetails.ImpersonatedBy {
+			log.Printf("token %v actor doesn't match the session %s\n", claims["jti"], sessionId)
+			return nil, app_errors.Unauthorised
+		}
+
+		return &AuthContext{UserId: fmt.Sprint(claims["id"]), Role: fmt.Sprint(claims["role"]), Roles: roles,
+			SessionId: sessionId, MfaVerified: mfaVerified, ImpersonatorId: sessionDetails.ImpersonatedBy}, nil
 	}
 
 	return nil, InvalidToken
 }
+
+// ValidateApiKey : authenticates the api key of a machine client, the key is in format <key-id>.<random-secret>
+func (service *AuthServiceImplementation) ValidateApiKey(apiKey string) (*AuthContext, error) {
+	apiKeyId, _, found := strings.Cut(apiKey, ".")
+	if !found {
+		log.Println("invalid api key format")
+		return nil, app_errors.Unauthorised
+	}
+
+	apiKeyDetails, err := service.apiKeyRepo.GetApiKeyById(apiKeyId)
+	if err != nil {
+		log.Printf("failed to fetch api key %s, error %v\n", apiKeyId, err)
+		return nil, app_errors.Unauthorised
+	}
+
+	if apiKeyDetails.KeyHash != util.HashToken(apiKey) {
+		log.Printf("invalid secret for api key %s\n", apiKeyId)
+		return nil, app_errors.Unauthorised
+	}
+
+	if apiKeyDetails.Revoked || apiKeyDetails.ExpiresAt.Before(util.GetCurrentTimeInUtc()) {
+		log.Printf("api key %s is revoked or expired\n", apiKeyId)
+		return nil, app_errors.Unauthorised
+	}
+
+	permissions, err := service.getIssuerPermissions(apiKeyDetails)
+	if err != nil {
+		return nil, err
+	}
+
+	// failing to track the usage doesn't fail the request
+	if err := service.apiKeyRepo.UpdateLastUsed(apiKeyId, util.GetCurrentTimeInUtc()); err != nil {
+		log.Printf("failed to update last used of api key %s, error %v\n", apiKeyId, err)
+	}
+
+	return &AuthContext{UserId: apiKeyDetails.ApiKeyId, Role: USER_TYPE_API_KEY, Permissions: permissions}, nil
+}
+
+// getIssuerPermissions : permissions of the api key still held by the user who issued it, the key loses the
+// permissions its issuer lost and keys of deleted issuers are rejected
+func (service *AuthServiceImplementation) getIssuerPermissions(apiKeyDetails *dto.ApiKeyDetails) ([]string, error) {
+	issuer, err := service.userRepo.GetUserByUsername(apiKeyDetails.CreatedBy)
+	if err != nil {
+		log.Printf("failed to fetch issuer %s of api key %s, error %v\n", apiKeyDetails.CreatedBy,
+			apiKeyDetails.ApiKeyId, err)
+		return nil, app_errors.Unauthorised
+	}
+
+	issuerRoles := getRolesForUserType(issuer, USER_TYPE_ADMIN)
+	permissions := make([]string, 0, len(apiKeyDetails.Permissions))
+	for _, permission := range apiKeyDetails.Permissions {
+		allowed, err := service.roleRepo.HasPermission(issuerRoles, permission)
+		if err != nil {
+			log.Printf("failed to check permission %s, error %v\n", permission, err)
+			return nil, app_errors.InternalServerError
+		}
+		if !allowed {
+			log.Printf("issuer %s of api key %s no longer has permission %s\n", issuer.Username,
+				apiKeyDetails.ApiKeyId, permission)
+			continue
+		}
+		permissions = append(permissions, permission)
+	}
+	return permissions, nil
+}
+
+// Authorize : checks if any of the roles of the caller is granted the permission,
+// api keys are only allowed the permissions they were issued with
+func (service *AuthServiceImplementation) Authorize(authContext *AuthContext, permission string) error {
+	if authContext.Role == USER_TYPE_API_KEY {
+		for _, granted := range authContext.Permissions {
+			if granted == permission {
+				return nil
+			}
+		}
+		log.Printf("api key %s doesn't have permission %s\n", authContext.UserId, permission)
+		return app_errors.Unauthorised
+	}
+
+	allowed, err := service.roleRepo.HasPermission(authContext.Roles, permission)
+	if err != nil {
+		log.Printf("failed to check permission %s, error %v\n", permission, err)
+		return app_errors.InternalServerError
+	}
+
+	if !allowed {
+		log.Printf("User %s with roles %v doesn't have permission %s\n", authContext.UserId, authContext.Roles, permission)
+		return app_errors.Unauthorised
+	}
+	return nil
+}
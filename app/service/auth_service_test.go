@@ -0,0 +1,104 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	responseDto "github.com/s8sg/mini-loan-app/app/dto"
+	repository "github.com/s8sg/mini-loan-app/app/repostory"
+	"github.com/s8sg/mini-loan-app/app/util"
+)
+
+// stubApiKeyRepository serves the api keys by id
+type stubApiKeyRepository struct {
+	repository.ApiKeyRepository
+	apiKeys map[string]*responseDto.ApiKeyDetails
+}
+
+func (s *stubApiKeyRepository) GetApiKeyById(apiKeyId string) (*responseDto.ApiKeyDetails, error) {
+	apiKeyDetails, ok := s.apiKeys[apiKeyId]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return apiKeyDetails, nil
+}
+
+func (s *stubApiKeyRepository) UpdateLastUsed(apiKeyId string, lastUsedAt time.Time) error {
+	return nil
+}
+
+// stubRoleRepository grants the permissions of the roles
+type stubRoleRepository struct {
+	repository.RoleRepository
+	permissions map[string][]string
+}
+
+func (s *stubRoleRepository) HasPermission(roles []string, permission string) (bool, error) {
+	for _, role := range roles {
+		for _, granted := range s.permissions[role] {
+			if granted == permission {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func TestValidateApiKey_IssuerPermissions(t *testing.T) {
+	users := map[string]*responseDto.UserDetails{
+		"admin1":   {Username: "admin1", Roles: []string{"admin"}},
+		"officer1": {Username: "officer1", Roles: []string{"support"}},
+	}
+	authService := &AuthServiceImplementation{
+		userRepo: &stubUserRepository{users: users},
+		roleRepo: &stubRoleRepository{permissions: map[string][]string{
+			"admin":   {PERMISSION_LOAN_APPROVE, PERMISSION_LOAN_READ_ANY},
+			"support": {PERMISSION_LOAN_READ_ANY},
+		}},
+		apiKeyRepo: &stubApiKeyRepository{apiKeys: map[string]*responseDto.ApiKeyDetails{}},
+	}
+	newApiKey := func(apiKeyId string, issuer string) string {
+		key := apiKeyId + ".secret"
+		authService.apiKeyRepo.(*stubApiKeyRepository).apiKeys[apiKeyId] = &responseDto.ApiKeyDetails{
+			ApiKeyId:    apiKeyId,
+			KeyHash:     util.HashToken(key),
+			Permissions: []string{PERMISSION_LOAN_APPROVE, PERMISSION_LOAN_READ_ANY},
+			CreatedBy:   issuer,
+			ExpiresAt:   util.GetCurrentTimeInUtc().Add(time.Hour),
+		}
+		return key
+	}
+
+	tests := []struct {
+		name        string
+		key         string
+		permissions []string
+		valid       bool
+	}{
+		{"issuer holds the permissions", newApiKey("key1", "admin1"), []string{PERMISSION_LOAN_APPROVE,
+			PERMISSION_LOAN_READ_ANY}, true},
+		// the issuer was moved to a role without loan:approve after issuing the key
+		{"issuer lost a permission", newApiKey("key2", "officer1"), []string{PERMISSION_LOAN_READ_ANY}, true},
+		{"issuer deleted", newApiKey("key3", "removed1"), nil, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			authContext, err := authService.ValidateApiKey(test.key)
+			if (err == nil) != test.valid {
+				t.Fatalf("expected valid %v but got %v", test.valid, err)
+			}
+			if !test.valid {
+				return
+			}
+			if len(authContext.Permissions) != len(test.permissions) {
+				t.Fatalf("expected permissions %v but got %v", test.permissions, authContext.Permissions)
+			}
+			for i, permission := range test.permissions {
+				if authContext.Permissions[i] != permission {
+					t.Errorf("expected permissions %v but got %v", test.permissions, authContext.Permissions)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/s8sg/mini-loan-app/app/app_errors"
+	"github.com/s8sg/mini-loan-app/app/controller/dto"
+	responseDto "github.com/s8sg/mini-loan-app/app/dto"
+	repository "github.com/s8sg/mini-loan-app/app/repostory"
+	"github.com/s8sg/mini-loan-app/app/util"
+	"log"
+	"net/mail"
+	"time"
+)
+
+var (
+	customerNotFound      = &app_errors.AppError{Code: 404, Message: "customer not found"}
+	customerDisabled      = &app_errors.AppError{Code: 403, Message: "customer is disabled"}
+	customerHasLoans      = &app_errors.AppError{Code: 409, Message: "customer with loans can not be deleted"}
+	customerIdNotProvided = &app_errors.AppError{Code: 400, Message: "customer id must be provided"}
+	customerNameNotValid  = &app_errors.AppError{Code: 400, Message: "name must be provided"}
+	customerEmailNotValid = &app_errors.AppError{Code: 400, Message: "a valid email must be provided"}
+)
+
+type CustomerService interface {
+	SignUp(request *dto.CustomerSignupRequest) (*responseDto.CustomerDetails, error)
+	GetCustomer(customerId string) (*responseDto.CustomerDetails, error)
+	GetAllCustomers() ([]*responseDto.CustomerDetails, error)
+	UpdateCustomer(customerId string, request *dto.CustomerUpdateRequest) (*responseDto.CustomerDetails, error)
+	DisableCustomer(customerId string) error
+	EnableCustomer(customerId string) error
+	DeleteCustomer(customerId string) error
+}
+
+type CustomerServiceImplementation struct {
+	repo     repository.CustomerRepository
+	userRepo repository.UserRepository
+}
+
+// GetCustomerService : Initialise customer-service, uses dependency customerRepository and userRepository
+func GetCustomerService(customerRepository repository.CustomerRepository,
+	userRepository repository.UserRepository) CustomerService {
+	customerService := &CustomerServiceImplementation{
+		repo:     customerRepository,
+		userRepo: userRepository,
+	}
+	return customerService
+}
+
+// SignUp : creates the customer login and profile within the same transaction
+func (c CustomerServiceImplementation) SignUp(request *dto.CustomerSignupRequest) (*responseDto.CustomerDetails, error) {
+
+	if request.Username == "" {
+		return nil, userIdMustBeProvided
+	}
+
+	if request.Secret == "" {
+		return nil, secretMustBeProvided
+	}
+
+	err := validateCustomerProfile(request.Name, request.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	secretHash, err := util.HashSecret(request.Secret)
+	if err != nil {
+		log.Println("failed to hash secret, error ", err)
+		return nil, app_errors.InternalServerError
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	tx, err := c.repo.CreateTransaction(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		log.Println("failed to initiate transaction")
+		return nil, app_errors.InternalServerError
+	}
+
+	defer func() {
+		if err != nil {
+			log.Println("calling rollback for error " + err.Error())
+			_ = tx.Rollback()
+			return
+		}
+		_ = tx.Commit()
+	}()
+
+	err = c.userRepo.CreateUser(&responseDto.UserDetails{
+		Username:   request.Username,
+		SecretHash: secretHash,
+		Roles:      []string{USER_TYPE_CUSTOMER},
+	}, tx)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, UserAlreadyExists
+		}
+		log.Printf("failed to create user %s, error %v\n", request.Username, err)
+		return nil, app_errors.InternalServerError
+	}
+
+	customerDetails := &responseDto.CustomerDetails{
+		CustomerId:       request.Username,
+		Name:             request.Name,
+		Email:            request.Email,
+		Phone:            request.Phone,
+		Status:           responseDto.CustomerStatusActive,
+		CreatedTimestamp: util.GetCurrentTimeInUtc(),
+		UpdatedTimestamp: util.GetCurrentTimeInUtc(),
+	}
+
+	err = c.repo.CreateCustomer(customerDetails, tx)
+	if err != nil {
+		log.Printf("failed to create customer %s, error %v\n", request.Username, err)
+		return nil, app_errors.InternalServerError
+	}
+
+	return customerDetails, nil
+}
+
+func (c CustomerServiceImplementation) GetCustomer(customerId string) (*responseDto.CustomerDetails, error) {
+	if customerId == "" {
+		return nil, customerIdNotProvided
+	}
+
+	customerDetails, err := c.repo.GetCustomerById(customerId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("customer %s not found\n", customerId)
+			return nil, customerNotFound
+		}
+		log.Printf("failed to get customer %s, error %v\n", customerId, err)
+		return nil, app_errors.InternalServerError
+	}
+	return customerDetails, nil
+}
+
+func (c CustomerServiceImplementation) GetAllCustomers() ([]*responseDto.CustomerDetails, error) {
+	customerDetailsList, err := c.repo.GetAllCustomers()
+	if err != nil {
+		log.Printf("failed to get customers, error %v\n", err)
+		return nil, app_errors.InternalServerError
+	}
+	return customerDetailsList, nil
+}
+
+func (c CustomerServiceImplementation) UpdateCustomer(customerId string,
+	request *dto.CustomerUpdateRequest) (*responseDto.CustomerDetails, error) {
+
+	err := validateCustomerProfile(request.Name, request.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	customerDetails, err := c.GetCustomer(customerId)
+	if err != nil {
+		return nil, err
+	}
+
+	customerDetails.Name = request.Name
+	customerDetails.Email = request.Email
+	customerDetails.Phone = request.Phone
+	customerDetails.UpdatedTimestamp = util.GetCurrentTimeInUtc()
+
+	err = c.repo.UpdateCustomerProfile(customerDetails)
+	if err != nil {
+		log.Printf("failed to update customer %s, error %v\n", customerId, err)
+		return nil, app_errors.InternalServerError
+	}
+	return customerDetails, nil
+}
+
+func (c CustomerServiceImplementation) DisableCustomer(customerId string) error {
+	return c.updateCustomerStatus(customerId, responseDto.CustomerStatusDisabled)
+}
+
+func (c CustomerServiceImplementation) EnableCustomer(customerId string) error {
+	return c.updateCustomerStatus(customerId, responseDto.CustomerStatusActive)
+}
+
+// DeleteCustomer : deletes the customer profile along with the login,
+// customers who already have loans can only be disabled
+func (c CustomerServiceImplementation) DeleteCustomer(customerId string) error {
+	_, err := c.GetCustomer(customerId)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	tx, err := c.repo.CreateTransaction(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		log.Println("failed to initiate transaction")
+		return app_errors.InternalServerError
+	}
+
+	defer func() {
+		if err != nil {
+			log.Println("calling rollback for error " + err.Error())
+			_ = tx.Rollback()
+			return
+		}
+		_ = tx.Commit()
+	}()
+
+	err = c.repo.DeleteCustomer(customerId, tx)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			log.Printf("customer %s has loans, can not be deleted\n", customerId)
+			return customerHasLoans
+		}
+		log.Printf("failed to delete customer %s, error %v\n", customerId, err)
+		return app_errors.InternalServerError
+	}
+
+	err = c.userRepo.DeleteUser(customerId, tx)
+	if err != nil {
+		log.Printf("failed to delete user %s, error %v\n", customerId, err)
+		return app_errors.InternalServerError
+	}
+
+	return nil
+}
+
+func (c CustomerServiceImplementation) updateCustomerStatus(customerId string, status string) error {
+	_, err := c.GetCustomer(customerId)
+	if err != nil {
+		return err
+	}
+
+	err = c.repo.UpdateCustomerStatus(customerId, status)
+	if err != nil {
+		log.Printf("failed to update status of customer %s to %s, error %v\n", customerId, status, err)
+		return app_errors.InternalServerError
+	}
+	return nil
+}
+
+func validateCustomerProfile(name string, email string) error {
+	if name == "" {
+		log.Println("customer name must be provided")
+		return customerNameNotValid
+	}
+
+	if _, err := mail.ParseAddress(email); err != nil {
+		log.Printf("customer email %s is not valid\n", email)
+		return customerEmailNotValid
+	}
+	return nil
+}
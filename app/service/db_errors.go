@@ -0,0 +1,25 @@
+package service
+
+import (
+	"errors"
+	"github.com/lib/pq"
+)
+
+// isUniqueViolation : checks if the db error is caused by a duplicate key
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// isSerializationFailure : checks if the db error is caused by a concurrent update of the row, the transaction can
+// be retried
+func isSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "40001"
+}
+
+// isForeignKeyViolation : checks if the db error is caused by a row still being referenced
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
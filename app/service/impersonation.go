@@ -0,0 +1,69 @@
+package service
+
+import (
+	"github.com/s8sg/mini-loan-app/app/app_errors"
+	"github.com/s8sg/mini-loan-app/app/dto"
+	"github.com/s8sg/mini-loan-app/app/util"
+	"log"
+)
+
+// Impersonate : issues a short-lived read only customer token for an admin to view the app as the customer,
+// the admin is recorded on the session and in the act claim of the token. No refresh token is issued
+func (service *AuthServiceImplementation) Impersonate(impersonator *AuthContext, customerId string) (*AuthTokens, error) {
+	if customerId == "" {
+		return nil, userIdMustBeProvided
+	}
+
+	// only admin logins, api keys and impersonation tokens can't impersonate
+	if impersonator.Role != USER_TYPE_ADMIN || impersonator.ImpersonatorId != "" {
+		log.Printf("%s %s is not allowed to impersonate\n", impersonator.Role, impersonator.UserId)
+		return nil, impersonationNotAllowed
+	}
+
+	userDetails, err := service.userRepo.GetUserByUsername(customerId)
+	if err != nil {
+		log.Printf("failed to fetch user %s, error %v\n", customerId, err)
+		return nil, customerNotFound
+	}
+	roles := getRolesForUserType(userDetails, USER_TYPE_CUSTOMER)
+	if len(roles) == 0 {
+		log.Printf("user %s is not a customer\n", customerId)
+		return nil, customerNotFound
+	}
+
+	sessionId := util.GenerateSessionID()
+
+	// the refresh token is never handed out, it only fills the session
+	refreshToken, err := generateRefreshToken(sessionId)
+	if err != nil {
+		log.Println("failed to generate refresh token, error ", err)
+		return nil, app_errors.InternalServerError
+	}
+
+	sessionDetails := &dto.SessionDetails{
+		SessionId:        sessionId,
+		Username:         customerId,
+		Role:             USER_TYPE_CUSTOMER,
+		RefreshTokenHash: util.HashToken(refreshToken),
+		ExpiresAt:        util.GetCurrentTimeInUtc().Add(ImpersonationTokenExpiry),
+		ImpersonatedBy:   impersonator.UserId,
+	}
+	err = service.sessionRepo.CreateSession(sessionDetails)
+	if err != nil {
+		log.Printf("failed to create impersonation session for user %s, error %v\n", customerId, err)
+		return nil, app_errors.InternalServerError
+	}
+
+	accessToken, err := service.signAccessToken(sessionDetails, roles)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("AUDIT: admin %s impersonates customer %s, session %s\n", impersonator.UserId, customerId,
+		sessionDetails.SessionId)
+
+	return &AuthTokens{
+		AccessToken: accessToken,
+		ExpiresIn:   int64(ImpersonationTokenExpiry.Seconds()),
+	}, nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/s8sg/mini-loan-app/app/app_errors"
+	responseDto "github.com/s8sg/mini-loan-app/app/dto"
+	repository "github.com/s8sg/mini-loan-app/app/repostory"
+	"github.com/s8sg/mini-loan-app/app/util"
+	"github.com/shopspring/decimal"
+	"log"
+	"strings"
+)
+
+var (
+	// LoanApprovalThreshold is the loan amount above which LoanApprovalQuorum distinct admins must approve the loan
+	LoanApprovalThreshold = decimal.NewFromInt(1000000)
+	// LoanApprovalQuorum is the number of distinct admins approving a loan above the LoanApprovalThreshold
+	LoanApprovalQuorum = 2
+	// RoleApprovalLimits are the approval limits of the staff without a user (identity provider staff and api keys)
+	// by role, they can't approve loans when none of their roles has a limit
+	RoleApprovalLimits = map[string]decimal.Decimal{}
+)
+
+var (
+	loanApproverIsBorrower    = &app_errors.AppError{Code: 403, Message: "loan can't be approved by its borrower"}
+	loanAlreadyApprovedByUser = &app_errors.AppError{Code: 409, Message: "loan is already approved by the user"}
+	loanApprovalRequiresAdmin = &app_errors.AppError{Code: 403,
+		Message: "loans above the approval threshold must be approved by admins"}
+	loanAboveApprovalLimit = &app_errors.AppError{Code: 403,
+		Message: "loan amount exceeds the approval limit of the user"}
+	counterOfferAboveThreshold = &app_errors.AppError{Code: 400,
+		Message: "loans above the approval threshold can't be counter-offered"}
+	loanUpdatedConcurrently = &app_errors.AppError{Code: 409,
+		Message: "loan is updated concurrently, retry the request"}
+)
+
+// getRequiredApprovals : loans above the approval threshold require the approval of the quorum
+func getRequiredApprovals(amount decimal.Decimal) int {
+	if amount.GreaterThan(LoanApprovalThreshold) {
+		return LoanApprovalQuorum
+	}
+	return 1
+}
+
+// checkApprover : the borrower can never approve or counter-offer the loan
+func checkApprover(loanDetails *responseDto.LoanDetails, actor *loanActor) error {
+	if actor.id == loanDetails.CustomerId {
+		log.Printf("loan %s can't be approved by its borrower %s\n", loanDetails.LoanId, actor.id)
+		return loanApproverIsBorrower
+	}
+	return nil
+}
+
+// checkApprovalLimit : admins can only approve loans up to their approval limit. Staff of the external identity
+// provider have no user and api keys are not users, they are limited by the approval limits of their roles
+func (l LoanServiceImplementation) checkApprovalLimit(actor *loanActor, amount decimal.Decimal) error {
+	if actor.actorType == USER_TYPE_API_KEY {
+		return checkRoleApprovalLimit(actor, amount)
+	}
+	if actor.actorType != USER_TYPE_ADMIN {
+		return nil
+	}
+	userDetails, err := l.userRepo.GetUserByUsername(actor.id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return checkRoleApprovalLimit(actor, amount)
+		}
+		log.Printf("failed to fetch user %s, error %v\n", actor.id, err)
+		return app_errors.InternalServerError
+	}
+	if isAboveApprovalLimit(userDetails, amount) {
+		log.Printf("loan amount %v exceeds the approval limit %v of %s\n", amount, userDetails.ApprovalLimit, actor.id)
+		return loanAboveApprovalLimit
+	}
+	return nil
+}
+
+func isAboveApprovalLimit(userDetails *responseDto.UserDetails, amount decimal.Decimal) bool {
+	return userDetails.ApprovalLimit != nil && amount.GreaterThan(*userDetails.ApprovalLimit)
+}
+
+// checkRoleApprovalLimit : the actor can approve loans up to the highest approval limit of its roles, actors
+// without a configured limit can't approve loans
+func checkRoleApprovalLimit(actor *loanActor, amount decimal.Decimal) error {
+	var approvalLimit *decimal.Decimal
+	for _, role := range actor.roles {
+		limit, ok := RoleApprovalLimits[role]
+		if ok && (approvalLimit == nil || limit.GreaterThan(*approvalLimit)) {
+			approvalLimit = &limit
+		}
+	}
+	if approvalLimit == nil {
+		log.Printf("no approval limit is configured for the roles %v of %s\n", actor.roles, actor.id)
+		return loanAboveApprovalLimit
+	}
+	if amount.GreaterThan(*approvalLimit) {
+		log.Printf("loan amount %v exceeds the approval limit %v of the roles of %s\n", amount, approvalLimit, actor.id)
+		return loanAboveApprovalLimit
+	}
+	return nil
+}
+
+// recordLoanApproval : records the approval of the actor, responds with the approvers once the loan has the
+// required approvals and nil while more approvals are required
+func recordLoanApproval(repo repository.LoanRepository, loanDetails *responseDto.LoanDetails, actor *loanActor,
+	tx *repository.Transaction) ([]string, error) {
+	requiredApprovals := getRequiredApprovals(loanDetails.TotalAmount)
+	// api keys are not distinct persons, the quorum is reached by admins only
+	if requiredApprovals > 1 && actor.actorType != USER_TYPE_ADMIN {
+		log.Printf("loan %s requires the approval of admins, %s %s can't approve\n", loanDetails.LoanId,
+			actor.actorType, actor.id)
+		return nil, loanApprovalRequiresAdmin
+	}
+
+	approverIds, err := repo.GetLoanApproverIds(loanDetails.LoanId, tx)
+	if err != nil {
+		log.Printf("failed to get approvals of loan %s, error %v\n", loanDetails.LoanId, err)
+		return nil, app_errors.InternalServerError
+	}
+	for _, approverId := range approverIds {
+		if approverId == actor.id {
+			log.Printf("loan %s is already approved by %s\n", loanDetails.LoanId, actor.id)
+			return nil, loanAlreadyApprovedByUser
+		}
+	}
+
+	err = repo.CreateLoanApproval(&responseDto.LoanApprovalDetails{
+		LoanId:           loanDetails.LoanId,
+		ApproverId:       actor.id,
+		ApproverType:     actor.actorType,
+		CreatedTimestamp: util.GetCurrentTimeInUtc(),
+	}, tx)
+	if err != nil {
+		log.Printf("failed to record approval of loan %s by %s, error %v\n", loanDetails.LoanId, actor.id, err)
+		return nil, app_errors.InternalServerError
+	}
+
+	approverIds = append(approverIds, actor.id)
+	if len(approverIds) < requiredApprovals {
+		log.Printf("loan %s has %d of %d approvals\n", loanDetails.LoanId, len(approverIds), requiredApprovals)
+		// the loan is written on every approval, a concurrent approver counting the approvals before this one is
+		// committed fails to lock the loan instead of leaving it pending with the quorum of approvals
+		err = repo.TouchLoan(loanDetails.LoanId, tx)
+		if err != nil {
+			log.Printf("failed to update loan %s, error %v\n", loanDetails.LoanId, err)
+			return nil, app_errors.InternalServerError
+		}
+		return nil, nil
+	}
+	return approverIds, nil
+}
+
+// approveLoan : approves the loan and re-bases its repayment schedule to the approval date, the schedule of a
+// pending loan is generated at its creation
+func approveLoan(repo repository.LoanRepository, loanDetails *responseDto.LoanDetails, tx *repository.Transaction) error {
+	approvedAt := util.GetCurrentTimeInUtc()
+	rebaseRepayments(loanDetails, approvedAt)
+	err := repo.RescheduleLoan(loanDetails, tx)
+	if err != nil {
+		log.Printf("failed to reschedule loan %s, error %v\n", loanDetails.LoanId, err)
+		return app_errors.InternalServerError
+	}
+
+	err = repo.ApproveLoan(loanDetails.LoanId, approvedAt, tx)
+	if err != nil {
+		log.Printf("failed to approve loan for loanId %s, error %v\n", loanDetails.LoanId, err)
+		return app_errors.InternalServerError
+	}
+	return nil
+}
+
+// getApprovalReason : reason of the approval in the status history, lists the approvers of the quorum
+func getApprovalReason(approverIds []string) string {
+	if len(approverIds) < 2 {
+		return ""
+	}
+	return fmt.Sprintf("approved by %s", strings.Join(approverIds, ", "))
+}
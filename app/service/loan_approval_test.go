@@ -0,0 +1,157 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+
+	responseDto "github.com/s8sg/mini-loan-app/app/dto"
+	repository "github.com/s8sg/mini-loan-app/app/repostory"
+	"github.com/shopspring/decimal"
+)
+
+func TestGetRequiredApprovals(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		expected int
+	}{
+		{1000, 1},
+		{1000000, 1},
+		{1000001, 2},
+	}
+	for _, test := range tests {
+		requiredApprovals := getRequiredApprovals(decimal.NewFromInt(test.amount))
+		if requiredApprovals != test.expected {
+			t.Errorf("%d: expected %d approvals but got %d", test.amount, test.expected, requiredApprovals)
+		}
+	}
+}
+
+func TestCheckApprover(t *testing.T) {
+	loanDetails := &responseDto.LoanDetails{LoanId: "loan1", CustomerId: "user1"}
+
+	if err := checkApprover(loanDetails, &loanActor{id: "user1", actorType: USER_TYPE_ADMIN}); err != loanApproverIsBorrower {
+		t.Errorf("expected the borrower to be refused but got %v", err)
+	}
+	if err := checkApprover(loanDetails, &loanActor{id: "admin1", actorType: USER_TYPE_ADMIN}); err != nil {
+		t.Errorf("expected the admin to be allowed but got %v", err)
+	}
+}
+
+func TestIsAboveApprovalLimit(t *testing.T) {
+	limit := decimal.NewFromInt(50000)
+	tests := []struct {
+		approvalLimit *decimal.Decimal
+		amount        int64
+		expected      bool
+	}{
+		{nil, 2000000, false},
+		{&limit, 50000, false},
+		{&limit, 50001, true},
+	}
+	for _, test := range tests {
+		userDetails := &responseDto.UserDetails{Username: "officer1", ApprovalLimit: test.approvalLimit}
+		if isAboveApprovalLimit(userDetails, decimal.NewFromInt(test.amount)) != test.expected {
+			t.Errorf("%d: expected above approval limit %v", test.amount, test.expected)
+		}
+	}
+}
+
+// stubUserRepository serves the users by username
+type stubUserRepository struct {
+	repository.UserRepository
+	users map[string]*responseDto.UserDetails
+}
+
+func (s *stubUserRepository) GetUserByUsername(username string) (*responseDto.UserDetails, error) {
+	userDetails, ok := s.users[username]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return userDetails, nil
+}
+
+func TestCheckApprovalLimit(t *testing.T) {
+	limit := decimal.NewFromInt(50000)
+	loanService := LoanServiceImplementation{userRepo: &stubUserRepository{users: map[string]*responseDto.UserDetails{
+		"officer1": {Username: "officer1", ApprovalLimit: &limit},
+	}}}
+	roleApprovalLimits := RoleApprovalLimits
+	defer func() { RoleApprovalLimits = roleApprovalLimits }()
+	RoleApprovalLimits = map[string]decimal.Decimal{"credit-officer": decimal.NewFromInt(10000),
+		"credit-manager": decimal.NewFromInt(100000), USER_TYPE_API_KEY: decimal.NewFromInt(5000)}
+	oidcOfficer := &loanActor{id: "oidc-officer", actorType: USER_TYPE_ADMIN,
+		roles: []string{"credit-officer", "credit-manager"}}
+	apiKey := &loanActor{id: "key1", actorType: USER_TYPE_API_KEY, roles: []string{USER_TYPE_API_KEY}}
+
+	tests := []struct {
+		name     string
+		actor    *loanActor
+		amount   int64
+		expected error
+	}{
+		{"within the limit", &loanActor{id: "officer1", actorType: USER_TYPE_ADMIN}, 50000, nil},
+		{"above the limit", &loanActor{id: "officer1", actorType: USER_TYPE_ADMIN}, 50001, loanAboveApprovalLimit},
+		// staff of the external identity provider have no user, they are limited by the highest limit of their roles
+		{"user not found", &loanActor{id: "oidc-officer", actorType: USER_TYPE_ADMIN}, 2000000, loanAboveApprovalLimit},
+		{"user not found within the role limit", oidcOfficer, 100000, nil},
+		{"user not found above the role limit", oidcOfficer, 100001, loanAboveApprovalLimit},
+		{"user not found without a role limit", &loanActor{id: "oidc-auditor", actorType: USER_TYPE_ADMIN,
+			roles: []string{"auditor"}}, 1000, loanAboveApprovalLimit},
+		{"api key within the role limit", apiKey, 5000, nil},
+		{"api key above the role limit", apiKey, 2000000, loanAboveApprovalLimit},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := loanService.checkApprovalLimit(test.actor, decimal.NewFromInt(test.amount)); err != test.expected {
+				t.Errorf("expected %v but got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+// stubLoanRepository records the approvals of the loans and the updates of the loan rows
+type stubLoanRepository struct {
+	repository.LoanRepository
+	approverIds map[string][]string
+	touched     map[string]int
+}
+
+func (s *stubLoanRepository) GetLoanApproverIds(loanId string, tx *repository.Transaction) ([]string, error) {
+	return s.approverIds[loanId], nil
+}
+
+func (s *stubLoanRepository) CreateLoanApproval(approval *responseDto.LoanApprovalDetails,
+	tx *repository.Transaction) error {
+	s.approverIds[approval.LoanId] = append(s.approverIds[approval.LoanId], approval.ApproverId)
+	return nil
+}
+
+func (s *stubLoanRepository) TouchLoan(loanId string, tx *repository.Transaction) error {
+	s.touched[loanId]++
+	return nil
+}
+
+func TestRecordLoanApproval_Quorum(t *testing.T) {
+	repo := &stubLoanRepository{approverIds: map[string][]string{}, touched: map[string]int{}}
+	loanDetails := &responseDto.LoanDetails{LoanId: "loan1", CustomerId: "user1",
+		TotalAmount: decimal.NewFromInt(2000000)}
+
+	approverIds, err := recordLoanApproval(repo, loanDetails, &loanActor{id: "admin1", actorType: USER_TYPE_ADMIN}, nil)
+	if err != nil || approverIds != nil {
+		t.Fatalf("expected the loan to be pending but got %v, %v", approverIds, err)
+	}
+	// concurrent approvers lock the loan row, the partial approval must write it to conflict with them
+	if repo.touched["loan1"] != 1 {
+		t.Errorf("expected the partial approval to update the loan")
+	}
+
+	approverIds, err = recordLoanApproval(repo, loanDetails, &loanActor{id: "admin2", actorType: USER_TYPE_ADMIN}, nil)
+	if err != nil || len(approverIds) != 2 {
+		t.Fatalf("expected the quorum of approvals but got %v, %v", approverIds, err)
+	}
+
+	if _, err = recordLoanApproval(repo, loanDetails, &loanActor{id: "admin1", actorType: USER_TYPE_ADMIN},
+		nil); err != loanAlreadyApprovedByUser {
+		t.Errorf("expected the approval to be refused but got %v", err)
+	}
+}
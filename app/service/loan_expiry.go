@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/s8sg/mini-loan-app/app/app_errors"
+	responseDto "github.com/s8sg/mini-loan-app/app/dto"
+	"github.com/s8sg/mini-loan-app/app/util"
+	"log"
+	"time"
+)
+
+const loanExpiryJob = "loan-expiry-job"
+
+var (
+	// LoanPendingExpiryPeriod is the time after the creation in which a pending loan expires, 0 disables the expiry
+	LoanPendingExpiryPeriod = time.Hour * 24 * 30
+	// LoanExpiryJobInterval is the time between the runs of the loan expiry job
+	LoanExpiryJobInterval = time.Hour
+)
+
+// RunLoanExpiryJob : expires the stale pending loans at every interval of the job, it runs until the process exits
+func RunLoanExpiryJob(loanService LoanService) {
+	if LoanPendingExpiryPeriod == 0 {
+		log.Println("loan expiry job is disabled")
+		return
+	}
+
+	ticker := time.NewTicker(LoanExpiryJobInterval)
+	defer ticker.Stop()
+	for {
+		expiredLoans, err := loanService.ExpireStaleLoans()
+		if err != nil {
+			log.Printf("loan expiry job failed, error %v\n", err)
+		} else if expiredLoans > 0 {
+			log.Printf("loan expiry job expired %d loans\n", expiredLoans)
+		}
+		<-ticker.C
+	}
+}
+
+// ExpireStaleLoans : expires the loans pending for longer than LoanPendingExpiryPeriod and responds with the number
+// of expired loans. Each loan is expired in its own transaction so a failure doesn't block the other loans
+func (l LoanServiceImplementation) ExpireStaleLoans() (int, error) {
+	createdBefore := util.GetCurrentTimeInUtc().Add(-LoanPendingExpiryPeriod)
+	loanIds, err := l.repo.GetStaleLoanIds(responseDto.LoanStatusPending, createdBefore)
+	if err != nil {
+		log.Printf("failed to get the stale loans, error %v\n", err)
+		return 0, app_errors.InternalServerError
+	}
+
+	expiredLoans := 0
+	for _, loanId := range loanIds {
+		expired, err := l.expireLoan(loanId, createdBefore)
+		if err != nil {
+			log.Printf("failed to expire loan %s, error %v\n", loanId, err)
+			continue
+		}
+		if expired {
+			expiredLoans = expiredLoans + 1
+		}
+	}
+	return expiredLoans, nil
+}
+
+// expireLoan : expires the loan if it is still pending and created before createdBefore, the loan may have been
+// approved or cancelled since it was listed
+func (l LoanServiceImplementation) expireLoan(loanId string, createdBefore time.Time) (bool, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	txOption := &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+	}
+
+	tx, err := l.repo.CreateTransaction(ctx, txOption)
+	if err != nil {
+		log.Println("failed to initiate transaction")
+		return false, app_errors.InternalServerError
+	}
+
+	defer func() {
+		if err != nil {
+			log.Println("calling rollback for error " + err.Error())
+			_ = tx.Rollback()
+			return
+		}
+		_ = tx.Commit()
+	}()
+
+	loanDetails, err := l.repo.GetLoanById(loanId, tx)
+	if err != nil {
+		log.Println("loan can not be fetched")
+		return false, loanNotPresent
+	}
+
+	if loanDetails.Status != responseDto.LoanStatusPending || !loanDetails.CreatedTimestamp.Before(createdBefore) {
+		return false, nil
+	}
+
+	actor := getSystemLoanActor(loanExpiryJob)
+	err = checkLoanTransition(loanDetails.Status, responseDto.LoanStatusExpired, actor)
+	if err != nil {
+		return false, err
+	}
+
+	err = l.repo.UpdateLoanStatus(loanId, responseDto.LoanStatusExpired, tx)
+	if err != nil {
+		log.Printf("failed to expire loan for loanId %s, error %v\n", loanId, err)
+		return false, app_errors.InternalServerError
+	}
+
+	reason := fmt.Sprintf("pending for more than %d days", int(LoanPendingExpiryPeriod.Hours()/24))
+	err = recordLoanTransition(l.repo, loanDetails, responseDto.LoanStatusExpired, actor, reason, tx)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
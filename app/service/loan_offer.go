@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/s8sg/mini-loan-app/app/app_errors"
+	"github.com/s8sg/mini-loan-app/app/controller/dto"
+	responseDto "github.com/s8sg/mini-loan-app/app/dto"
+	"github.com/s8sg/mini-loan-app/app/util"
+	"github.com/shopspring/decimal"
+	"log"
+	"time"
+)
+
+var (
+	counterOfferInvalid = &app_errors.AppError{Code: 400,
+		Message: "counter-offer amount, term and interest rate can't be negative"}
+	offerDecisionInvalid = &app_errors.AppError{Code: 400, Message: "decision must be ACCEPT or DECLINE"}
+)
+
+// isCounterOffer : approvals with adjusted terms are offered to the customer before the loan is approved
+func isCounterOffer(loanApproveRequest *dto.LoanApproveRequest) bool {
+	return loanApproveRequest.Amount != 0 || loanApproveRequest.Term != 0 || loanApproveRequest.InterestRate != nil
+}
+
+func validateCounterOffer(loanApproveRequest *dto.LoanApproveRequest) error {
+	if loanApproveRequest.Amount < 0 || loanApproveRequest.Term < 0 ||
+		(loanApproveRequest.InterestRate != nil && *loanApproveRequest.InterestRate < 0) {
+		log.Printf("counter-offer for loan %s is invalid\n", loanApproveRequest.LoanId)
+		return counterOfferInvalid
+	}
+	return nil
+}
+
+// applyCounterOffer : adjusts the terms of the loan to the counter-offer and regenerates the repayment schedule,
+// the origination fee keeps its rate on the adjusted amount. Responds with the terms of the offer
+func applyCounterOffer(loanDetails *responseDto.LoanDetails, loanApproveRequest *dto.LoanApproveRequest) (string, error) {
+	minorUnits, ok := util.GetCurrencyMinorUnits(loanDetails.Currency)
+	if !ok {
+		log.Printf("currency %s is not supported", loanDetails.Currency)
+		return "", currencyNotSupported
+	}
+	round := getRoundingFunc(minorUnits)
+
+	if loanApproveRequest.Amount != 0 {
+		amount := decimal.NewFromFloat(loanApproveRequest.Amount)
+		if !amount.Equal(amount.Truncate(minorUnits)) {
+			log.Printf("loan amount %v has fractions of the minor unit of %s", amount, loanDetails.Currency)
+			return "", loanAmountInvalid
+		}
+		loanDetails.OriginationFee = round(loanDetails.OriginationFee.Mul(amount).Div(loanDetails.TotalAmount))
+		loanDetails.TotalAmount = amount
+	}
+	if loanApproveRequest.Term != 0 {
+		loanDetails.Term = loanApproveRequest.Term
+	}
+	if loanApproveRequest.InterestRate != nil {
+		loanDetails.InterestRate = decimal.NewFromFloat(*loanApproveRequest.InterestRate)
+	}
+
+	repayments, err := generateRepayments(loanDetails, round)
+	if err != nil {
+		return "", err
+	}
+	for _, repayment := range repayments {
+		repayment.RepaymentId = util.GenerateRepaymentID()
+	}
+	loanDetails.Repayments = repayments
+
+	return fmt.Sprintf("amount %v, term %d, interest rate %v", loanDetails.TotalAmount, loanDetails.Term,
+		loanDetails.InterestRate), nil
+}
+
+// checkCounterOfferProduct : the adjusted amount and term must be offered by the version of the loan product the
+// loan was originated at
+func (l LoanServiceImplementation) checkCounterOfferProduct(loanDetails *responseDto.LoanDetails) error {
+	if loanDetails.ProductId == "" {
+		return nil
+	}
+	loanProduct, err := l.productRepo.GetLoanProductVersion(loanDetails.ProductId, loanDetails.ProductVersion)
+	if err != nil {
+		log.Printf("failed to get version %d of loan product %s, error %v\n", loanDetails.ProductVersion,
+			loanDetails.ProductId, err)
+		return app_errors.InternalServerError
+	}
+	return checkProductAmountAndTerm(loanProduct, loanDetails.TotalAmount, loanDetails.Term)
+}
+
+// RespondToLoanOffer : the customer accepts the counter-offer, which approves the loan, or declines it
+func (l LoanServiceImplementation) RespondToLoanOffer(customerId string,
+	loanOfferDecisionRequest *dto.LoanOfferDecisionRequest) error {
+	loanId := loanOfferDecisionRequest.LoanId
+
+	// validate loanId
+	if loanId == "" {
+		log.Println("loan id not specified")
+		return invalidLoanId
+	}
+
+	var nextStatus string
+	switch loanOfferDecisionRequest.Decision {
+	case responseDto.LoanOfferDecisionAccept:
+		nextStatus = responseDto.LoanStatusApproved
+	case responseDto.LoanOfferDecisionDecline:
+		nextStatus = responseDto.LoanStatusDeclined
+	default:
+		log.Printf("offer decision %s is invalid", loanOfferDecisionRequest.Decision)
+		return offerDecisionInvalid
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	txOption := &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+	}
+
+	tx, err := l.repo.CreateTransaction(ctx, txOption)
+	if err != nil {
+		log.Println("failed to initiate transaction")
+		return app_errors.InternalServerError
+	}
+
+	defer func() {
+		if err != nil {
+			log.Println("calling rollback for error " + err.Error())
+			_ = tx.Rollback()
+			return
+		}
+		_ = tx.Commit()
+	}()
+
+	loanDetails, err := l.repo.GetLoanById(loanId, tx)
+	if err != nil {
+		log.Println("loan can not be fetched")
+		return loanNotPresent
+	}
+
+	// check if loan belongs to customer
+	if loanDetails.CustomerId != customerId {
+		log.Println("loan doesn't belongs to customer")
+		err = fmt.Errorf("loan doesn't belongs to customer")
+		return loanNotPresent
+	}
+
+	actor := getCustomerLoanActor(customerId)
+	err = checkLoanTransition(loanDetails.Status, nextStatus, actor)
+	if err != nil {
+		return err
+	}
+
+	if nextStatus == responseDto.LoanStatusApproved {
+		err = approveLoan(l.repo, loanDetails, tx)
+		if err != nil {
+			return err
+		}
+	} else {
+		err = l.repo.UpdateLoanStatus(loanId, nextStatus, tx)
+		if err != nil {
+			log.Printf("failed to decline offered loan %s, error %v\n", loanId, err)
+			return app_errors.InternalServerError
+		}
+	}
+
+	err = recordLoanTransition(l.repo, loanDetails, nextStatus, actor, "", tx)
+	return err
+}
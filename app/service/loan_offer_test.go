@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/s8sg/mini-loan-app/app/controller/dto"
+	responseDto "github.com/s8sg/mini-loan-app/app/dto"
+	"github.com/shopspring/decimal"
+)
+
+func TestCheckCounterOfferProduct(t *testing.T) {
+	loanService := LoanServiceImplementation{productRepo: &stubLoanProductRepository{
+		versions: map[string]*responseDto.LoanProductDetails{
+			"product1/1": {ProductId: "product1", Version: 1, MinAmount: decimal.NewFromInt(1000),
+				MaxAmount: decimal.NewFromInt(5000), Terms: []int64{6, 12}},
+		}}}
+
+	tests := []struct {
+		name      string
+		productId string
+		request   *dto.LoanApproveRequest
+		expected  error
+	}{
+		{"offered amount and term", "product1", &dto.LoanApproveRequest{Amount: 2000, Term: 6}, nil},
+		{"amount above the product", "product1", &dto.LoanApproveRequest{Amount: 6000}, loanAmountOutOfRange},
+		{"amount below the product", "product1", &dto.LoanApproveRequest{Amount: 500}, loanAmountOutOfRange},
+		{"term not offered", "product1", &dto.LoanApproveRequest{Term: 9}, loanTermNotOffered},
+		{"loan without product", "", &dto.LoanApproveRequest{Amount: 6000, Term: 9}, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			loanDetails := &responseDto.LoanDetails{LoanId: "loan1", ProductId: test.productId, ProductVersion: 1,
+				TotalAmount: decimal.NewFromInt(3000), Currency: "USD", Term: 12,
+				InterestMethod: responseDto.InterestMethodFlat, Frequency: responseDto.RepaymentFrequencyMonthly}
+			if _, err := applyCounterOffer(loanDetails, test.request); err != nil {
+				t.Fatal(err)
+			}
+			if err := loanService.checkCounterOfferProduct(loanDetails); err != test.expected {
+				t.Errorf("expected %v but got %v", test.expected, err)
+			}
+		})
+	}
+}
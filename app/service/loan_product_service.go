@@ -0,0 +1,206 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/s8sg/mini-loan-app/app/app_errors"
+	"github.com/s8sg/mini-loan-app/app/controller/dto"
+	responseDto "github.com/s8sg/mini-loan-app/app/dto"
+	repository "github.com/s8sg/mini-loan-app/app/repostory"
+	"github.com/s8sg/mini-loan-app/app/util"
+	"github.com/shopspring/decimal"
+	"log"
+)
+
+var (
+	loanProductNotFound       = &app_errors.AppError{Code: 404, Message: "loan product not found"}
+	loanProductNameNotPresent = &app_errors.AppError{Code: 400, Message: "loan product name must be provided"}
+	loanProductStatusInvalid  = &app_errors.AppError{Code: 400, Message: "loan product status must be ACTIVE or INACTIVE"}
+	loanProductAmountInvalid  = &app_errors.AppError{Code: 400,
+		Message: "loan product min amount must be positive and not greater than the max amount"}
+	loanProductTermsInvalid = &app_errors.AppError{Code: 400, Message: "loan product terms must be provided and greater than 0"}
+	loanProductFeeInvalid   = &app_errors.AppError{Code: 400, Message: "origination fee rate can't be negative"}
+	loanProductRuleInvalid  = &app_errors.AppError{Code: 400,
+		Message: "max open loans and min customer days can't be negative"}
+)
+
+type LoanProductService interface {
+	CreateLoanProduct(request *dto.LoanProductSaveRequest) (*responseDto.LoanProductDetails, error)
+	UpdateLoanProduct(productId string, request *dto.LoanProductSaveRequest) (*responseDto.LoanProductDetails, error)
+	GetAllLoanProducts() ([]*responseDto.LoanProductDetails, error)
+	GetActiveLoanProducts() ([]*responseDto.LoanProductDetails, error)
+}
+
+type LoanProductServiceImplementation struct {
+	repo repository.LoanProductRepository
+}
+
+// GetLoanProductService : Initialise loan-product-service, uses dependency loanProductRepository
+func GetLoanProductService(loanProductRepository repository.LoanProductRepository) LoanProductService {
+	loanProductService := &LoanProductServiceImplementation{
+		repo: loanProductRepository,
+	}
+	return loanProductService
+}
+
+func (l LoanProductServiceImplementation) CreateLoanProduct(
+	request *dto.LoanProductSaveRequest) (*responseDto.LoanProductDetails, error) {
+	loanProductDetails, err := validateLoanProduct(request)
+	if err != nil {
+		return nil, err
+	}
+	loanProductDetails.ProductId = util.GenerateLoanProductID()
+	loanProductDetails.Version = 1
+	loanProductDetails.CreatedTimestamp = util.GetCurrentTimeInUtc()
+	loanProductDetails.UpdatedTimestamp = util.GetCurrentTimeInUtc()
+
+	err = l.repo.CreateLoanProduct(loanProductDetails)
+	if err != nil {
+		log.Printf("failed to create loan product %s, error %v\n", request.Name, err)
+		return nil, app_errors.InternalServerError
+	}
+	return loanProductDetails, nil
+}
+
+// UpdateLoanProduct : replaces the terms of the product, existing loans keep the terms of their origination
+func (l LoanProductServiceImplementation) UpdateLoanProduct(productId string,
+	request *dto.LoanProductSaveRequest) (*responseDto.LoanProductDetails, error) {
+	existingProduct, err := l.repo.GetLoanProductById(productId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("loan product %s not found\n", productId)
+			return nil, loanProductNotFound
+		}
+		log.Printf("failed to get loan product %s, error %v\n", productId, err)
+		return nil, app_errors.InternalServerError
+	}
+
+	loanProductDetails, err := validateLoanProduct(request)
+	if err != nil {
+		return nil, err
+	}
+	loanProductDetails.ProductId = productId
+	loanProductDetails.CreatedTimestamp = existingProduct.CreatedTimestamp
+	loanProductDetails.UpdatedTimestamp = util.GetCurrentTimeInUtc()
+
+	err = l.repo.UpdateLoanProduct(loanProductDetails)
+	if err != nil {
+		log.Printf("failed to update loan product %s, error %v\n", productId, err)
+		return nil, app_errors.InternalServerError
+	}
+	return loanProductDetails, nil
+}
+
+func (l LoanProductServiceImplementation) GetAllLoanProducts() ([]*responseDto.LoanProductDetails, error) {
+	loanProductDetailsList, err := l.repo.GetAllLoanProducts()
+	if err != nil {
+		log.Printf("failed to get loan products, error %v\n", err)
+		return nil, app_errors.InternalServerError
+	}
+	return loanProductDetailsList, nil
+}
+
+// GetActiveLoanProducts : products offered to the customers
+func (l LoanProductServiceImplementation) GetActiveLoanProducts() ([]*responseDto.LoanProductDetails, error) {
+	loanProductDetailsList, err := l.repo.GetLoanProductsByStatus(responseDto.LoanProductStatusActive)
+	if err != nil {
+		log.Printf("failed to get active loan products, error %v\n", err)
+		return nil, app_errors.InternalServerError
+	}
+	return loanProductDetailsList, nil
+}
+
+// validateLoanProduct : validates the request and creates the product terms, defaults are the same as for loans
+func validateLoanProduct(request *dto.LoanProductSaveRequest) (*responseDto.LoanProductDetails, error) {
+	if request.Name == "" {
+		return nil, loanProductNameNotPresent
+	}
+
+	status := request.Status
+	switch status {
+	case "":
+		status = responseDto.LoanProductStatusActive
+	case responseDto.LoanProductStatusActive, responseDto.LoanProductStatusInactive:
+	default:
+		return nil, loanProductStatusInvalid
+	}
+
+	currency := request.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	if _, ok := util.GetCurrencyMinorUnits(currency); !ok {
+		log.Printf("currency %s is not supported", currency)
+		return nil, currencyNotSupported
+	}
+
+	if request.MinAmount <= 0 || request.MaxAmount < request.MinAmount {
+		return nil, loanProductAmountInvalid
+	}
+
+	if len(request.Terms) == 0 {
+		return nil, loanProductTermsInvalid
+	}
+	for _, term := range request.Terms {
+		if term < 1 {
+			return nil, loanProductTermsInvalid
+		}
+	}
+
+	if request.InterestRate < 0 {
+		return nil, interestRateInvalid
+	}
+
+	interestMethod := request.InterestMethod
+	switch interestMethod {
+	case "":
+		interestMethod = responseDto.InterestMethodFlat
+	case responseDto.InterestMethodFlat, responseDto.InterestMethodDecliningBalance, responseDto.InterestMethodInterestOnly:
+	default:
+		return nil, interestMethodInvalid
+	}
+
+	frequency := request.Frequency
+	if frequency == "" {
+		frequency = responseDto.RepaymentFrequencyWeekly
+	}
+	if !isValidRepaymentFrequency(frequency) {
+		return nil, frequencyInvalid
+	}
+
+	if request.OriginationFeeRate < 0 {
+		return nil, loanProductFeeInvalid
+	}
+
+	if request.MaxOpenLoans < 0 || request.MinCustomerDays < 0 {
+		return nil, loanProductRuleInvalid
+	}
+
+	return &responseDto.LoanProductDetails{
+		Name:               request.Name,
+		Description:        request.Description,
+		Status:             status,
+		Currency:           currency,
+		MinAmount:          decimal.NewFromFloat(request.MinAmount),
+		MaxAmount:          decimal.NewFromFloat(request.MaxAmount),
+		Terms:              uniqueTerms(request.Terms),
+		InterestRate:       decimal.NewFromFloat(request.InterestRate),
+		InterestMethod:     interestMethod,
+		Frequency:          frequency,
+		OriginationFeeRate: decimal.NewFromFloat(request.OriginationFeeRate),
+		MaxOpenLoans:       request.MaxOpenLoans,
+		MinCustomerDays:    request.MinCustomerDays,
+	}, nil
+}
+
+func uniqueTerms(terms []int64) []int64 {
+	seen := make(map[int64]bool, len(terms))
+	unique := make([]int64, 0, len(terms))
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			unique = append(unique, term)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/s8sg/mini-loan-app/app/app_errors"
+	"github.com/s8sg/mini-loan-app/app/controller/dto"
+	responseDto "github.com/s8sg/mini-loan-app/app/dto"
+	"github.com/s8sg/mini-loan-app/app/util"
+	"github.com/shopspring/decimal"
+	"log"
+	"time"
+)
+
+const loanQuoteAudience = "loan-quote"
+
+var (
+	// LoanQuoteExpiry is the time a loan can be created at the terms of a quote
+	LoanQuoteExpiry = time.Minute * 15
+)
+
+var (
+	loanQuoteInvalid    = &app_errors.AppError{Code: 400, Message: "loan quote is invalid or expired"}
+	loanTermsSetByQuote = &app_errors.AppError{Code: 400, Message: "loan terms are set by the quote"}
+	// loanQuoteAlreadyUsed is returned when the quote already created a loan, a quote creates one loan
+	loanQuoteAlreadyUsed = &app_errors.AppError{Code: 409, Message: "loan quote is already used"}
+)
+
+// loanQuoteClaims : terms of the quoted loan, the quote id is signed so the quote doesn't need to be stored
+type loanQuoteClaims struct {
+	ProductId          string          `json:"product-id,omitempty"`
+	ProductVersion     int             `json:"product-version,omitempty"`
+	Amount             decimal.Decimal `json:"amount"`
+	Term               int             `json:"term"`
+	Currency           string          `json:"currency"`
+	InterestRate       decimal.Decimal `json:"interest-rate"`
+	InterestMethod     string          `json:"interest-method"`
+	Frequency          string          `json:"repayment-frequency"`
+	OriginationFeeRate decimal.Decimal `json:"origination-fee-rate"`
+	jwt.RegisteredClaims
+}
+
+// QuoteLoan : generates the repayment schedule of the loan without persisting it, the quote id creates one loan
+// at the same terms until the quote expires
+func (l LoanServiceImplementation) QuoteLoan(customerId string,
+	loanCreateRequest *dto.LoanCreateRequest) (*responseDto.LoanQuoteDetails, error) {
+	if loanCreateRequest.QuoteId != "" {
+		log.Printf("quote id is provided for a quote")
+		return nil, loanTermsSetByQuote
+	}
+
+	loanDetails, terms, err := l.buildLoan(customerId, loanCreateRequest, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := util.GetCurrentTimeInUtc().Add(LoanQuoteExpiry)
+	claims := &loanQuoteClaims{
+		ProductId:          terms.productId,
+		ProductVersion:     terms.productVersion,
+		Amount:             loanDetails.TotalAmount,
+		Term:               loanDetails.Term,
+		Currency:           terms.currency,
+		InterestRate:       terms.interestRate,
+		InterestMethod:     terms.interestMethod,
+		Frequency:          terms.frequency,
+		OriginationFeeRate: terms.originationFeeRate,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        util.GenerateQuoteID(),
+			Subject:   customerId,
+			Audience:  jwt.ClaimStrings{loanQuoteAudience},
+			IssuedAt:  jwt.NewNumericDate(util.GetCurrentTimeInUtc()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+	quoteId, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(l.quoteSigningKey)
+	if err != nil {
+		log.Printf("failed to sign quote for customer %s, error %v\n", customerId, err)
+		return nil, app_errors.InternalServerError
+	}
+
+	totalInterest := decimal.Zero
+	totalRepayment := decimal.Zero
+	for _, repayment := range loanDetails.Repayments {
+		totalInterest = totalInterest.Add(repayment.Interest)
+		totalRepayment = totalRepayment.Add(repayment.Amount)
+	}
+
+	return &responseDto.LoanQuoteDetails{
+		QuoteId:        quoteId,
+		ExpiresAt:      expiresAt,
+		TotalInterest:  totalInterest,
+		TotalRepayment: totalRepayment,
+		Apr:            calculateApr(loanDetails.TotalAmount, loanDetails.Repayments, loanDetails.Frequency),
+		LoanDetails:    loanDetails,
+	}, nil
+}
+
+// getQuotedLoanProduct : terms of the loan product at the version of the quote, the quoted terms must be the terms of
+// the version
+func (l LoanServiceImplementation) getQuotedLoanProduct(quotedTerms *loanTerms) (*responseDto.LoanProductDetails, error) {
+	loanProduct, err := l.productRepo.GetLoanProductVersion(quotedTerms.productId, quotedTerms.productVersion)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("version %d of loan product %s not found\n", quotedTerms.productVersion, quotedTerms.productId)
+			return nil, loanQuoteInvalid
+		}
+		log.Printf("failed to get version %d of loan product %s, error %v\n", quotedTerms.productVersion,
+			quotedTerms.productId, err)
+		return nil, app_errors.InternalServerError
+	}
+
+	if quotedTerms.currency != loanProduct.Currency || !quotedTerms.interestRate.Equal(loanProduct.InterestRate) ||
+		quotedTerms.interestMethod != loanProduct.InterestMethod || quotedTerms.frequency != loanProduct.Frequency ||
+		!quotedTerms.originationFeeRate.Equal(loanProduct.OriginationFeeRate) {
+		log.Printf("quoted terms don't match version %d of loan product %s\n", quotedTerms.productVersion,
+			quotedTerms.productId)
+		return nil, loanQuoteInvalid
+	}
+	return loanProduct, nil
+}
+
+// parseLoanQuote : validates the quote of the customer and responds with the quoted request and terms
+func (l LoanServiceImplementation) parseLoanQuote(customerId string,
+	loanCreateRequest *dto.LoanCreateRequest) (*dto.LoanCreateRequest, *loanTerms, error) {
+	if loanCreateRequest.ProductId != "" || loanCreateRequest.Amount != 0 || loanCreateRequest.Term != 0 ||
+		loanCreateRequest.Currency != "" || loanCreateRequest.InterestRate != 0 ||
+		loanCreateRequest.InterestMethod != "" || loanCreateRequest.Frequency != "" {
+		log.Printf("loan terms are provided with quote")
+		return nil, nil, loanTermsSetByQuote
+	}
+
+	claims := &loanQuoteClaims{}
+	_, err := jwt.ParseWithClaims(loanCreateRequest.QuoteId, claims, func(token *jwt.Token) (interface{}, error) {
+		return l.quoteSigningKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		log.Printf("failed to validate quote, error %v\n", err)
+		return nil, nil, loanQuoteInvalid
+	}
+	if !claims.VerifyAudience(loanQuoteAudience, true) || claims.Subject != customerId {
+		log.Printf("quote of %s is used by customer %s\n", claims.Subject, customerId)
+		return nil, nil, loanQuoteInvalid
+	}
+	// the id makes the quote single use
+	if _, err := uuid.Parse(claims.ID); err != nil {
+		log.Printf("quote of %s has no valid id\n", claims.Subject)
+		return nil, nil, loanQuoteInvalid
+	}
+
+	quotedRequest := &dto.LoanCreateRequest{
+		ProductId: claims.ProductId,
+		Amount:    claims.Amount.InexactFloat64(),
+		Term:      claims.Term,
+	}
+	terms := &loanTerms{
+		quoteId:            claims.ID,
+		productId:          claims.ProductId,
+		productVersion:     claims.ProductVersion,
+		currency:           claims.Currency,
+		interestRate:       claims.InterestRate,
+		interestMethod:     claims.InterestMethod,
+		frequency:          claims.Frequency,
+		originationFeeRate: claims.OriginationFeeRate,
+	}
+	return quotedRequest, terms, nil
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/s8sg/mini-loan-app/app/controller/dto"
+	responseDto "github.com/s8sg/mini-loan-app/app/dto"
+	repository "github.com/s8sg/mini-loan-app/app/repostory"
+	"github.com/shopspring/decimal"
+)
+
+// stubLoanProductRepository serves the versions of the loan products, keyed by <product-id>/<version>
+type stubLoanProductRepository struct {
+	repository.LoanProductRepository
+	versions map[string]*responseDto.LoanProductDetails
+}
+
+func (s *stubLoanProductRepository) GetLoanProductVersion(productId string,
+	version int) (*responseDto.LoanProductDetails, error) {
+	loanProduct, ok := s.versions[fmt.Sprintf("%s/%d", productId, version)]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return loanProduct, nil
+}
+
+func TestGetQuotedLoanProduct(t *testing.T) {
+	version1 := &responseDto.LoanProductDetails{ProductId: "product1", Version: 1, Currency: "USD",
+		MinAmount: decimal.NewFromInt(1000), MaxAmount: decimal.NewFromInt(5000), Terms: []int64{6, 12},
+		InterestRate: decimal.NewFromInt(12), InterestMethod: responseDto.InterestMethodFlat,
+		Frequency: responseDto.RepaymentFrequencyMonthly, OriginationFeeRate: decimal.NewFromInt(1)}
+	loanService := LoanServiceImplementation{productRepo: &stubLoanProductRepository{
+		versions: map[string]*responseDto.LoanProductDetails{"product1/1": version1}}}
+
+	quotedTerms := func(update func(terms *loanTerms)) *loanTerms {
+		terms := &loanTerms{productId: "product1", productVersion: 1, currency: "USD",
+			interestRate: decimal.NewFromInt(12), interestMethod: responseDto.InterestMethodFlat,
+			frequency: responseDto.RepaymentFrequencyMonthly, originationFeeRate: decimal.NewFromInt(1)}
+		update(terms)
+		return terms
+	}
+
+	loanProduct, err := loanService.getQuotedLoanProduct(quotedTerms(func(terms *loanTerms) {}))
+	if err != nil || loanProduct != version1 {
+		t.Fatalf("expected version 1 of the product but got %v, %v", loanProduct, err)
+	}
+
+	invalidTerms := map[string]*loanTerms{
+		"unknown version":       quotedTerms(func(terms *loanTerms) { terms.productVersion = 2 }),
+		"other interest rate":   quotedTerms(func(terms *loanTerms) { terms.interestRate = decimal.Zero }),
+		"other fee rate":        quotedTerms(func(terms *loanTerms) { terms.originationFeeRate = decimal.Zero }),
+		"other currency":        quotedTerms(func(terms *loanTerms) { terms.currency = "EUR" }),
+		"other interest method": quotedTerms(func(terms *loanTerms) { terms.interestMethod = "" }),
+		"other frequency": quotedTerms(func(terms *loanTerms) {
+			terms.frequency = responseDto.RepaymentFrequencyWeekly
+		}),
+	}
+	for name, terms := range invalidTerms {
+		t.Run(name, func(t *testing.T) {
+			if _, err := loanService.getQuotedLoanProduct(terms); err != loanQuoteInvalid {
+				t.Errorf("expected the quote to be invalid but got %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckProductAmountAndTerm(t *testing.T) {
+	loanProduct := &responseDto.LoanProductDetails{ProductId: "product1", MinAmount: decimal.NewFromInt(1000),
+		MaxAmount: decimal.NewFromInt(5000), Terms: []int64{6, 12}}
+	tests := []struct {
+		amount   int64
+		term     int
+		expected error
+	}{
+		{1000, 6, nil},
+		{5000, 12, nil},
+		{999, 6, loanAmountOutOfRange},
+		{5001, 12, loanAmountOutOfRange},
+		{3000, 9, loanTermNotOffered},
+	}
+	for _, test := range tests {
+		err := checkProductAmountAndTerm(loanProduct, decimal.NewFromInt(test.amount), test.term)
+		if err != test.expected {
+			t.Errorf("%d/%d: expected %v but got %v", test.amount, test.term, test.expected, err)
+		}
+	}
+}
+
+func TestParseLoanQuote_QuoteId(t *testing.T) {
+	loanService := LoanServiceImplementation{quoteSigningKey: []byte("quote-key")}
+	signQuote := func(quoteId string) string {
+		claims := &loanQuoteClaims{ProductId: "product1", ProductVersion: 1, Amount: decimal.NewFromInt(1000),
+			Term: 6, RegisteredClaims: jwt.RegisteredClaims{
+				ID:        quoteId,
+				Subject:   "customer1",
+				Audience:  jwt.ClaimStrings{loanQuoteAudience},
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			}}
+		quote, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(loanService.quoteSigningKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return quote
+	}
+
+	quoteId := "5b0a4a52-6f4e-4a8e-9a0c-3d2a8c1f7e11"
+	_, terms, err := loanService.parseLoanQuote("customer1", &dto.LoanCreateRequest{QuoteId: signQuote(quoteId)})
+	if err != nil || terms.quoteId != quoteId {
+		t.Fatalf("expected the terms of quote %s but got %v, %v", quoteId, terms, err)
+	}
+
+	// quotes without an id can't be tracked as used
+	for name, quoteId := range map[string]string{"no id": "", "invalid id": "quote1"} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := loanService.parseLoanQuote("customer1", &dto.LoanCreateRequest{QuoteId: signQuote(quoteId)})
+			if err != loanQuoteInvalid {
+				t.Errorf("expected the quote to be invalid but got %v", err)
+			}
+		})
+	}
+}
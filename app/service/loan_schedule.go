@@ -0,0 +1,234 @@
+package service
+
+import (
+	"fmt"
+	responseDto "github.com/s8sg/mini-loan-app/app/dto"
+	"github.com/s8sg/mini-loan-app/app/util"
+	"github.com/shopspring/decimal"
+	"log"
+	"math"
+	"time"
+)
+
+// Rounding modes of the installment amounts to the minor unit of the currency
+const (
+	ROUNDING_MODE_HALF_UP   = "HALF_UP"
+	ROUNDING_MODE_HALF_EVEN = "HALF_EVEN"
+	ROUNDING_MODE_UP        = "UP"
+	ROUNDING_MODE_DOWN      = "DOWN"
+)
+
+var (
+	// DefaultCurrency is the currency of loans created without a currency
+	DefaultCurrency = "USD"
+	// InstallmentRoundingMode rounds the principal and the interest of the installments to the minor unit,
+	// the remainder of the principal is added to the last installment
+	InstallmentRoundingMode = ROUNDING_MODE_HALF_UP
+)
+
+var (
+	hundred = decimal.NewFromInt(100)
+)
+
+// repaymentsPerYear : number of repayments in a year for the repayment frequencies
+var repaymentsPerYear = map[string]int64{
+	responseDto.RepaymentFrequencyWeekly:      52,
+	responseDto.RepaymentFrequencyBiWeekly:    26,
+	responseDto.RepaymentFrequencySemiMonthly: 24,
+	responseDto.RepaymentFrequencyMonthly:     12,
+}
+
+// ValidateLoanRounding : validates the default currency and the rounding mode configured for the installments
+func ValidateLoanRounding(currency string, roundingMode string) error {
+	if _, ok := util.GetCurrencyMinorUnits(currency); !ok {
+		return fmt.Errorf("currency %s is not supported", currency)
+	}
+	switch roundingMode {
+	case ROUNDING_MODE_HALF_UP, ROUNDING_MODE_HALF_EVEN, ROUNDING_MODE_UP, ROUNDING_MODE_DOWN:
+		return nil
+	}
+	return fmt.Errorf("rounding mode %s is not supported", roundingMode)
+}
+
+// getRoundingFunc : rounds amounts to the minor unit of the currency with the InstallmentRoundingMode
+func getRoundingFunc(minorUnits int32) func(decimal.Decimal) decimal.Decimal {
+	return func(amount decimal.Decimal) decimal.Decimal {
+		switch InstallmentRoundingMode {
+		case ROUNDING_MODE_HALF_EVEN:
+			return amount.RoundBank(minorUnits)
+		case ROUNDING_MODE_UP:
+			return amount.RoundUp(minorUnits)
+		case ROUNDING_MODE_DOWN:
+			return amount.RoundDown(minorUnits)
+		default:
+			return amount.Round(minorUnits)
+		}
+	}
+}
+
+// installment : principal and interest due with a repayment of the schedule
+type installment struct {
+	principal decimal.Decimal
+	interest  decimal.Decimal
+}
+
+// isValidRepaymentFrequency : checks if the repayment frequency is supported
+func isValidRepaymentFrequency(frequency string) bool {
+	_, ok := repaymentsPerYear[frequency]
+	return ok
+}
+
+// getPeriodRate : interest rate per repayment for the annual interest rate in percent
+func getPeriodRate(annualInterestRate decimal.Decimal, frequency string) decimal.Decimal {
+	return annualInterestRate.Div(hundred).Div(decimal.NewFromInt(repaymentsPerYear[frequency]))
+}
+
+// getDueDate : due date of the repayment with the number (starting at 1), due dates are computed from the start date
+// so months with fewer days don't shift the following due dates. Semi-monthly repayments are due 15 days after
+// the monthly due date and on the monthly due date
+func getDueDate(startDate time.Time, frequency string, number int) time.Time {
+	switch frequency {
+	case responseDto.RepaymentFrequencyBiWeekly:
+		return startDate.AddDate(0, 0, 14*number)
+	case responseDto.RepaymentFrequencySemiMonthly:
+		dueDate := addMonths(startDate, number/2)
+		if number%2 == 1 {
+			dueDate = dueDate.AddDate(0, 0, 15)
+		}
+		return dueDate
+	case responseDto.RepaymentFrequencyMonthly:
+		return addMonths(startDate, number)
+	default:
+		return startDate.AddDate(0, 0, 7*number)
+	}
+}
+
+// addMonths : adds calendar months, the day is clamped to the end of the month (e.g. Jan 31 + 1 month = Feb 28)
+func addMonths(date time.Time, months int) time.Time {
+	year, month, day := date.Date()
+	firstOfMonth := time.Date(year, month+time.Month(months), 1, date.Hour(), date.Minute(), date.Second(),
+		date.Nanosecond(), date.Location())
+	daysInMonth := firstOfMonth.AddDate(0, 1, -1).Day()
+	if day > daysInMonth {
+		day = daysInMonth
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
+
+// generateInstallments : splits the principal over the term and charges the interest of each period
+// with the interest method of the loan. Amounts are rounded with round, the last installment takes the
+// remainder so the principal of the installments always sums up to the principal of the loan
+func generateInstallments(principal decimal.Decimal, periodRate decimal.Decimal, interestMethod string,
+	term int, round func(decimal.Decimal) decimal.Decimal) []*installment {
+	installments := make([]*installment, term)
+	termDecimal := decimal.NewFromInt(int64(term))
+
+	switch interestMethod {
+	case responseDto.InterestMethodDecliningBalance:
+		// equated installment: principal * r / (1 - (1 + r)^-n), the interest is charged on the outstanding principal
+		installmentAmount := principal.Div(termDecimal)
+		if periodRate.IsPositive() {
+			compounded := decimal.NewFromInt(1).Add(periodRate).Pow(termDecimal)
+			installmentAmount = principal.Mul(periodRate).Mul(compounded).Div(compounded.Sub(decimal.NewFromInt(1)))
+		}
+		installmentAmount = round(installmentAmount)
+		outstanding := principal
+		for i := 0; i < term; i++ {
+			interest := round(outstanding.Mul(periodRate))
+			principalPart := installmentAmount.Sub(interest)
+			if i == term-1 {
+				principalPart = outstanding
+			}
+			outstanding = outstanding.Sub(principalPart)
+			installments[i] = &installment{principal: principalPart, interest: interest}
+		}
+
+	case responseDto.InterestMethodInterestOnly:
+		interest := round(principal.Mul(periodRate))
+		for i := 0; i < term; i++ {
+			installments[i] = &installment{principal: decimal.Zero, interest: interest}
+		}
+		installments[term-1].principal = principal
+
+	default:
+		// flat: the interest is charged on the original principal for every period
+		principalPart := round(principal.Div(termDecimal))
+		interest := round(principal.Mul(periodRate))
+		for i := 0; i < term; i++ {
+			installments[i] = &installment{principal: principalPart, interest: interest}
+		}
+		installments[term-1].principal = principal.Sub(principalPart.Mul(decimal.NewFromInt(int64(term - 1))))
+	}
+
+	return installments
+}
+
+// generateRepayments : generates the repayment schedule of the loan, each repayment is the principal and the
+// interest of the period, the origination fee is charged with the first repayment
+func generateRepayments(loanDetails *responseDto.LoanDetails,
+	round func(decimal.Decimal) decimal.Decimal) ([]*responseDto.RepaymentDetails, error) {
+	repayments := make([]*responseDto.RepaymentDetails, loanDetails.Term)
+	periodRate := getPeriodRate(loanDetails.InterestRate, loanDetails.Frequency)
+	installments := generateInstallments(loanDetails.TotalAmount, periodRate, loanDetails.InterestMethod, loanDetails.Term,
+		round)
+	for i := 0; i < loanDetails.Term; i++ {
+		if installments[i].principal.IsNegative() {
+			log.Printf("loan amount %v can't be split into %d repayments", loanDetails.TotalAmount, loanDetails.Term)
+			return nil, loanAmountTooSmall
+		}
+
+		fee := decimal.Zero
+		if i == 0 {
+			fee = loanDetails.OriginationFee
+		}
+
+		repayments[i] = &responseDto.RepaymentDetails{
+			Number:           i + 1,
+			Amount:           installments[i].principal.Add(installments[i].interest).Add(fee),
+			Principal:        installments[i].principal,
+			Interest:         installments[i].interest,
+			Fee:              fee,
+			DueDate:          getDueDate(loanDetails.StartDate, loanDetails.Frequency, i+1),
+			Status:           responseDto.RepaymentStatusPending,
+			CreatedTimestamp: util.GetCurrentTimeInUtc(),
+			UpdatedTimestamp: util.GetCurrentTimeInUtc(),
+		}
+	}
+	return repayments, nil
+}
+
+// rebaseRepayments : moves the repayment schedule to start at the start date, the amounts are unchanged
+func rebaseRepayments(loanDetails *responseDto.LoanDetails, startDate time.Time) {
+	loanDetails.StartDate = startDate
+	for _, repayment := range loanDetails.Repayments {
+		repayment.DueDate = getDueDate(startDate, loanDetails.Frequency, repayment.Number)
+	}
+}
+
+// calculateApr : annual percentage rate in percent, the rate per period at which the present value of the repayments
+// (including the fees) equals the amount of the loan, times the repayments per year
+func calculateApr(amount decimal.Decimal, repayments []*responseDto.RepaymentDetails, frequency string) decimal.Decimal {
+	principal := amount.InexactFloat64()
+	presentValue := func(periodRate float64) float64 {
+		value := 0.0
+		for i, repayment := range repayments {
+			value += repayment.Amount.InexactFloat64() / math.Pow(1+periodRate, float64(i+1))
+		}
+		return value
+	}
+
+	if presentValue(0) <= principal {
+		return decimal.Zero
+	}
+	// the present value declines with the rate, bisect the rate between 0 and 100% per period
+	low, high := 0.0, 1.0
+	for i := 0; i < 100; i++ {
+		mid := (low + high) / 2
+		if presentValue(mid) > principal {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return decimal.NewFromFloat(low * float64(repaymentsPerYear[frequency]) * 100).Round(2)
+}
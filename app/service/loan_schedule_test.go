@@ -0,0 +1,228 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	responseDto "github.com/s8sg/mini-loan-app/app/dto"
+	"github.com/shopspring/decimal"
+)
+
+func TestGetPeriodRate(t *testing.T) {
+	tests := []struct {
+		annualRate int64
+		frequency  string
+		expected   string
+	}{
+		{52, responseDto.RepaymentFrequencyWeekly, "0.01"},
+		{52, responseDto.RepaymentFrequencyBiWeekly, "0.02"},
+		{48, responseDto.RepaymentFrequencySemiMonthly, "0.02"},
+		{12, responseDto.RepaymentFrequencyMonthly, "0.01"},
+	}
+	for _, test := range tests {
+		periodRate := getPeriodRate(decimal.NewFromInt(test.annualRate), test.frequency)
+		if !periodRate.Equal(decimal.RequireFromString(test.expected)) {
+			t.Errorf("%s: expected period rate %s but got %v", test.frequency, test.expected, periodRate)
+		}
+	}
+}
+
+func TestGetDueDate(t *testing.T) {
+	startDate := time.Date(2024, time.January, 31, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		frequency string
+		expected  []time.Time
+	}{
+		{responseDto.RepaymentFrequencyWeekly, []time.Time{
+			time.Date(2024, time.February, 7, 10, 0, 0, 0, time.UTC),
+			time.Date(2024, time.February, 14, 10, 0, 0, 0, time.UTC),
+		}},
+		{responseDto.RepaymentFrequencyBiWeekly, []time.Time{
+			time.Date(2024, time.February, 14, 10, 0, 0, 0, time.UTC),
+			time.Date(2024, time.February, 28, 10, 0, 0, 0, time.UTC),
+		}},
+		{responseDto.RepaymentFrequencySemiMonthly, []time.Time{
+			time.Date(2024, time.February, 15, 10, 0, 0, 0, time.UTC),
+			time.Date(2024, time.February, 29, 10, 0, 0, 0, time.UTC),
+			time.Date(2024, time.March, 15, 10, 0, 0, 0, time.UTC),
+			time.Date(2024, time.March, 31, 10, 0, 0, 0, time.UTC),
+		}},
+		// the day is clamped to the end of short months but doesn't shift the following due dates
+		{responseDto.RepaymentFrequencyMonthly, []time.Time{
+			time.Date(2024, time.February, 29, 10, 0, 0, 0, time.UTC),
+			time.Date(2024, time.March, 31, 10, 0, 0, 0, time.UTC),
+			time.Date(2024, time.April, 30, 10, 0, 0, 0, time.UTC),
+			time.Date(2024, time.May, 31, 10, 0, 0, 0, time.UTC),
+		}},
+	}
+	for _, test := range tests {
+		for i, expected := range test.expected {
+			dueDate := getDueDate(startDate, test.frequency, i+1)
+			if !dueDate.Equal(expected) {
+				t.Errorf("%s: expected due date %d to be %v but got %v", test.frequency, i+1, expected, dueDate)
+			}
+		}
+	}
+}
+
+func TestRebaseRepayments(t *testing.T) {
+	createdAt := time.Date(2024, time.January, 31, 10, 0, 0, 0, time.UTC)
+	approvedAt := time.Date(2024, time.March, 4, 12, 0, 0, 0, time.UTC)
+	loanDetails := &responseDto.LoanDetails{
+		StartDate: createdAt,
+		Frequency: responseDto.RepaymentFrequencyWeekly,
+		Repayments: []*responseDto.RepaymentDetails{
+			{Number: 1, Amount: decimal.NewFromInt(100), DueDate: createdAt},
+			{Number: 2, Amount: decimal.NewFromInt(100), DueDate: createdAt},
+		},
+	}
+
+	rebaseRepayments(loanDetails, approvedAt)
+
+	if !loanDetails.StartDate.Equal(approvedAt) {
+		t.Errorf("expected start date %v but got %v", approvedAt, loanDetails.StartDate)
+	}
+	expected := []time.Time{
+		time.Date(2024, time.March, 11, 12, 0, 0, 0, time.UTC),
+		time.Date(2024, time.March, 18, 12, 0, 0, 0, time.UTC),
+	}
+	for i, repayment := range loanDetails.Repayments {
+		if !repayment.DueDate.Equal(expected[i]) {
+			t.Errorf("expected due date %d to be %v but got %v", i+1, expected[i], repayment.DueDate)
+		}
+		if !repayment.Amount.Equal(decimal.NewFromInt(100)) {
+			t.Errorf("expected amount of repayment %d to be unchanged but got %v", i+1, repayment.Amount)
+		}
+	}
+}
+
+func TestGenerateInstallments(t *testing.T) {
+	principal := decimal.NewFromInt(1200)
+	periodRate := decimal.RequireFromString("0.01")
+	round := getRoundingFunc(2)
+
+	t.Run("flat", func(t *testing.T) {
+		installments := generateInstallments(principal, periodRate, responseDto.InterestMethodFlat, 12, round)
+		for _, installment := range installments {
+			if !installment.principal.Equal(decimal.NewFromInt(100)) || !installment.interest.Equal(decimal.NewFromInt(12)) {
+				t.Errorf("unexpected installment %v %v", installment.principal, installment.interest)
+			}
+		}
+	})
+
+	t.Run("declining balance", func(t *testing.T) {
+		installments := generateInstallments(principal, periodRate, responseDto.InterestMethodDecliningBalance, 12, round)
+		totalPrincipal := decimal.Zero
+		for i, installment := range installments {
+			totalPrincipal = totalPrincipal.Add(installment.principal)
+			// equated installment of 106.62, the last installment repays the outstanding principal
+			amount := installment.principal.Add(installment.interest)
+			if i < 11 && !amount.Equal(decimal.RequireFromString("106.62")) {
+				t.Errorf("unexpected amount %v of installment %d", amount, i+1)
+			}
+			if i > 0 && !installment.interest.LessThan(installments[i-1].interest) {
+				t.Errorf("interest of installment %d doesn't decline", i+1)
+			}
+		}
+		if !installments[0].interest.Equal(decimal.NewFromInt(12)) {
+			t.Errorf("expected interest 12 on the first installment but got %v", installments[0].interest)
+		}
+		if !totalPrincipal.Equal(principal) {
+			t.Errorf("expected total principal %v but got %v", principal, totalPrincipal)
+		}
+	})
+
+	t.Run("declining balance without interest", func(t *testing.T) {
+		installments := generateInstallments(principal, decimal.Zero, responseDto.InterestMethodDecliningBalance, 12, round)
+		for _, installment := range installments {
+			if !installment.principal.Equal(decimal.NewFromInt(100)) || !installment.interest.IsZero() {
+				t.Errorf("unexpected installment %v %v", installment.principal, installment.interest)
+			}
+		}
+	})
+
+	t.Run("interest only", func(t *testing.T) {
+		installments := generateInstallments(principal, periodRate, responseDto.InterestMethodInterestOnly, 12, round)
+		for i, installment := range installments {
+			expectedPrincipal := decimal.Zero
+			if i == 11 {
+				expectedPrincipal = principal
+			}
+			if !installment.principal.Equal(expectedPrincipal) || !installment.interest.Equal(decimal.NewFromInt(12)) {
+				t.Errorf("unexpected installment %d %v %v", i+1, installment.principal, installment.interest)
+			}
+		}
+	})
+}
+
+func TestGenerateInstallments_Rounding(t *testing.T) {
+	t.Cleanup(func() { InstallmentRoundingMode = ROUNDING_MODE_HALF_UP })
+
+	tests := []struct {
+		roundingMode string
+		minorUnits   int32
+		principal    string
+		term         int
+		expected     []string
+	}{
+		{ROUNDING_MODE_HALF_UP, 2, "100000", 3, []string{"33333.33", "33333.33", "33333.34"}},
+		{ROUNDING_MODE_HALF_UP, 2, "200", 3, []string{"66.67", "66.67", "66.66"}},
+		{ROUNDING_MODE_DOWN, 2, "200", 3, []string{"66.66", "66.66", "66.68"}},
+		{ROUNDING_MODE_UP, 2, "100", 3, []string{"33.34", "33.34", "33.32"}},
+		{ROUNDING_MODE_HALF_EVEN, 2, "0.25", 2, []string{"0.12", "0.13"}},
+		{ROUNDING_MODE_HALF_UP, 0, "1000", 3, []string{"333", "333", "334"}},
+	}
+	for _, test := range tests {
+		InstallmentRoundingMode = test.roundingMode
+		installments := generateInstallments(decimal.RequireFromString(test.principal), decimal.Zero,
+			responseDto.InterestMethodFlat, test.term, getRoundingFunc(test.minorUnits))
+
+		total := decimal.Zero
+		for i, installment := range installments {
+			total = total.Add(installment.principal)
+			if !installment.principal.Equal(decimal.RequireFromString(test.expected[i])) {
+				t.Errorf("%s %s/%d: expected installment %d to be %s but got %v", test.roundingMode, test.principal,
+					test.term, i+1, test.expected[i], installment.principal)
+			}
+		}
+		if !total.Equal(decimal.RequireFromString(test.principal)) {
+			t.Errorf("%s %s/%d: installments sum up to %v", test.roundingMode, test.principal, test.term, total)
+		}
+	}
+}
+
+func TestCalculateApr(t *testing.T) {
+	principal := decimal.NewFromInt(1200)
+	round := getRoundingFunc(2)
+	toRepayments := func(installments []*installment, fee decimal.Decimal) []*responseDto.RepaymentDetails {
+		repayments := make([]*responseDto.RepaymentDetails, len(installments))
+		for i, installment := range installments {
+			repayments[i] = &responseDto.RepaymentDetails{Amount: installment.principal.Add(installment.interest)}
+		}
+		repayments[0].Amount = repayments[0].Amount.Add(fee)
+		return repayments
+	}
+
+	tests := []struct {
+		name           string
+		interestMethod string
+		periodRate     string
+		fee            string
+		expected       string
+	}{
+		{"without interest", responseDto.InterestMethodFlat, "0", "0", "0"},
+		{"declining balance", responseDto.InterestMethodDecliningBalance, "0.01", "0", "12"},
+		{"flat", responseDto.InterestMethodFlat, "0.01", "0", "21.46"},
+		{"fee", responseDto.InterestMethodDecliningBalance, "0.01", "12", "13.89"},
+	}
+	for _, test := range tests {
+		installments := generateInstallments(principal, decimal.RequireFromString(test.periodRate), test.interestMethod,
+			12, round)
+		apr := calculateApr(principal, toRepayments(installments, decimal.RequireFromString(test.fee)),
+			responseDto.RepaymentFrequencyMonthly)
+		if !apr.Equal(decimal.RequireFromString(test.expected)) {
+			t.Errorf("%s: expected apr %s but got %v", test.name, test.expected, apr)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/s8sg/mini-loan-app/app/app_errors"
 	"github.com/s8sg/mini-loan-app/app/controller/dto"
@@ -15,89 +16,361 @@ import (
 )
 
 var (
-	// RepaymentFrequency is not fixed to 1 week
-	RepaymentFrequency = (time.Hour * 24) * 7
+	// LoanCoolingOffPeriod is the time after the approval in which a customer can cancel a loan without repayments
+	LoanCoolingOffPeriod = time.Hour * 24 * 14
 )
 
 var (
-	loanInvalidStatus    = &app_errors.AppError{Code: 400, Message: "loan invalid status"}
-	loanNotPresent       = &app_errors.AppError{Code: 404, Message: "loan not found"}
-	loanAmountNotPresent = &app_errors.AppError{Code: 400, Message: "loan amount must be provided"}
-	loanTermInvalid      = &app_errors.AppError{Code: 400, Message: "loan term can;t be less than 1"}
-	invalidLoanId        = &app_errors.AppError{Code: 400, Message: "invalid loan id"}
+	loanInvalidStatus     = &app_errors.AppError{Code: 400, Message: "loan invalid status"}
+	loanNotPresent        = &app_errors.AppError{Code: 404, Message: "loan not found"}
+	loanAmountNotPresent  = &app_errors.AppError{Code: 400, Message: "loan amount must be provided"}
+	loanTermInvalid       = &app_errors.AppError{Code: 400, Message: "loan term can;t be less than 1"}
+	invalidLoanId         = &app_errors.AppError{Code: 400, Message: "invalid loan id"}
+	interestRateInvalid   = &app_errors.AppError{Code: 400, Message: "interest rate can't be negative"}
+	interestMethodInvalid = &app_errors.AppError{Code: 400,
+		Message: "interest method must be FLAT, DECLINING_BALANCE or INTEREST_ONLY"}
+	frequencyInvalid = &app_errors.AppError{Code: 400,
+		Message: "repayment frequency must be WEEKLY, BI_WEEKLY, SEMI_MONTHLY or MONTHLY"}
+	currencyNotSupported  = &app_errors.AppError{Code: 400, Message: "currency is not supported"}
+	loanAmountInvalid     = &app_errors.AppError{Code: 400, Message: "loan amount can't have fractions of the minor unit of the currency"}
+	loanAmountTooSmall    = &app_errors.AppError{Code: 400, Message: "loan amount is too small for the term"}
+	loanTermsSetByProduct = &app_errors.AppError{Code: 400,
+		Message: "currency, interest and repayment frequency are set by the loan product"}
+	loanAmountOutOfRange   = &app_errors.AppError{Code: 400, Message: "loan amount is outside of the range of the loan product"}
+	loanTermNotOffered     = &app_errors.AppError{Code: 400, Message: "loan term is not offered by the loan product"}
+	customerNotEligible    = &app_errors.AppError{Code: 403, Message: "customer is not eligible for the loan product"}
+	rejectionReasonInvalid = &app_errors.AppError{Code: 400,
+		Message: "reason must be CREDIT_HISTORY, INSUFFICIENT_INCOME, INCOMPLETE_APPLICATION, POLICY or OTHER"}
+	rejectionNotesInvalid = &app_errors.AppError{Code: 400,
+		Message: "notes must be provided for reason OTHER and can't be longer than 1000 characters"}
+	loanCancellationNotAllowed = &app_errors.AppError{Code: 400,
+		Message: "loan can only be cancelled within the cooling-off period if nothing has been repaid"}
 )
 
 type LoanService interface {
 	CreateLoan(customerId string, loanCreateRequest *dto.LoanCreateRequest) (*responseDto.LoanDetails, error)
+	QuoteLoan(customerId string, loanCreateRequest *dto.LoanCreateRequest) (*responseDto.LoanQuoteDetails, error)
 	GetAllLoansForCustomer(customerId string) ([]*responseDto.LoanDetails, error)
-	ApproveLoan(loanApproveRequest *dto.LoanApproveRequest) error
+	ApproveLoan(authContext *AuthContext, loanApproveRequest *dto.LoanApproveRequest) error
+	RejectLoan(authContext *AuthContext, loanRejectRequest *dto.LoanRejectRequest) error
+	RespondToLoanOffer(customerId string, loanOfferDecisionRequest *dto.LoanOfferDecisionRequest) error
+	CancelLoan(customerId string, loanCancelRequest *dto.LoanCancelRequest) error
+	GetLoanStatusHistory(customerId string, loanId string) ([]*responseDto.LoanStatusHistory, error)
+	GetLoanApprovals(loanId string) ([]*responseDto.LoanApprovalDetails, error)
+	ExpireStaleLoans() (int, error)
 }
 
 type LoanServiceImplementation struct {
-	repo repository.LoanRepository
+	repo         repository.LoanRepository
+	customerRepo repository.CustomerRepository
+	productRepo  repository.LoanProductRepository
+	userRepo     repository.UserRepository
+	// quoteSigningKey signs the quote ids, quotes are not stored
+	quoteSigningKey []byte
 }
 
-// GetLoanService : Initialise loan-service, uses dependency loanRepository
-func GetLoanService(loanRepository repository.LoanRepository) LoanService {
+// GetLoanService : Initialise loan-service, uses dependency loanRepository, customerRepository, loanProductRepository
+// and userRepository, quotes are signed with the quoteSigningKey
+func GetLoanService(loanRepository repository.LoanRepository, customerRepository repository.CustomerRepository,
+	loanProductRepository repository.LoanProductRepository, userRepository repository.UserRepository,
+	quoteSigningKey string) LoanService {
 	loanServiceImpl := &LoanServiceImplementation{
-		repo: loanRepository,
+		repo:            loanRepository,
+		customerRepo:    customerRepository,
+		productRepo:     loanProductRepository,
+		userRepo:        userRepository,
+		quoteSigningKey: []byte(quoteSigningKey),
 	}
 	return loanServiceImpl
 }
 
+// loanTerms : currency, interest and repayment frequency of a loan, set by the loan product or the request
+type loanTerms struct {
+	// quoteId is the id of the quote the terms are quoted by, empty for terms that are not quoted
+	quoteId            string
+	productId          string
+	productVersion     int
+	currency           string
+	interestRate       decimal.Decimal
+	interestMethod     string
+	frequency          string
+	originationFeeRate decimal.Decimal
+}
+
+// CreateLoan : creates the loan with the terms of the request, or the terms of a quote for requests with a quote id
 func (l LoanServiceImplementation) CreateLoan(customerId string,
 	loanCreateRequest *dto.LoanCreateRequest) (*responseDto.LoanDetails, error) {
+	var quotedTerms *loanTerms
+	if loanCreateRequest.QuoteId != "" {
+		quotedRequest, terms, err := l.parseLoanQuote(customerId, loanCreateRequest)
+		if err != nil {
+			return nil, err
+		}
+		loanCreateRequest, quotedTerms = quotedRequest, terms
+	}
+
+	loanDetails, _, err := l.buildLoan(customerId, loanCreateRequest, quotedTerms)
+	if err != nil {
+		return nil, err
+	}
+
+	actor := getCustomerLoanActor(customerId)
+	err = checkLoanTransition("", loanDetails.Status, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	loanDetails.LoanId = util.GenerateLoanID()
+	for _, repayment := range loanDetails.Repayments {
+		repayment.RepaymentId = util.GenerateRepaymentID()
+	}
+
+	quoteId := ""
+	if quotedTerms != nil {
+		quoteId = quotedTerms.quoteId
+	}
+
+	history := newLoanStatusHistory(loanDetails.LoanId, "", loanDetails.Status, actor, "")
+	loanDetails, err = l.repo.CreateLoan(loanDetails, history, quoteId)
+	if err != nil {
+		if quoteId != "" && isUniqueViolation(err) {
+			log.Printf("quote %s is already used\n", quoteId)
+			return nil, loanQuoteAlreadyUsed
+		}
+		log.Printf("failed to create loan, error %v\n", err)
+		return nil, app_errors.InternalServerError
+	}
+
+	return loanDetails, nil
+}
+
+// buildLoan : validates the request and generates the repayment schedule without persisting the loan,
+// responds with the terms of the loan. quotedTerms are used instead of the current terms of the loan product
+func (l LoanServiceImplementation) buildLoan(customerId string, loanCreateRequest *dto.LoanCreateRequest,
+	quotedTerms *loanTerms) (*responseDto.LoanDetails, *loanTerms, error) {
 
 	// validate amount
 	if loanCreateRequest.Amount == 0 {
 		log.Printf("loan must be present")
-		return nil, loanAmountNotPresent
+		return nil, nil, loanAmountNotPresent
 	}
 
 	// validate term
 	if loanCreateRequest.Term < 1 {
 		log.Printf("term must be provide and should be greater than 1")
-		return nil, loanTermInvalid
+		return nil, nil, loanTermInvalid
+	}
+
+	// validate the loan product, the product sets the terms of the loan
+	var loanProduct *responseDto.LoanProductDetails
+	var err error
+	if loanCreateRequest.ProductId != "" {
+		loanProduct, err = l.getLoanProduct(loanCreateRequest.ProductId)
+		if err != nil {
+			return nil, nil, err
+		}
+		// quoted terms are honored until the quote expires, even if the product is no longer offered
+		if quotedTerms == nil && loanProduct.Status != responseDto.LoanProductStatusActive {
+			log.Printf("loan product %s is not active\n", loanProduct.ProductId)
+			return nil, nil, loanProductNotFound
+		}
 	}
 
-	// create loan details
+	terms := quotedTerms
+	if terms == nil {
+		terms, err = getLoanTerms(loanCreateRequest, loanProduct)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// validate currency, the amount can't have fractions of the minor unit
+	minorUnits, ok := util.GetCurrencyMinorUnits(terms.currency)
+	if !ok {
+		log.Printf("currency %s is not supported", terms.currency)
+		return nil, nil, currencyNotSupported
+	}
+	amount := decimal.NewFromFloat(loanCreateRequest.Amount)
+	if !amount.Equal(amount.Truncate(minorUnits)) {
+		log.Printf("loan amount %v has fractions of the minor unit of %s", amount, terms.currency)
+		return nil, nil, loanAmountInvalid
+	}
+
+	if loanProduct != nil {
+		// quoted loans are checked against the version of the product they were quoted at
+		productTerms := loanProduct
+		if quotedTerms != nil {
+			productTerms, err = l.getQuotedLoanProduct(quotedTerms)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		err = checkProductAmountAndTerm(productTerms, amount, loanCreateRequest.Term)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// validate customer exists and is allowed to take a loan
+	customerDetails, err := l.customerRepo.GetCustomerById(customerId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("customer %s not found\n", customerId)
+			return nil, nil, customerNotFound
+		}
+		log.Printf("failed to get customer %s, error %v\n", customerId, err)
+		return nil, nil, app_errors.InternalServerError
+	}
+
+	if customerDetails.Status != responseDto.CustomerStatusActive {
+		log.Printf("customer %s is not active\n", customerId)
+		return nil, nil, customerDisabled
+	}
+
+	if loanProduct != nil {
+		err = l.checkEligibility(customerDetails, loanProduct)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	round := getRoundingFunc(minorUnits)
+
+	// create loan details, the terms are a snapshot of the loan product at origination
 	loanDetails := &responseDto.LoanDetails{
-		LoanId:           util.GenerateLoanID(),
-		TotalAmount:      decimal.NewFromFloat(loanCreateRequest.Amount),
+		ProductId:        terms.productId,
+		ProductVersion:   terms.productVersion,
+		TotalAmount:      amount,
+		Currency:         terms.currency,
+		InterestRate:     terms.interestRate,
+		InterestMethod:   terms.interestMethod,
+		OriginationFee:   round(amount.Mul(terms.originationFeeRate).Div(hundred)),
 		CustomerId:       customerId,
 		Term:             loanCreateRequest.Term,
+		Frequency:        terms.frequency,
 		StartDate:        util.GetCurrentTimeInUtc(),
-		Repayments:       make([]*responseDto.RepaymentDetails, loanCreateRequest.Term),
 		Status:           responseDto.LoanStatusPending,
 		CreatedTimestamp: util.GetCurrentTimeInUtc(),
 		UpdatedTimestamp: util.GetCurrentTimeInUtc(),
 	}
+	loanDetails.Repayments, err = generateRepayments(loanDetails, round)
+	if err != nil {
+		return nil, nil, err
+	}
+	loanDetails.UpdateOutstanding()
 
-	// generate repayment details
-	repaymentAmountPerTenure := loanDetails.TotalAmount.Div(decimal.NewFromInt32(int32(loanDetails.Term)))
-	nextDueDate := loanDetails.StartDate
-	for i := 0; i < loanDetails.Term; i++ {
-		nextDueDate = nextDueDate.Add(RepaymentFrequency)
-		repayment := &responseDto.RepaymentDetails{
-			RepaymentId:      util.GenerateRepaymentID(),
-			Number:           i + 1,
-			Amount:           repaymentAmountPerTenure,
-			DueDate:          nextDueDate,
-			Status:           responseDto.RepaymentStatusPending,
-			CreatedTimestamp: util.GetCurrentTimeInUtc(),
-			UpdatedTimestamp: util.GetCurrentTimeInUtc(),
+	return loanDetails, terms, nil
+}
+
+// getLoanProduct : loan product the customer applies for
+func (l LoanServiceImplementation) getLoanProduct(productId string) (*responseDto.LoanProductDetails, error) {
+	loanProduct, err := l.productRepo.GetLoanProductById(productId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("loan product %s not found\n", productId)
+			return nil, loanProductNotFound
 		}
+		log.Printf("failed to get loan product %s, error %v\n", productId, err)
+		return nil, app_errors.InternalServerError
+	}
+	return loanProduct, nil
+}
 
-		loanDetails.Repayments[i] = repayment
+// checkProductAmountAndTerm : validates the amount is in the range of the loan product and the term is offered by it
+func checkProductAmountAndTerm(loanProduct *responseDto.LoanProductDetails, amount decimal.Decimal, term int) error {
+	if amount.LessThan(loanProduct.MinAmount) || amount.GreaterThan(loanProduct.MaxAmount) {
+		log.Printf("loan amount %v is outside of the range of product %s", amount, loanProduct.ProductId)
+		return loanAmountOutOfRange
 	}
+	if !loanProduct.OffersTerm(term) {
+		log.Printf("term %d is not offered by product %s", term, loanProduct.ProductId)
+		return loanTermNotOffered
+	}
+	return nil
+}
 
-	loanDetails, err := l.repo.CreateLoan(loanDetails)
-	if err != nil {
-		log.Printf("failed to create loan, error %v\n", err)
-		return nil, app_errors.InternalServerError
+// checkEligibility : validates the eligibility rules of the loan product for the customer
+func (l LoanServiceImplementation) checkEligibility(customerDetails *responseDto.CustomerDetails,
+	loanProduct *responseDto.LoanProductDetails) error {
+	customerSince := customerDetails.CreatedTimestamp.AddDate(0, 0, loanProduct.MinCustomerDays)
+	if util.GetCurrentTimeInUtc().Before(customerSince) {
+		log.Printf("customer %s is a customer for less than %d days\n", customerDetails.CustomerId,
+			loanProduct.MinCustomerDays)
+		return customerNotEligible
 	}
 
-	return loanDetails, nil
+	if loanProduct.MaxOpenLoans > 0 {
+		openLoans, err := l.repo.CountLoansByCustomerId(customerDetails.CustomerId,
+			[]string{responseDto.LoanStatusPending, responseDto.LoanStatusOffered, responseDto.LoanStatusApproved})
+		if err != nil {
+			log.Printf("failed to count loans of customer %s, error %v\n", customerDetails.CustomerId, err)
+			return app_errors.InternalServerError
+		}
+		if openLoans >= loanProduct.MaxOpenLoans {
+			log.Printf("customer %s has %d open loans\n", customerDetails.CustomerId, openLoans)
+			return customerNotEligible
+		}
+	}
+	return nil
+}
+
+// getLoanTerms : terms of the loan product, or the terms of the request for loans without a product
+func getLoanTerms(loanCreateRequest *dto.LoanCreateRequest, loanProduct *responseDto.LoanProductDetails) (*loanTerms, error) {
+	if loanProduct != nil {
+		if loanCreateRequest.Currency != "" || loanCreateRequest.InterestRate != 0 ||
+			loanCreateRequest.InterestMethod != "" || loanCreateRequest.Frequency != "" {
+			log.Printf("loan terms are provided for product %s", loanProduct.ProductId)
+			return nil, loanTermsSetByProduct
+		}
+		return &loanTerms{
+			productId:          loanProduct.ProductId,
+			productVersion:     loanProduct.Version,
+			currency:           loanProduct.Currency,
+			interestRate:       loanProduct.InterestRate,
+			interestMethod:     loanProduct.InterestMethod,
+			frequency:          loanProduct.Frequency,
+			originationFeeRate: loanProduct.OriginationFeeRate,
+		}, nil
+	}
+
+	// validate repayment frequency
+	frequency := loanCreateRequest.Frequency
+	if frequency == "" {
+		frequency = responseDto.RepaymentFrequencyWeekly
+	}
+	if !isValidRepaymentFrequency(frequency) {
+		log.Printf("repayment frequency %s is invalid", frequency)
+		return nil, frequencyInvalid
+	}
+
+	currency := loanCreateRequest.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+
+	// validate interest
+	if loanCreateRequest.InterestRate < 0 {
+		log.Printf("interest rate %v is negative", loanCreateRequest.InterestRate)
+		return nil, interestRateInvalid
+	}
+
+	interestMethod := loanCreateRequest.InterestMethod
+	switch interestMethod {
+	case "":
+		interestMethod = responseDto.InterestMethodFlat
+	case responseDto.InterestMethodFlat, responseDto.InterestMethodDecliningBalance, responseDto.InterestMethodInterestOnly:
+	default:
+		log.Printf("interest method %s is invalid", interestMethod)
+		return nil, interestMethodInvalid
+	}
+
+	return &loanTerms{
+		currency:           currency,
+		interestRate:       decimal.NewFromFloat(loanCreateRequest.InterestRate),
+		interestMethod:     interestMethod,
+		frequency:          frequency,
+		originationFeeRate: decimal.Zero,
+	}, nil
 }
 
 func (l LoanServiceImplementation) GetAllLoansForCustomer(customerId string) ([]*responseDto.LoanDetails, error) {
@@ -109,7 +382,11 @@ func (l LoanServiceImplementation) GetAllLoansForCustomer(customerId string) ([]
 	return loanDetails, nil
 }
 
-func (l LoanServiceImplementation) ApproveLoan(loanApproveRequest *dto.LoanApproveRequest) error {
+// ApproveLoan : approves a pending loan, the approval is recorded with the staff user or api key approving it.
+// Admins can only approve or counter-offer loans within their approval limit.
+// Loans above the approval threshold are approved once the quorum of distinct admins approved them.
+// With adjusted terms the repayment schedule is regenerated and the loan is offered to the customer instead
+func (l LoanServiceImplementation) ApproveLoan(authContext *AuthContext, loanApproveRequest *dto.LoanApproveRequest) error {
 	loanId := loanApproveRequest.LoanId
 
 	// validate loanId
@@ -118,6 +395,151 @@ func (l LoanServiceImplementation) ApproveLoan(loanApproveRequest *dto.LoanAppro
 		return invalidLoanId
 	}
 
+	err := validateCounterOffer(loanApproveRequest)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	txOption := &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+	}
+
+	tx, err := l.repo.CreateTransaction(ctx, txOption)
+	if err != nil {
+		log.Println("failed to initiate transaction")
+		return app_errors.InternalServerError
+	}
+
+	defer func() {
+		if err != nil {
+			log.Println("calling rollback for error " + err.Error())
+			_ = tx.Rollback()
+			return
+		}
+		_ = tx.Commit()
+	}()
+
+	loanDetails, err := l.repo.GetLoanById(loanId, tx)
+	if err != nil {
+		if isSerializationFailure(err) {
+			log.Printf("loan %s is updated concurrently\n", loanId)
+			return loanUpdatedConcurrently
+		}
+		log.Println("loan can not be fetched")
+		return loanNotPresent
+	}
+
+	actor := getLoanActor(authContext)
+	err = checkApprover(loanDetails, actor)
+	if err != nil {
+		return err
+	}
+
+	if isCounterOffer(loanApproveRequest) {
+		err = checkLoanTransition(loanDetails.Status, responseDto.LoanStatusOffered, actor)
+		if err != nil {
+			return err
+		}
+
+		var offer string
+		offer, err = applyCounterOffer(loanDetails, loanApproveRequest)
+		if err != nil {
+			return err
+		}
+		err = l.checkCounterOfferProduct(loanDetails)
+		if err != nil {
+			return err
+		}
+		// the customer accepting the offer approves the loan, offers can't bypass the quorum
+		if getRequiredApprovals(loanDetails.TotalAmount) > 1 {
+			log.Printf("loan %s above the approval threshold can't be counter-offered\n", loanId)
+			err = fmt.Errorf("loan can not be counter-offered")
+			return counterOfferAboveThreshold
+		}
+		err = l.checkApprovalLimit(actor, loanDetails.TotalAmount)
+		if err != nil {
+			return err
+		}
+
+		err = l.repo.OfferLoan(loanDetails, tx)
+		if err != nil {
+			log.Printf("failed to offer loan for loanId %s, error %v\n", loanId, err)
+			return app_errors.InternalServerError
+		}
+
+		err = recordLoanTransition(l.repo, loanDetails, responseDto.LoanStatusOffered, actor, offer, tx)
+		return err
+	}
+
+	err = checkLoanTransition(loanDetails.Status, responseDto.LoanStatusApproved, actor)
+	if err != nil {
+		return err
+	}
+
+	err = l.checkApprovalLimit(actor, loanDetails.TotalAmount)
+	if err != nil {
+		return err
+	}
+
+	approverIds, err := recordLoanApproval(l.repo, loanDetails, actor, tx)
+	if err != nil || approverIds == nil {
+		return err
+	}
+
+	err = approveLoan(l.repo, loanDetails, tx)
+	if err != nil {
+		return err
+	}
+
+	err = recordLoanTransition(l.repo, loanDetails, responseDto.LoanStatusApproved, actor,
+		getApprovalReason(approverIds), tx)
+	return err
+}
+
+// GetLoanApprovals : responds with the approvals of the loan, loans above the approval threshold list the partial
+// approvals until the quorum is reached
+func (l LoanServiceImplementation) GetLoanApprovals(loanId string) ([]*responseDto.LoanApprovalDetails, error) {
+	if loanId == "" {
+		log.Println("loan id not specified")
+		return nil, invalidLoanId
+	}
+
+	approvals, err := l.repo.GetLoanApprovals(loanId)
+	if err != nil {
+		log.Printf("failed to get approvals of loan %s, error %v\n", loanId, err)
+		return nil, app_errors.InternalServerError
+	}
+	return approvals, nil
+}
+
+// RejectLoan : rejects a pending loan with the reason shown to the customer
+func (l LoanServiceImplementation) RejectLoan(authContext *AuthContext, loanRejectRequest *dto.LoanRejectRequest) error {
+	loanId := loanRejectRequest.LoanId
+
+	// validate loanId
+	if loanId == "" {
+		log.Println("loan id not specified")
+		return invalidLoanId
+	}
+
+	// validate reason
+	switch loanRejectRequest.Reason {
+	case responseDto.LoanRejectionReasonCreditHistory, responseDto.LoanRejectionReasonInsufficientIncome,
+		responseDto.LoanRejectionReasonIncompleteApplication, responseDto.LoanRejectionReasonPolicy,
+		responseDto.LoanRejectionReasonOther:
+	default:
+		log.Printf("rejection reason %s is invalid", loanRejectRequest.Reason)
+		return rejectionReasonInvalid
+	}
+	if len(loanRejectRequest.Notes) > 1000 ||
+		(loanRejectRequest.Reason == responseDto.LoanRejectionReasonOther && loanRejectRequest.Notes == "") {
+		log.Println("rejection notes invalid")
+		return rejectionNotesInvalid
+	}
+
 	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
 	defer cancelFunc()
 
@@ -146,16 +568,116 @@ func (l LoanServiceImplementation) ApproveLoan(loanApproveRequest *dto.LoanAppro
 		return loanNotPresent
 	}
 
-	if loanDetails.Status != responseDto.LoanStatusPending {
-		log.Println("loan can not be approved, invalid status")
-		err = fmt.Errorf("loan can not be approved, invalid status")
-		return loanInvalidStatus
+	actor := getLoanActor(authContext)
+	err = checkLoanTransition(loanDetails.Status, responseDto.LoanStatusRejected, actor)
+	if err != nil {
+		return err
 	}
 
-	err = l.repo.UpdateLoanStatus(loanId, responseDto.LoanStatusApproved, tx)
+	err = l.repo.RejectLoan(loanId, loanRejectRequest.Reason, loanRejectRequest.Notes, tx)
 	if err != nil {
-		log.Printf("failed to approve loan for loanId %s, error %v\n", loanId, err)
+		log.Printf("failed to reject loan for loanId %s, error %v\n", loanId, err)
 		return app_errors.InternalServerError
 	}
-	return nil
+
+	err = recordLoanTransition(l.repo, loanDetails, responseDto.LoanStatusRejected, actor, loanRejectRequest.Reason, tx)
+	return err
+}
+
+// CancelLoan : cancels a pending loan of the customer, or an approved loan within the cooling-off period
+// if nothing has been repaid
+func (l LoanServiceImplementation) CancelLoan(customerId string, loanCancelRequest *dto.LoanCancelRequest) error {
+	loanId := loanCancelRequest.LoanId
+
+	// validate loanId
+	if loanId == "" {
+		log.Println("loan id not specified")
+		return invalidLoanId
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	txOption := &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+	}
+
+	tx, err := l.repo.CreateTransaction(ctx, txOption)
+	if err != nil {
+		log.Println("failed to initiate transaction")
+		return app_errors.InternalServerError
+	}
+
+	defer func() {
+		if err != nil {
+			log.Println("calling rollback for error " + err.Error())
+			_ = tx.Rollback()
+			return
+		}
+		_ = tx.Commit()
+	}()
+
+	loanDetails, err := l.repo.GetLoanById(loanId, tx)
+	if err != nil {
+		log.Println("loan can not be fetched")
+		return loanNotPresent
+	}
+
+	// check if loan belongs to customer
+	if loanDetails.CustomerId != customerId {
+		log.Println("loan doesn't belongs to customer")
+		err = fmt.Errorf("loan doesn't belongs to customer")
+		return loanNotPresent
+	}
+
+	actor := getCustomerLoanActor(customerId)
+	err = checkLoanTransition(loanDetails.Status, responseDto.LoanStatusCancelled, actor)
+	if err != nil {
+		return err
+	}
+
+	// approved loans can only be cancelled within the cooling-off period
+	if loanDetails.Status == responseDto.LoanStatusApproved {
+		// loans approved before the approval time was recorded fall back to the last update
+		coolingOffEnd := loanDetails.UpdatedTimestamp.Add(LoanCoolingOffPeriod)
+		if loanDetails.ApprovedAt != nil {
+			coolingOffEnd = loanDetails.ApprovedAt.Add(LoanCoolingOffPeriod)
+		}
+		if util.GetCurrentTimeInUtc().After(coolingOffEnd) || getRepaidRepaymentCount(loanDetails) > 0 {
+			log.Printf("loan %s can't be cancelled after the cooling-off period or once repaid\n", loanId)
+			err = fmt.Errorf("loan can not be cancelled")
+			return loanCancellationNotAllowed
+		}
+	}
+
+	err = l.repo.UpdateLoanStatus(loanId, responseDto.LoanStatusCancelled, tx)
+	if err != nil {
+		log.Printf("failed to cancel loan for loanId %s, error %v\n", loanId, err)
+		return app_errors.InternalServerError
+	}
+
+	err = recordLoanTransition(l.repo, loanDetails, responseDto.LoanStatusCancelled, actor, "", tx)
+	return err
+}
+
+// GetLoanStatusHistory : responds with the status transitions of the loan, customerId restricts the history
+// to the loans of the customer and is empty for staff
+func (l LoanServiceImplementation) GetLoanStatusHistory(customerId string,
+	loanId string) ([]*responseDto.LoanStatusHistory, error) {
+	if loanId == "" {
+		log.Println("loan id not specified")
+		return nil, invalidLoanId
+	}
+
+	historyList, err := l.repo.GetLoanStatusHistory(loanId, customerId)
+	if err != nil {
+		log.Printf("failed to get status history of loan %s, error %v\n", loanId, err)
+		return nil, app_errors.InternalServerError
+	}
+	// every loan has the entry of its creation
+	if len(historyList) == 0 {
+		log.Printf("loan %s not found for customer %s\n", loanId, customerId)
+		return nil, loanNotPresent
+	}
+	return historyList, nil
 }
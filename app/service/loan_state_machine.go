@@ -0,0 +1,105 @@
+package service
+
+import (
+	"github.com/s8sg/mini-loan-app/app/app_errors"
+	responseDto "github.com/s8sg/mini-loan-app/app/dto"
+	repository "github.com/s8sg/mini-loan-app/app/repostory"
+	"github.com/s8sg/mini-loan-app/app/util"
+	"log"
+)
+
+// loanActorSystem : actor type of the transitions made by the background jobs
+const loanActorSystem = "system"
+
+var (
+	loanTransitionNotAllowed = &app_errors.AppError{Code: 403, Message: "loan status can't be changed by the user"}
+)
+
+// loanTransitions : allowed transitions of the loan status and the login types of the actors performing them,
+// the empty status is the creation of the loan. Statuses without transitions are final
+var loanTransitions = map[string]map[string][]string{
+	"": {
+		responseDto.LoanStatusPending: {USER_TYPE_CUSTOMER},
+	},
+	responseDto.LoanStatusPending: {
+		responseDto.LoanStatusApproved:  {USER_TYPE_ADMIN, USER_TYPE_API_KEY},
+		responseDto.LoanStatusOffered:   {USER_TYPE_ADMIN, USER_TYPE_API_KEY},
+		responseDto.LoanStatusRejected:  {USER_TYPE_ADMIN, USER_TYPE_API_KEY},
+		responseDto.LoanStatusCancelled: {USER_TYPE_CUSTOMER},
+		responseDto.LoanStatusExpired:   {loanActorSystem},
+	},
+	responseDto.LoanStatusOffered: {
+		responseDto.LoanStatusApproved: {USER_TYPE_CUSTOMER},
+		responseDto.LoanStatusDeclined: {USER_TYPE_CUSTOMER},
+	},
+	responseDto.LoanStatusApproved: {
+		responseDto.LoanStatusCancelled: {USER_TYPE_CUSTOMER},
+		responseDto.LoanStatusPaid:      {USER_TYPE_CUSTOMER},
+	},
+}
+
+// loanActor : user performing a transition of the loan status, actorType is the login type of the user
+type loanActor struct {
+	id        string
+	actorType string
+	// roles of the staff user, api keys have the api key role
+	roles []string
+}
+
+func getLoanActor(authContext *AuthContext) *loanActor {
+	roles := authContext.Roles
+	if len(roles) == 0 {
+		roles = []string{authContext.Role}
+	}
+	return &loanActor{id: authContext.UserId, actorType: authContext.Role, roles: roles}
+}
+
+func getCustomerLoanActor(customerId string) *loanActor {
+	return &loanActor{id: customerId, actorType: USER_TYPE_CUSTOMER}
+}
+
+func getSystemLoanActor(job string) *loanActor {
+	return &loanActor{id: job, actorType: loanActorSystem}
+}
+
+// checkLoanTransition : validates that the actor can move the loan from the status to the next status
+func checkLoanTransition(status string, nextStatus string, actor *loanActor) error {
+	actorTypes, ok := loanTransitions[status][nextStatus]
+	if !ok {
+		log.Printf("loan status can't change from %s to %s\n", status, nextStatus)
+		return loanInvalidStatus
+	}
+	for _, actorType := range actorTypes {
+		if actorType == actor.actorType {
+			return nil
+		}
+	}
+	log.Printf("loan status can't change from %s to %s by %s %s\n", status, nextStatus, actor.actorType, actor.id)
+	return loanTransitionNotAllowed
+}
+
+func newLoanStatusHistory(loanId string, status string, nextStatus string, actor *loanActor,
+	reason string) *responseDto.LoanStatusHistory {
+	return &responseDto.LoanStatusHistory{
+		LoanId:           loanId,
+		FromStatus:       status,
+		ToStatus:         nextStatus,
+		ActorId:          actor.id,
+		ActorType:        actor.actorType,
+		Reason:           reason,
+		CreatedTimestamp: util.GetCurrentTimeInUtc(),
+	}
+}
+
+// recordLoanTransition : records the transition in the status history of the loan within the transaction
+// updating the loan status
+func recordLoanTransition(repo repository.LoanRepository, loanDetails *responseDto.LoanDetails, nextStatus string,
+	actor *loanActor, reason string, tx *repository.Transaction) error {
+	history := newLoanStatusHistory(loanDetails.LoanId, loanDetails.Status, nextStatus, actor, reason)
+	err := repo.CreateLoanStatusHistory(history, tx)
+	if err != nil {
+		log.Printf("failed to record status %s of loan %s, error %v\n", nextStatus, loanDetails.LoanId, err)
+		return app_errors.InternalServerError
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+
+	responseDto "github.com/s8sg/mini-loan-app/app/dto"
+)
+
+func TestCheckLoanTransition(t *testing.T) {
+	tests := []struct {
+		status     string
+		nextStatus string
+		actorType  string
+		expected   error
+	}{
+		{"", responseDto.LoanStatusPending, USER_TYPE_CUSTOMER, nil},
+		{responseDto.LoanStatusPending, responseDto.LoanStatusApproved, USER_TYPE_ADMIN, nil},
+		{responseDto.LoanStatusPending, responseDto.LoanStatusApproved, USER_TYPE_API_KEY, nil},
+		{responseDto.LoanStatusPending, responseDto.LoanStatusApproved, USER_TYPE_CUSTOMER, loanTransitionNotAllowed},
+		{responseDto.LoanStatusPending, responseDto.LoanStatusOffered, USER_TYPE_ADMIN, nil},
+		{responseDto.LoanStatusPending, responseDto.LoanStatusOffered, USER_TYPE_CUSTOMER, loanTransitionNotAllowed},
+		{responseDto.LoanStatusOffered, responseDto.LoanStatusApproved, USER_TYPE_CUSTOMER, nil},
+		{responseDto.LoanStatusOffered, responseDto.LoanStatusApproved, USER_TYPE_ADMIN, loanTransitionNotAllowed},
+		{responseDto.LoanStatusOffered, responseDto.LoanStatusDeclined, USER_TYPE_CUSTOMER, nil},
+		{responseDto.LoanStatusDeclined, responseDto.LoanStatusApproved, USER_TYPE_CUSTOMER, loanInvalidStatus},
+		{responseDto.LoanStatusPending, responseDto.LoanStatusRejected, USER_TYPE_ADMIN, nil},
+		{responseDto.LoanStatusPending, responseDto.LoanStatusCancelled, USER_TYPE_CUSTOMER, nil},
+		{responseDto.LoanStatusPending, responseDto.LoanStatusCancelled, USER_TYPE_ADMIN, loanTransitionNotAllowed},
+		{responseDto.LoanStatusPending, responseDto.LoanStatusExpired, loanActorSystem, nil},
+		{responseDto.LoanStatusPending, responseDto.LoanStatusExpired, USER_TYPE_ADMIN, loanTransitionNotAllowed},
+		{responseDto.LoanStatusApproved, responseDto.LoanStatusExpired, loanActorSystem, loanInvalidStatus},
+		{responseDto.LoanStatusPending, responseDto.LoanStatusPaid, USER_TYPE_CUSTOMER, loanInvalidStatus},
+		{responseDto.LoanStatusApproved, responseDto.LoanStatusCancelled, USER_TYPE_CUSTOMER, nil},
+		{responseDto.LoanStatusApproved, responseDto.LoanStatusPaid, USER_TYPE_CUSTOMER, nil},
+		{responseDto.LoanStatusApproved, responseDto.LoanStatusApproved, USER_TYPE_ADMIN, loanInvalidStatus},
+		{responseDto.LoanStatusRejected, responseDto.LoanStatusApproved, USER_TYPE_ADMIN, loanInvalidStatus},
+		{responseDto.LoanStatusCancelled, responseDto.LoanStatusApproved, USER_TYPE_ADMIN, loanInvalidStatus},
+		{responseDto.LoanStatusPaid, responseDto.LoanStatusCancelled, USER_TYPE_CUSTOMER, loanInvalidStatus},
+	}
+	for _, test := range tests {
+		err := checkLoanTransition(test.status, test.nextStatus, &loanActor{id: "user1", actorType: test.actorType})
+		if err != test.expected {
+			t.Errorf("%s -> %s by %s: expected %v but got %v", test.status, test.nextStatus, test.actorType,
+				test.expected, err)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/s8sg/mini-loan-app/app/app_errors"
+	"github.com/s8sg/mini-loan-app/app/dto"
+	"github.com/s8sg/mini-loan-app/app/util"
+	"log"
+	"time"
+)
+
+// loginThrottlePolicy : failed attempts allowed before backoff starts and before the login is locked
+type loginThrottlePolicy struct {
+	scope            string
+	freeAttempts     int
+	lockoutThreshold int
+}
+
+var (
+	// LoginAttemptWindow is the period after which failed attempts are forgotten
+	LoginAttemptWindow = time.Minute * 15
+	// LoginBackoffBase is the delay after the first failure beyond the free attempts, doubled for every further failure
+	LoginBackoffBase = time.Second
+	// LoginBackoffMax caps the exponential backoff
+	LoginBackoffMax = time.Minute * 5
+	// LoginLockoutDuration is how long a login stays locked unless unlocked by an admin
+	LoginLockoutDuration = time.Minute * 30
+
+	// usernames are locked quickly, IPs are shared (e.g. NAT) so they get more attempts
+	usernameThrottlePolicy = &loginThrottlePolicy{scope: dto.LoginAttemptScopeUsername, freeAttempts: 3, lockoutThreshold: 10}
+	ipThrottlePolicy       = &loginThrottlePolicy{scope: dto.LoginAttemptScopeIp, freeAttempts: 50, lockoutThreshold: 200}
+)
+
+var (
+	loginNotLocked       = &app_errors.AppError{Code: 404, Message: "no failed login attempts found"}
+	unlockTargetRequired = &app_errors.AppError{Code: 400, Message: "username or ip must be provided"}
+)
+
+// checkLoginThrottle : rejects the login with 429 while the subject is locked or in backoff
+func (service *AuthServiceImplementation) checkLoginThrottle(policy *loginThrottlePolicy, subject string) error {
+	if subject == "" {
+		return nil
+	}
+
+	loginAttemptDetails, err := service.loginAttemptRepo.GetLoginAttempts(policy.scope, subject)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		log.Printf("failed to fetch login attempts of %s %s, error %v\n", policy.scope, subject, err)
+		return app_errors.InternalServerError
+	}
+
+	now := util.GetCurrentTimeInUtc()
+	if loginAttemptDetails.LockedUntil != nil && loginAttemptDetails.LockedUntil.After(now) {
+		log.Printf("login of %s %s is locked until %v\n", policy.scope, subject, loginAttemptDetails.LockedUntil)
+		return app_errors.TooManyRequests
+	}
+
+	if loginAttemptDetails.LastFailedAt.Before(now.Add(-LoginAttemptWindow)) {
+		return nil
+	}
+
+	retryAt := loginAttemptDetails.LastFailedAt.Add(policy.backoff(loginAttemptDetails.FailedCount))
+	if retryAt.After(now) {
+		log.Printf("login of %s %s is in backoff until %v\n", policy.scope, subject, retryAt)
+		return app_errors.TooManyRequests
+	}
+	return nil
+}
+
+// recordFailedLogin : tracks the failed attempt and locks the subject once the lockout threshold is reached
+func (service *AuthServiceImplementation) recordFailedLogin(policy *loginThrottlePolicy, subject string) {
+	if subject == "" {
+		return
+	}
+
+	now := util.GetCurrentTimeInUtc()
+	loginAttemptDetails, err := service.loginAttemptRepo.RecordFailedLogin(policy.scope, subject, now,
+		now.Add(-LoginAttemptWindow))
+	if err != nil {
+		log.Printf("failed to record failed login of %s %s, error %v\n", policy.scope, subject, err)
+		return
+	}
+
+	if loginAttemptDetails.FailedCount >= policy.lockoutThreshold {
+		log.Printf("locking login of %s %s after %d failed attempts\n", policy.scope, subject,
+			loginAttemptDetails.FailedCount)
+		if err := service.loginAttemptRepo.LockLogin(policy.scope, subject, now.Add(LoginLockoutDuration)); err != nil {
+			log.Printf("failed to lock login of %s %s, error %v\n", policy.scope, subject, err)
+		}
+	}
+}
+
+// resetFailedLogins : clears the failed attempts of the username after a successful login,
+// failures of the IP are kept so a valid account doesn't reset the throttling of credential stuffing
+func (service *AuthServiceImplementation) resetFailedLogins(userid string) {
+	if _, err := service.loginAttemptRepo.ResetLoginAttempts(dto.LoginAttemptScopeUsername, userid); err != nil {
+		log.Printf("failed to reset login attempts of %s, error %v\n", userid, err)
+	}
+}
+
+// UnlockLogin : clears the failed attempts and the lock of the username and/or the ip
+func (service *AuthServiceImplementation) UnlockLogin(userid string, clientIp string) error {
+	if userid == "" && clientIp == "" {
+		return unlockTargetRequired
+	}
+
+	unlocked := false
+	for scope, subject := range map[string]string{
+		dto.LoginAttemptScopeUsername: userid,
+		dto.LoginAttemptScopeIp:       clientIp,
+	} {
+		if subject == "" {
+			continue
+		}
+		found, err := service.loginAttemptRepo.ResetLoginAttempts(scope, subject)
+		if err != nil {
+			log.Printf("failed to reset login attempts of %s %s, error %v\n", scope, subject, err)
+			return app_errors.InternalServerError
+		}
+		unlocked = unlocked || found
+	}
+
+	if !unlocked {
+		return loginNotLocked
+	}
+	return nil
+}
+
+// backoff : exponential delay before the next attempt is allowed, no delay within the free attempts
+func (policy *loginThrottlePolicy) backoff(failedCount int) time.Duration {
+	if failedCount <= policy.freeAttempts {
+		return 0
+	}
+	delay := LoginBackoffBase
+	for i := policy.freeAttempts + 1; i < failedCount; i++ {
+		delay *= 2
+		if delay >= LoginBackoffMax {
+			return LoginBackoffMax
+		}
+	}
+	return delay
+}
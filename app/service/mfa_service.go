@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/s8sg/mini-loan-app/app/app_errors"
+	"github.com/s8sg/mini-loan-app/app/controller/dto"
+	responseDto "github.com/s8sg/mini-loan-app/app/dto"
+	repository "github.com/s8sg/mini-loan-app/app/repostory"
+	"github.com/s8sg/mini-loan-app/app/util"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// MfaIssuer is the issuer shown by the authenticator apps
+	MfaIssuer = "mini-loan-app"
+	// MfaChallengeExpiry is the time to complete the second step of the admin login
+	MfaChallengeExpiry = time.Minute * 5
+	// MfaChallengeMaxAttempts is the number of codes that can be tried for a login challenge
+	MfaChallengeMaxAttempts = 5
+	// RecoveryCodeCount is the number of single use recovery codes issued on activation
+	RecoveryCodeCount = 10
+)
+
+var (
+	MfaRequired = &app_errors.AppError{Code: 403, Message: "multi-factor authentication is required"}
+)
+
+var (
+	mfaAlreadyEnabled   = &app_errors.AppError{Code: 409, Message: "multi-factor authentication is already enabled"}
+	mfaNotEnrolled      = &app_errors.AppError{Code: 400, Message: "multi-factor authentication is not enrolled"}
+	mfaCodeNotProvided  = &app_errors.AppError{Code: 400, Message: "code must be provided"}
+	mfaTokenNotProvided = &app_errors.AppError{Code: 400, Message: "mfa token must be provided"}
+	invalidMfaCode      = &app_errors.AppError{Code: 401, Message: "invalid code"}
+	invalidMfaToken     = &app_errors.AppError{Code: 401, Message: "mfa token is not valid"}
+
+	totpCodePattern = regexp.MustCompile(`^[0-9]{6}$`)
+)
+
+type MfaService interface {
+	Enroll(authContext *AuthContext) (*dto.MfaEnrollResponse, error)
+	Activate(authContext *AuthContext, request *dto.MfaActivateRequest) (*dto.RecoveryCodesResponse, error)
+	RegenerateRecoveryCodes(authContext *AuthContext) (*dto.RecoveryCodesResponse, error)
+	ResetMfa(request *dto.MfaResetRequest) error
+}
+
+type MfaServiceImplementation struct {
+	userRepo      repository.UserRepository
+	mfaRepo       repository.MfaRepository
+	encryptionKey string
+}
+
+// GetMfaService : Initialise mfa-service, uses dependency userRepository and mfaRepository,
+// TOTP secrets are encrypted at rest with the encryptionKey
+func GetMfaService(userRepository repository.UserRepository, mfaRepository repository.MfaRepository,
+	encryptionKey string) MfaService {
+	mfaService := &MfaServiceImplementation{
+		userRepo:      userRepository,
+		mfaRepo:       mfaRepository,
+		encryptionKey: encryptionKey,
+	}
+	return mfaService
+}
+
+// Enroll : generates a new TOTP secret for the admin, MFA is enforced once activated with a code of the secret
+func (m MfaServiceImplementation) Enroll(authContext *AuthContext) (*dto.MfaEnrollResponse, error) {
+	userDetails, err := m.getAdminUser(authContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if userDetails.TotpEnabled {
+		return nil, mfaAlreadyEnabled
+	}
+
+	secret, err := util.GenerateTotpSecret()
+	if err != nil {
+		log.Println("failed to generate totp secret, error ", err)
+		return nil, app_errors.InternalServerError
+	}
+	encryptedSecret, err := util.Encrypt(m.encryptionKey, secret)
+	if err != nil {
+		log.Println("failed to encrypt totp secret, error ", err)
+		return nil, app_errors.InternalServerError
+	}
+
+	err = m.userRepo.SetTotpSecret(userDetails.Username, encryptedSecret)
+	if err != nil {
+		log.Printf("failed to store totp secret of user %s, error %v\n", userDetails.Username, err)
+		return nil, app_errors.InternalServerError
+	}
+
+	return &dto.MfaEnrollResponse{
+		Secret:     secret,
+		OtpauthUri: util.GetTotpUri(MfaIssuer, userDetails.Username, secret),
+	}, nil
+}
+
+// Activate : enables MFA once the admin proves the authenticator is set up, responds with the recovery codes
+func (m MfaServiceImplementation) Activate(authContext *AuthContext,
+	request *dto.MfaActivateRequest) (*dto.RecoveryCodesResponse, error) {
+
+	if request.Code == "" {
+		return nil, mfaCodeNotProvided
+	}
+
+	userDetails, err := m.getAdminUser(authContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if userDetails.TotpEnabled {
+		return nil, mfaAlreadyEnabled
+	}
+	if userDetails.TotpSecret == "" {
+		return nil, mfaNotEnrolled
+	}
+
+	secret, err := util.Decrypt(m.encryptionKey, userDetails.TotpSecret)
+	if err != nil {
+		log.Printf("failed to decrypt totp secret of user %s, error %v\n", userDetails.Username, err)
+		return nil, app_errors.InternalServerError
+	}
+
+	step, ok := util.ValidateTotpCode(secret, request.Code, util.GetCurrentTimeInUtc())
+	if !ok {
+		log.Printf("invalid totp code for activation of user %s\n", userDetails.Username)
+		return nil, invalidMfaCode
+	}
+
+	return m.saveRecoveryCodes(userDetails.Username, func(tx *repository.Transaction) error {
+		return m.userRepo.EnableTotp(userDetails.Username, step, tx)
+	})
+}
+
+// RegenerateRecoveryCodes : replaces the recovery codes of the admin, the previous codes can't be used anymore
+func (m MfaServiceImplementation) RegenerateRecoveryCodes(authContext *AuthContext) (*dto.RecoveryCodesResponse, error) {
+	userDetails, err := m.getAdminUser(authContext)
+	if err != nil {
+		return nil, err
+	}
+
+	if !userDetails.TotpEnabled {
+		return nil, mfaNotEnrolled
+	}
+
+	return m.saveRecoveryCodes(userDetails.Username, nil)
+}
+
+// ResetMfa : disables MFA of a user who lost the authenticator and the recovery codes, the user has to enroll again
+func (m MfaServiceImplementation) ResetMfa(request *dto.MfaResetRequest) error {
+	if request.Username == "" {
+		return userIdMustBeProvided
+	}
+
+	_, err := m.userRepo.GetUserByUsername(request.Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return userNotFound
+		}
+		log.Printf("failed to fetch user %s, error %v\n", request.Username, err)
+		return app_errors.InternalServerError
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	tx, err := m.mfaRepo.CreateTransaction(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		log.Println("failed to initiate transaction")
+		return app_errors.InternalServerError
+	}
+
+	defer func() {
+		if err != nil {
+			log.Println("calling rollback for error " + err.Error())
+			_ = tx.Rollback()
+			return
+		}
+		_ = tx.Commit()
+	}()
+
+	err = m.userRepo.ResetTotp(request.Username, tx)
+	if err != nil {
+		log.Printf("failed to reset totp of user %s, error %v\n", request.Username, err)
+		return app_errors.InternalServerError
+	}
+
+	err = m.mfaRepo.ReplaceRecoveryCodes(request.Username, nil, tx)
+	if err != nil {
+		log.Printf("failed to remove recovery codes of user %s, error %v\n", request.Username, err)
+		return app_errors.InternalServerError
+	}
+	return nil
+}
+
+// saveRecoveryCodes : generates new recovery codes and stores their hashes, update is applied in the same transaction
+func (m MfaServiceImplementation) saveRecoveryCodes(username string,
+	update func(tx *repository.Transaction) error) (*dto.RecoveryCodesResponse, error) {
+
+	recoveryCodes := make([]string, 0, RecoveryCodeCount)
+	codeHashes := make([]string, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		recoveryCode, err := generateRecoveryCode()
+		if err != nil {
+			log.Println("failed to generate recovery code, error ", err)
+			return nil, app_errors.InternalServerError
+		}
+		recoveryCodes = append(recoveryCodes, recoveryCode)
+		codeHashes = append(codeHashes, util.HashToken(normalizeRecoveryCode(recoveryCode)))
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	tx, err := m.mfaRepo.CreateTransaction(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		log.Println("failed to initiate transaction")
+		return nil, app_errors.InternalServerError
+	}
+
+	defer func() {
+		if err != nil {
+			log.Println("calling rollback for error " + err.Error())
+			_ = tx.Rollback()
+			return
+		}
+		_ = tx.Commit()
+	}()
+
+	if update != nil {
+		err = update(tx)
+		if err != nil {
+			log.Printf("failed to update mfa of user %s, error %v\n", username, err)
+			return nil, app_errors.InternalServerError
+		}
+	}
+
+	err = m.mfaRepo.ReplaceRecoveryCodes(username, codeHashes, tx)
+	if err != nil {
+		log.Printf("failed to store recovery codes of user %s, error %v\n", username, err)
+		return nil, app_errors.InternalServerError
+	}
+
+	return &dto.RecoveryCodesResponse{RecoveryCodes: recoveryCodes}, nil
+}
+
+// getAdminUser : MFA is managed by the admin for its own login
+func (m MfaServiceImplementation) getAdminUser(authContext *AuthContext) (*responseDto.UserDetails, error) {
+	if authContext.Role != USER_TYPE_ADMIN {
+		log.Printf("mfa is only available for admin logins, %s logged in as %s\n", authContext.UserId, authContext.Role)
+		return nil, app_errors.Unauthorised
+	}
+
+	userDetails, err := m.userRepo.GetUserByUsername(authContext.UserId)
+	if err != nil {
+		log.Printf("failed to fetch user %s, error %v\n", authContext.UserId, err)
+		return nil, app_errors.InternalServerError
+	}
+	return userDetails, nil
+}
+
+// verifySecondFactor : validates a TOTP code (rejecting replays) or consumes a recovery code of the user
+func verifySecondFactor(userRepo repository.UserRepository, mfaRepo repository.MfaRepository, encryptionKey string,
+	userDetails *responseDto.UserDetails, code string) error {
+
+	if totpCodePattern.MatchString(code) {
+		secret, err := util.Decrypt(encryptionKey, userDetails.TotpSecret)
+		if err != nil {
+			log.Printf("failed to decrypt totp secret of user %s, error %v\n", userDetails.Username, err)
+			return app_errors.InternalServerError
+		}
+		step, ok := util.ValidateTotpCode(secret, code, util.GetCurrentTimeInUtc())
+		if !ok {
+			log.Printf("invalid totp code for user %s\n", userDetails.Username)
+			return invalidMfaCode
+		}
+		if err := userRepo.UpdateTotpLastStep(userDetails.Username, step); err != nil {
+			log.Printf("totp code of user %s was already used, error %v\n", userDetails.Username, err)
+			return invalidMfaCode
+		}
+		return nil
+	}
+
+	err := mfaRepo.UseRecoveryCode(userDetails.Username, util.HashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		log.Printf("invalid recovery code for user %s, error %v\n", userDetails.Username, err)
+		return invalidMfaCode
+	}
+	log.Printf("recovery code used by user %s\n", userDetails.Username)
+	return nil
+}
+
+// generateRecoveryCode : 80 bit random code formatted as XXXX-XXXX-XXXX-XXXX
+func generateRecoveryCode() (string, error) {
+	secret, err := util.GenerateTotpSecret()
+	if err != nil {
+		return "", err
+	}
+	code := secret[:16]
+	return code[0:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:16], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/s8sg/mini-loan-app/app/app_errors"
+	"github.com/s8sg/mini-loan-app/app/dto"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	AUTH_PROVIDER_LOCAL = "local"
+	AUTH_PROVIDER_OIDC  = "oidc"
+)
+
+var (
+	// JwksRefreshInterval limits how often the JWKS of the issuer is fetched again for an unknown kid
+	JwksRefreshInterval = time.Minute
+)
+
+var (
+	loginWithIdentityProvider = &app_errors.AppError{Code: 400, Message: "login with the identity provider"}
+)
+
+// OidcConfig : issuer whose ID tokens are accepted and how their claims map to users and roles
+type OidcConfig struct {
+	// Issuer must match the iss claim and serves /.well-known/openid-configuration
+	Issuer string
+	// ClientId must be in the aud claim
+	ClientId string
+	// UsernameClaim is the claim used as user id (e.g. sub or preferred_username)
+	UsernameClaim string
+	// RolesClaim is the claim with the groups or roles of the user (string or list of strings)
+	RolesClaim string
+	// RoleMapping maps the values of the roles claim to roles, values are used as roles when empty
+	RoleMapping map[string]string
+}
+
+type oidcDiscoveryDocument struct {
+	Issuer  string `json:"issuer"`
+	JwksUri string `json:"jwks_uri"`
+}
+
+// OidcAuthServiceImplementation : authenticates the ID tokens of an external OIDC provider instead of the built-in
+// login, the built-in AuthService is used for api keys, authorization and the tokens it issued itself
+type OidcAuthServiceImplementation struct {
+	AuthService
+
+	config     *OidcConfig
+	httpClient *http.Client
+
+	mutex         sync.Mutex
+	jwksUri       string
+	keys          map[string]*verificationKey
+	keysFetchedAt time.Time
+}
+
+// GetOidcAuthService : Initialise oidc-auth-service, uses dependency authService, the discovery document
+// and the JWKS of the issuer are fetched on first use
+func GetOidcAuthService(config *OidcConfig, authService AuthService, httpClient *http.Client) AuthService {
+	return &OidcAuthServiceImplementation{
+		AuthService: authService,
+		config:      config,
+		httpClient:  httpClient,
+		keys:        map[string]*verificationKey{},
+	}
+}
+
+// Login : users sign in with the identity provider
+func (service *OidcAuthServiceImplementation) Login(userid string, userType string, secret string,
+	clientIp string) (*AuthTokens, error) {
+	log.Printf("login with secret is disabled, user %s must login with the identity provider\n", userid)
+	return nil, loginWithIdentityProvider
+}
+
+// LoginWithMfa : the second factor is enforced by the identity provider
+func (service *OidcAuthServiceImplementation) LoginWithMfa(mfaToken string, code string,
+	clientIp string) (*AuthTokens, error) {
+	return nil, loginWithIdentityProvider
+}
+
+// ValidateToken : authenticates the ID token of the issuer, other tokens are validated by the built-in AuthService
+func (service *OidcAuthServiceImplementation) ValidateToken(token string) (*AuthContext, error) {
+	unverifiedClaims := jwt.MapClaims{}
+	_, _, err := jwt.NewParser().ParseUnverified(token, unverifiedClaims)
+	if err != nil || unverifiedClaims["iss"] != service.config.Issuer {
+		return service.AuthService.ValidateToken(token)
+	}
+
+	parsedToken, err := jwt.Parse(token, service.keyFunc, jwt.WithValidMethods([]string{
+		SIGNING_ALGORITHM_RS256, SIGNING_ALGORITHM_ES256}))
+	if err != nil {
+		log.Printf("id token parse failed %v\n", err)
+		return nil, app_errors.Unauthorised
+	}
+
+	claims, ok := parsedToken.Claims.(jwt.MapClaims)
+	if !ok || !parsedToken.Valid {
+		return nil, InvalidToken
+	}
+	if !claims.VerifyIssuer(service.config.Issuer, true) {
+		log.Printf("id token parse failed: unexpected issuer %v\n", claims["iss"])
+		return nil, app_errors.Unauthorised
+	}
+	if !claims.VerifyAudience(service.config.ClientId, true) {
+		log.Printf("id token parse failed: unexpected audience %v\n", claims["aud"])
+		return nil, app_errors.Unauthorised
+	}
+	if _, ok := claims["exp"]; !ok {
+		log.Printf("id token parse failed: exp is empty")
+		return nil, app_errors.Unauthorised
+	}
+
+	userid, ok := claims[service.config.UsernameClaim].(string)
+	if !ok || userid == "" {
+		log.Printf("id token parse failed: %s is empty", service.config.UsernameClaim)
+		return nil, app_errors.Unauthorised
+	}
+
+	role, roles := service.mapRoles(claims[service.config.RolesClaim])
+	if role == "" {
+		log.Printf("user %s doesn't have any role in %s %v\n", userid, service.config.RolesClaim,
+			claims[service.config.RolesClaim])
+		return nil, app_errors.Unauthorised
+	}
+
+	sessionId, _ := claims["sid"].(string)
+
+	return &AuthContext{UserId: userid, Role: role, Roles: roles, SessionId: sessionId,
+		MfaVerified: hasMfaAuthenticationMethod(claims["amr"])}, nil
+}
+
+// mapRoles : maps the claim to roles, users with any staff role login as admin, otherwise as customer
+func (service *OidcAuthServiceImplementation) mapRoles(rolesClaim interface{}) (string, []string) {
+	claimValues := make([]string, 0)
+	switch value := rolesClaim.(type) {
+	case string:
+		claimValues = append(claimValues, strings.Fields(value)...)
+	case []interface{}:
+		for _, claimValue := range value {
+			claimValues = append(claimValues, fmt.Sprint(claimValue))
+		}
+	}
+
+	customerRoles := make([]string, 0)
+	staffRoles := make([]string, 0)
+	for _, claimValue := range claimValues {
+		role := claimValue
+		if len(service.config.RoleMapping) > 0 {
+			mappedRole, ok := service.config.RoleMapping[claimValue]
+			if !ok {
+				continue
+			}
+			role = mappedRole
+		}
+		if role == USER_TYPE_CUSTOMER {
+			customerRoles = append(customerRoles, role)
+		} else {
+			staffRoles = append(staffRoles, role)
+		}
+	}
+
+	if len(staffRoles) > 0 {
+		return USER_TYPE_ADMIN, uniqueValues(staffRoles)
+	}
+	if len(customerRoles) > 0 {
+		return USER_TYPE_CUSTOMER, []string{USER_TYPE_CUSTOMER}
+	}
+	return "", nil
+}
+
+// keyFunc : selects the verification key of the issuer by kid, the JWKS is fetched again for unknown kids (key rotation)
+func (service *OidcAuthServiceImplementation) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil, fmt.Errorf("token doesn't have a kid")
+	}
+
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+
+	key, ok := service.keys[kid]
+	if !ok && time.Since(service.keysFetchedAt) > JwksRefreshInterval {
+		if err := service.fetchKeys(); err != nil {
+			return nil, err
+		}
+		key, ok = service.keys[kid]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %s", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %v for kid %s", token.Header["alg"], kid)
+	}
+	return key.key, nil
+}
+
+// fetchKeys : fetches the JWKS of the issuer, the jwks_uri is discovered once from the discovery document
+func (service *OidcAuthServiceImplementation) fetchKeys() error {
+	service.keysFetchedAt = time.Now()
+
+	if service.jwksUri == "" {
+		discoveryDocument := &oidcDiscoveryDocument{}
+		discoveryUrl := strings.TrimSuffix(service.config.Issuer, "/") + "/.well-known/openid-configuration"
+		if err := service.getJson(discoveryUrl, discoveryDocument); err != nil {
+			return fmt.Errorf("failed to fetch discovery document, %v", err)
+		}
+		if discoveryDocument.Issuer != service.config.Issuer {
+			return fmt.Errorf("discovery document is for issuer %s", discoveryDocument.Issuer)
+		}
+		if discoveryDocument.JwksUri == "" {
+			return fmt.Errorf("discovery document doesn't have a jwks_uri")
+		}
+		service.jwksUri = discoveryDocument.JwksUri
+	}
+
+	keySet := &dto.JSONWebKeySet{}
+	if err := service.getJson(service.jwksUri, keySet); err != nil {
+		return fmt.Errorf("failed to fetch jwks, %v", err)
+	}
+
+	keys := map[string]*verificationKey{}
+	for _, jwk := range keySet.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := parseJSONWebKey(jwk)
+		if err != nil {
+			log.Printf("skipping key of issuer %s, %v\n", service.config.Issuer, err)
+			continue
+		}
+		keys[jwk.KeyId] = key
+	}
+	service.keys = keys
+	return nil
+}
+
+func (service *OidcAuthServiceImplementation) getJson(url string, response interface{}) error {
+	res, err := service.httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", res.StatusCode, url)
+	}
+	return json.NewDecoder(res.Body).Decode(response)
+}
+
+// hasMfaAuthenticationMethod : checks the amr claim (RFC 8176) for a multi-factor login
+func hasMfaAuthenticationMethod(amrClaim interface{}) bool {
+	methods, ok := amrClaim.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, method := range methods {
+		if method == "mfa" {
+			return true
+		}
+	}
+	return false
+}
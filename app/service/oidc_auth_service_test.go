@@ -0,0 +1,255 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/s8sg/mini-loan-app/app/dto"
+)
+
+const stubClientId = "mini-loan-app"
+
+// stubIssuer serves the discovery document and the JWKS of an OIDC provider, keys can be rotated by the test
+type stubIssuer struct {
+	server *httptest.Server
+	keys   map[string]interface{}
+}
+
+func newStubIssuer(t *testing.T) *stubIssuer {
+	t.Helper()
+
+	issuer := &stubIssuer{keys: map[string]interface{}{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   issuer.server.URL,
+			"jwks_uri": issuer.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		keySet := &dto.JSONWebKeySet{Keys: make([]*dto.JSONWebKey, 0)}
+		for kid, key := range issuer.keys {
+			switch privateKey := key.(type) {
+			case *rsa.PrivateKey:
+				keySet.Keys = append(keySet.Keys, &dto.JSONWebKey{KeyType: "RSA", KeyId: kid, Use: "sig",
+					N: base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+					E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes())})
+			case *ecdsa.PrivateKey:
+				keySet.Keys = append(keySet.Keys, &dto.JSONWebKey{KeyType: "EC", KeyId: kid, Use: "sig", Curve: "P-256",
+					X: base64.RawURLEncoding.EncodeToString(privateKey.X.FillBytes(make([]byte, 32))),
+					Y: base64.RawURLEncoding.EncodeToString(privateKey.Y.FillBytes(make([]byte, 32)))})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(keySet)
+	})
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+func (issuer *stubIssuer) addRSAKey(t *testing.T, kid string) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer.keys[kid] = key
+	return key
+}
+
+func (issuer *stubIssuer) addECKey(t *testing.T, kid string) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer.keys[kid] = key
+	return key
+}
+
+// idToken signs the claims, the issuer, audience and expiry are set unless provided
+func (issuer *stubIssuer) idToken(t *testing.T, kid string, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+
+	defaults := jwt.MapClaims{
+		"iss": issuer.server.URL,
+		"aud": stubClientId,
+		"exp": time.Now().Add(time.Minute).Unix(),
+		"iat": time.Now().Unix(),
+	}
+	for name, value := range defaults {
+		if _, ok := claims[name]; !ok {
+			claims[name] = value
+		}
+	}
+
+	method := jwt.SigningMethod(jwt.SigningMethodRS256)
+	if _, ok := key.(*ecdsa.PrivateKey); ok {
+		method = jwt.SigningMethodES256
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signedToken, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signedToken
+}
+
+// stubAuthService is the built-in auth service, it accepts the token "local-token" only
+type stubAuthService struct {
+	AuthService
+}
+
+func (s *stubAuthService) ValidateToken(token string) (*AuthContext, error) {
+	if token != "local-token" {
+		return nil, InvalidToken
+	}
+	return &AuthContext{UserId: "local-user", Role: USER_TYPE_CUSTOMER, Roles: []string{USER_TYPE_CUSTOMER}}, nil
+}
+
+func newOidcAuthService(issuer *stubIssuer, roleMapping map[string]string) AuthService {
+	return GetOidcAuthService(&OidcConfig{
+		Issuer:        issuer.server.URL,
+		ClientId:      stubClientId,
+		UsernameClaim: "preferred_username",
+		RolesClaim:    "groups",
+		RoleMapping:   roleMapping,
+	}, &stubAuthService{}, issuer.server.Client())
+}
+
+func TestOidcAuthService_ValidateToken(t *testing.T) {
+	issuer := newStubIssuer(t)
+	rsaKey := issuer.addRSAKey(t, "rsa-1")
+	ecKey := issuer.addECKey(t, "ec-1")
+
+	authService := newOidcAuthService(issuer, map[string]string{
+		"loan-customers":  USER_TYPE_CUSTOMER,
+		"credit-officers": "credit-officer",
+		"support":         "support",
+	})
+
+	t.Run("customer", func(t *testing.T) {
+		token := issuer.idToken(t, "rsa-1", rsaKey, jwt.MapClaims{
+			"preferred_username": "user1", "groups": []string{"loan-customers", "unmapped"}})
+
+		authContext, err := authService.ValidateToken(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if authContext.UserId != "user1" || authContext.Role != USER_TYPE_CUSTOMER ||
+			len(authContext.Roles) != 1 || authContext.Roles[0] != USER_TYPE_CUSTOMER {
+			t.Errorf("unexpected auth context %+v", authContext)
+		}
+	})
+
+	t.Run("staff with mfa", func(t *testing.T) {
+		token := issuer.idToken(t, "ec-1", ecKey, jwt.MapClaims{"preferred_username": "officer1",
+			"groups": []string{"credit-officers", "support"}, "amr": []string{"pwd", "mfa"}, "aud": []string{"other", stubClientId}})
+
+		authContext, err := authService.ValidateToken(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if authContext.Role != USER_TYPE_ADMIN || len(authContext.Roles) != 2 || !authContext.MfaVerified {
+			t.Errorf("unexpected auth context %+v", authContext)
+		}
+	})
+
+	t.Run("staff without mfa", func(t *testing.T) {
+		token := issuer.idToken(t, "rsa-1", rsaKey, jwt.MapClaims{"preferred_username": "support1", "groups": "support"})
+
+		authContext, err := authService.ValidateToken(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if authContext.Role != USER_TYPE_ADMIN || authContext.MfaVerified {
+			t.Errorf("unexpected auth context %+v", authContext)
+		}
+	})
+
+	invalidTokens := map[string]string{
+		"wrong audience": issuer.idToken(t, "rsa-1", rsaKey, jwt.MapClaims{
+			"preferred_username": "user1", "groups": []string{"loan-customers"}, "aud": "other"}),
+		"expired": issuer.idToken(t, "rsa-1", rsaKey, jwt.MapClaims{
+			"preferred_username": "user1", "groups": []string{"loan-customers"}, "exp": time.Now().Add(-time.Minute).Unix()}),
+		"no expiry": issuer.idToken(t, "rsa-1", rsaKey, jwt.MapClaims{
+			"preferred_username": "user1", "groups": []string{"loan-customers"}, "exp": nil}),
+		"no username": issuer.idToken(t, "rsa-1", rsaKey, jwt.MapClaims{"groups": []string{"loan-customers"}}),
+		"no mapped role": issuer.idToken(t, "rsa-1", rsaKey, jwt.MapClaims{
+			"preferred_username": "user1", "groups": []string{"unmapped"}}),
+		"signed by unknown key": issuer.idToken(t, "rsa-1", mustGenerateRSAKey(t), jwt.MapClaims{
+			"preferred_username": "user1", "groups": []string{"loan-customers"}}),
+		"unknown kid": issuer.idToken(t, "rsa-2", rsaKey, jwt.MapClaims{
+			"preferred_username": "user1", "groups": []string{"loan-customers"}}),
+	}
+	for name, token := range invalidTokens {
+		t.Run(name, func(t *testing.T) {
+			if _, err := authService.ValidateToken(token); err == nil {
+				t.Errorf("expected token to be rejected")
+			}
+		})
+	}
+
+	t.Run("token of the built-in auth service", func(t *testing.T) {
+		authContext, err := authService.ValidateToken("local-token")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if authContext.UserId != "local-user" {
+			t.Errorf("unexpected auth context %+v", authContext)
+		}
+	})
+}
+
+func TestOidcAuthService_KeyRotation(t *testing.T) {
+	refreshInterval := JwksRefreshInterval
+	JwksRefreshInterval = 0
+	t.Cleanup(func() { JwksRefreshInterval = refreshInterval })
+
+	issuer := newStubIssuer(t)
+	oldKey := issuer.addRSAKey(t, "old")
+	authService := newOidcAuthService(issuer, nil)
+
+	token := issuer.idToken(t, "old", oldKey, jwt.MapClaims{"preferred_username": "user1", "groups": []string{"customer"}})
+	if _, err := authService.ValidateToken(token); err != nil {
+		t.Fatal(err)
+	}
+
+	// the issuer rotates to a new key, the JWKS is fetched again for the unknown kid
+	delete(issuer.keys, "old")
+	newKey := issuer.addRSAKey(t, "new")
+
+	token = issuer.idToken(t, "new", newKey, jwt.MapClaims{"preferred_username": "user1", "groups": []string{"customer"}})
+	if _, err := authService.ValidateToken(token); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOidcAuthService_Login(t *testing.T) {
+	issuer := newStubIssuer(t)
+	authService := newOidcAuthService(issuer, nil)
+
+	if _, err := authService.Login("user1", USER_TYPE_CUSTOMER, "secret", "127.0.0.1"); err != loginWithIdentityProvider {
+		t.Errorf("expected login with secret to be disabled, got %v", err)
+	}
+}
+
+func mustGenerateRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
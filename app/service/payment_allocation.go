@@ -0,0 +1,142 @@
+package service
+
+import (
+	"fmt"
+	responseDto "github.com/s8sg/mini-loan-app/app/dto"
+	"github.com/shopspring/decimal"
+	"log"
+	"sort"
+)
+
+var (
+	// PaymentWaterfall is the order in which a payment pays the components of the repayments
+	PaymentWaterfall = []string{responseDto.PaymentComponentFee, responseDto.PaymentComponentInterest,
+		responseDto.PaymentComponentPrincipal}
+)
+
+// ValidatePaymentWaterfall : validates that the waterfall contains each component of a repayment once
+func ValidatePaymentWaterfall(waterfall []string) error {
+	components := map[string]bool{
+		responseDto.PaymentComponentFee:       false,
+		responseDto.PaymentComponentInterest:  false,
+		responseDto.PaymentComponentPrincipal: false,
+	}
+	for _, component := range waterfall {
+		seen, ok := components[component]
+		if !ok {
+			return fmt.Errorf("payment component %s is not supported", component)
+		}
+		if seen {
+			return fmt.Errorf("payment component %s is repeated", component)
+		}
+		components[component] = true
+	}
+	if len(waterfall) != len(components) {
+		return fmt.Errorf("payment waterfall must contain FEE, INTEREST and PRINCIPAL")
+	}
+	return nil
+}
+
+// getRepaymentGroups : groups of the repayment with the number and the following unpaid repayments, each repayment
+// is paid in full before the next one
+func getRepaymentGroups(repayments []*responseDto.RepaymentDetails, number int) [][]*responseDto.RepaymentDetails {
+	groups := make([][]*responseDto.RepaymentDetails, 0)
+	for _, repayment := range getUnpaidRepayments(repayments) {
+		if repayment.Number >= number {
+			groups = append(groups, []*responseDto.RepaymentDetails{repayment})
+		}
+	}
+	return groups
+}
+
+// getLoanPaymentGroups : groups of the unpaid repayments of a loan, each repayment is paid in full in the order of
+// their due date so the oldest overdue installment is cleared before the next one
+func getLoanPaymentGroups(repayments []*responseDto.RepaymentDetails) [][]*responseDto.RepaymentDetails {
+	groups := make([][]*responseDto.RepaymentDetails, 0)
+	for _, repayment := range getUnpaidRepayments(repayments) {
+		groups = append(groups, []*responseDto.RepaymentDetails{repayment})
+	}
+	return groups
+}
+
+// getUnpaidRepayments : repayments that are not paid in full in the order of their due date
+func getUnpaidRepayments(repayments []*responseDto.RepaymentDetails) []*responseDto.RepaymentDetails {
+	unpaidRepayments := make([]*responseDto.RepaymentDetails, 0)
+	for _, repayment := range repayments {
+		if repayment.Status != responseDto.RepaymentStatusPaid {
+			unpaidRepayments = append(unpaidRepayments, repayment)
+		}
+	}
+	sort.SliceStable(unpaidRepayments, func(i, j int) bool {
+		if unpaidRepayments[i].DueDate.Equal(unpaidRepayments[j].DueDate) {
+			return unpaidRepayments[i].Number < unpaidRepayments[j].Number
+		}
+		return unpaidRepayments[i].DueDate.Before(unpaidRepayments[j].DueDate)
+	})
+	return unpaidRepayments
+}
+
+// allocatePayment : pays the amount to the groups of repayments in their order, within a group the components are
+// paid in the order of the PaymentWaterfall. Responds with the amounts paid to each repayment
+func allocatePayment(groups [][]*responseDto.RepaymentDetails,
+	amount decimal.Decimal) ([]*responseDto.PaymentAllocation, error) {
+	allocations := make([]*responseDto.PaymentAllocation, 0)
+	remaining := amount
+	for _, group := range groups {
+		groupAllocations := make([]*responseDto.PaymentAllocation, len(group))
+		for _, component := range PaymentWaterfall {
+			for i, repayment := range group {
+				if !remaining.IsPositive() {
+					break
+				}
+				due := selectComponent(component, &repayment.Principal, &repayment.Interest, &repayment.Fee)
+				paid := selectComponent(component, &repayment.PaidPrincipal, &repayment.PaidInterest, &repayment.PaidFee)
+				paidNow := decimal.Min(remaining, due.Sub(*paid))
+				if !paidNow.IsPositive() {
+					continue
+				}
+
+				if groupAllocations[i] == nil {
+					groupAllocations[i] = &responseDto.PaymentAllocation{RepaymentId: repayment.RepaymentId}
+				}
+				allocation := groupAllocations[i]
+				allocated := selectComponent(component, &allocation.Principal, &allocation.Interest, &allocation.Fee)
+				*allocated = allocated.Add(paidNow)
+				allocation.Amount = allocation.Amount.Add(paidNow)
+				*paid = paid.Add(paidNow)
+				remaining = remaining.Sub(paidNow)
+			}
+		}
+
+		for i, repayment := range group {
+			if groupAllocations[i] == nil {
+				continue
+			}
+			repayment.PaidAmount = repayment.PaidPrincipal.Add(repayment.PaidInterest).Add(repayment.PaidFee)
+			repayment.Status = responseDto.RepaymentStatusPartiallyPaid
+			if repayment.PaidAmount.Equal(repayment.Amount) {
+				repayment.Status = responseDto.RepaymentStatusPaid
+			}
+			allocations = append(allocations, groupAllocations[i])
+		}
+	}
+
+	if remaining.IsPositive() {
+		log.Printf("amount %v exceeds the outstanding amount by %v\n", amount, remaining)
+		return nil, amountExceedsOutstanding
+	}
+	return allocations, nil
+}
+
+// selectComponent : amount of the component among the principal, interest and fee amounts
+func selectComponent(component string, principal *decimal.Decimal, interest *decimal.Decimal,
+	fee *decimal.Decimal) *decimal.Decimal {
+	switch component {
+	case responseDto.PaymentComponentFee:
+		return fee
+	case responseDto.PaymentComponentInterest:
+		return interest
+	default:
+		return principal
+	}
+}
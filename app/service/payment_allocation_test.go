@@ -0,0 +1,157 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	responseDto "github.com/s8sg/mini-loan-app/app/dto"
+	"github.com/shopspring/decimal"
+)
+
+// newRepayment : repayment of 100 with a principal of 80, an interest of 15 and a fee of 5 due in number weeks
+func newRepayment(number int, dueDate time.Time) *responseDto.RepaymentDetails {
+	return &responseDto.RepaymentDetails{
+		RepaymentId: string(rune('a' + number - 1)),
+		Number:      number,
+		Amount:      decimal.NewFromInt(100),
+		Principal:   decimal.NewFromInt(80),
+		Interest:    decimal.NewFromInt(15),
+		Fee:         decimal.NewFromInt(5),
+		Status:      responseDto.RepaymentStatusPending,
+		DueDate:     dueDate.AddDate(0, 0, 7*number),
+	}
+}
+
+func TestAllocatePayment(t *testing.T) {
+	startDate := time.Date(2024, time.January, 31, 10, 0, 0, 0, time.UTC)
+	newRepayments := func() []*responseDto.RepaymentDetails {
+		partiallyPaid := newRepayment(1, startDate)
+		partiallyPaid.PaidAmount = decimal.NewFromInt(40)
+		partiallyPaid.PaidFee = decimal.NewFromInt(5)
+		partiallyPaid.PaidInterest = decimal.NewFromInt(15)
+		partiallyPaid.PaidPrincipal = decimal.NewFromInt(20)
+		partiallyPaid.Status = responseDto.RepaymentStatusPartiallyPaid
+		return []*responseDto.RepaymentDetails{newRepayment(2, startDate), partiallyPaid, newRepayment(3, startDate)}
+	}
+
+	tests := []struct {
+		name     string
+		number   int
+		amount   int64
+		expected []string
+		err      error
+	}{
+		{"partial", 1, 20, []string{"60", "0", "0"}, nil},
+		{"installment", 1, 60, []string{"100", "0", "0"}, nil},
+		{"carried forward", 1, 110, []string{"100", "50", "0"}, nil},
+		{"outstanding of the loan", 1, 260, []string{"100", "100", "100"}, nil},
+		{"following repayments only", 2, 20, []string{"40", "20", "0"}, nil},
+		{"above the outstanding", 2, 201, nil, amountExceedsOutstanding},
+	}
+	for _, test := range tests {
+		repayments := newRepayments()
+		allocations, err := allocatePayment(getRepaymentGroups(repayments, test.number), decimal.NewFromInt(test.amount))
+		if err != test.err {
+			t.Errorf("%s: expected error %v but got %v", test.name, test.err, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		allocated := decimal.Zero
+		for _, allocation := range allocations {
+			allocated = allocated.Add(allocation.Amount)
+		}
+		if !allocated.Equal(decimal.NewFromInt(test.amount)) {
+			t.Errorf("%s: expected the amount %d to be allocated but got %v", test.name, test.amount, allocated)
+		}
+
+		loanDetails := &responseDto.LoanDetails{Repayments: repayments}
+		loanDetails.UpdateOutstanding()
+		for _, repayment := range repayments {
+			expected := decimal.RequireFromString(test.expected[repayment.Number-1])
+			if !repayment.PaidAmount.Equal(expected) {
+				t.Errorf("%s: expected paid amount %s of repayment %d but got %v", test.name, expected,
+					repayment.Number, repayment.PaidAmount)
+			}
+
+			expectedStatus := responseDto.RepaymentStatusPartiallyPaid
+			if expected.IsZero() {
+				expectedStatus = responseDto.RepaymentStatusPending
+			} else if expected.Equal(repayment.Amount) {
+				expectedStatus = responseDto.RepaymentStatusPaid
+			}
+			if repayment.Status != expectedStatus {
+				t.Errorf("%s: expected status %s of repayment %d but got %s", test.name, expectedStatus,
+					repayment.Number, repayment.Status)
+			}
+			if !repayment.Outstanding.Equal(repayment.Amount.Sub(expected)) {
+				t.Errorf("%s: expected outstanding amount %v of repayment %d but got %v", test.name,
+					repayment.Amount.Sub(expected), repayment.Number, repayment.Outstanding)
+			}
+		}
+	}
+}
+
+func TestAllocatePayment_Waterfall(t *testing.T) {
+	// the repayments are given out of order, they are paid in the order of their due date
+	startDate := time.Date(2024, time.January, 31, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		waterfall []string
+		amount    int64
+		// expected paid principal, interest and fee of each repayment
+		expected [][3]int64
+	}{
+		{"fee and interest first", PaymentWaterfall, 50, [][3]int64{{30, 15, 5}, {0, 0, 0}, {0, 0, 0}}},
+		// the oldest overdue installment is paid in full before the fee and the interest of the next one
+		{"two overdue installments", PaymentWaterfall, 120, [][3]int64{{80, 15, 5}, {0, 15, 5}, {0, 0, 0}}},
+		{"installments in order", PaymentWaterfall, 220, [][3]int64{{80, 15, 5}, {80, 15, 5}, {0, 15, 5}}},
+		{"principal first", []string{responseDto.PaymentComponentPrincipal, responseDto.PaymentComponentInterest,
+			responseDto.PaymentComponentFee}, 170, [][3]int64{{80, 15, 5}, {70, 0, 0}, {0, 0, 0}}},
+	}
+	defaultWaterfall := PaymentWaterfall
+	for _, test := range tests {
+		PaymentWaterfall = test.waterfall
+		repayments := []*responseDto.RepaymentDetails{newRepayment(3, startDate), newRepayment(1, startDate),
+			newRepayment(2, startDate)}
+
+		_, err := allocatePayment(getLoanPaymentGroups(repayments), decimal.NewFromInt(test.amount))
+		if err != nil {
+			t.Errorf("%s: expected no error but got %v", test.name, err)
+			continue
+		}
+		for _, repayment := range repayments {
+			expected := test.expected[repayment.Number-1]
+			if !repayment.PaidPrincipal.Equal(decimal.NewFromInt(expected[0])) ||
+				!repayment.PaidInterest.Equal(decimal.NewFromInt(expected[1])) ||
+				!repayment.PaidFee.Equal(decimal.NewFromInt(expected[2])) {
+				t.Errorf("%s: expected paid principal, interest and fee %v of repayment %d but got %v %v %v",
+					test.name, expected, repayment.Number, repayment.PaidPrincipal, repayment.PaidInterest,
+					repayment.PaidFee)
+			}
+		}
+	}
+	PaymentWaterfall = defaultWaterfall
+}
+
+func TestValidatePaymentWaterfall(t *testing.T) {
+	tests := []struct {
+		waterfall []string
+		valid     bool
+	}{
+		{[]string{"FEE", "INTEREST", "PRINCIPAL"}, true},
+		{[]string{"PRINCIPAL", "FEE", "INTEREST"}, true},
+		{[]string{"FEE", "INTEREST"}, false},
+		{[]string{"FEE", "FEE", "PRINCIPAL"}, false},
+		{[]string{"FEE", "INTEREST", "PENALTY"}, false},
+	}
+	for _, test := range tests {
+		err := ValidatePaymentWaterfall(test.waterfall)
+		if (err == nil) != test.valid {
+			t.Errorf("%v: expected valid %v but got %v", test.waterfall, test.valid, err)
+		}
+	}
+}
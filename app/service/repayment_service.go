@@ -8,6 +8,7 @@ import (
 	"github.com/s8sg/mini-loan-app/app/controller/dto"
 	repoDto "github.com/s8sg/mini-loan-app/app/dto"
 	repository "github.com/s8sg/mini-loan-app/app/repostory"
+	"github.com/s8sg/mini-loan-app/app/util"
 	"github.com/shopspring/decimal"
 	"log"
 	"time"
@@ -23,11 +24,17 @@ var (
 	repaymentIdNotProvided = &app_errors.AppError{Code: 400, Message: "repaymentId must be provided"}
 	invalidLoanStatus      = &app_errors.AppError{Code: 400, Message: "invalid loan status"}
 	invalidRepaymentStatus = &app_errors.AppError{Code: 400, Message: "invalid repayment status"}
-	amountNotSufficient    = &app_errors.AppError{Code: 400, Message: "amount not sufficient"}
+	loanCancelled          = &app_errors.AppError{Code: 400, Message: "loan is cancelled"}
+	repaymentAmountInvalid = &app_errors.AppError{Code: 400,
+		Message: "amount can't be negative or have fractions of the minor unit of the currency"}
+	amountExceedsOutstanding = &app_errors.AppError{Code: 400, Message: "amount exceeds the outstanding amount of the loan"}
+	paymentChannelInvalid    = &app_errors.AppError{Code: 400, Message: "channel must be CARD, BANK_TRANSFER or DIRECT_DEBIT"}
 )
 
 type RepaymentService interface {
 	Repay(customerId string, request *dto.LoanRepaymentRequest) error
+	PayLoan(customerId string, loanId string, request *dto.LoanPayRequest) (*repoDto.PaymentDetails, error)
+	GetLoanPayments(customerId string, loanId string) ([]*repoDto.PaymentDetails, error)
 }
 
 type RepaymentServiceImplementation struct {
@@ -49,11 +56,21 @@ func (r RepaymentServiceImplementation) Repay(customerId string, request *dto.Lo
 		return amountNotProvided
 	}
 
+	if request.Amount < 0 {
+		log.Println("amount can't be negative")
+		return repaymentAmountInvalid
+	}
+
 	if request.RepaymentID == "" {
 		log.Println("repaymentId must be provided")
 		return repaymentIdNotProvided
 	}
 
+	channel, err := getPaymentChannel(request.Channel)
+	if err != nil {
+		return err
+	}
+
 	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
 	defer cancelFunc()
 
@@ -98,52 +115,217 @@ func (r RepaymentServiceImplementation) Repay(customerId string, request *dto.Lo
 		return repaymentNotFound
 	}
 
-	// check the lean status
-	if loanDetails.Status != repoDto.LoanStatusApproved {
-		log.Println("loan status invalid")
-		err = fmt.Errorf("loan has an invalid status %s", loanDetails.Status)
-		return invalidLoanStatus
+	err = checkLoanPayable(loanDetails)
+	if err != nil {
+		return err
 	}
 
 	// check if the repayment status
-	if repaymentDetails.Status != repoDto.RepaymentStatusPending {
+	if repaymentDetails.Status == repoDto.RepaymentStatusPaid {
 		log.Println("repayment status invalid")
 		err = fmt.Errorf("repaymentis already paid")
 		return invalidRepaymentStatus
 	}
 
-	// check of the repayment amount >= due amount
-	if decimal.NewFromFloat(request.Amount).LessThan(repaymentDetails.Amount) {
-		log.Println("invalid amount paid")
-		err = fmt.Errorf("repaymentis paid with invalid amount")
-		return amountNotSufficient
+	// the amount pays the repayment, the excess is carried forward to the following repayments
+	groups := getRepaymentGroups(loanDetails.Repayments, repaymentDetails.Number)
+	_, err = r.applyPayment(customerId, loanDetails, groups, request.Amount, channel, tx)
+	return err
+}
+
+// PayLoan : pays the amount to the unpaid repayments of the loan oldest first, the components of the repayments
+// are paid in the order of the PaymentWaterfall. Responds with the payment and its allocations
+func (r RepaymentServiceImplementation) PayLoan(customerId string, loanId string,
+	request *dto.LoanPayRequest) (*repoDto.PaymentDetails, error) {
+	if loanId == "" {
+		log.Println("loan id not specified")
+		return nil, invalidLoanId
 	}
 
-	err = r.repo.UpdateRepaymentStatus(repaymentDetails.RepaymentId, repoDto.RepaymentStatusPaid, tx)
+	if request.Amount == 0 {
+		log.Println("amount must be provided")
+		return nil, amountNotProvided
+	}
+
+	if request.Amount < 0 {
+		log.Println("amount can't be negative")
+		return nil, repaymentAmountInvalid
+	}
+
+	channel, err := getPaymentChannel(request.Channel)
 	if err != nil {
-		log.Println("failed to update repayment, error " + err.Error())
-		return app_errors.InternalServerError
+		return nil, err
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	txOption := &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+	}
+
+	tx, err := r.repo.CreateTransaction(ctx, txOption)
+	if err != nil {
+		log.Println("failed to initiate transaction")
+		return nil, app_errors.InternalServerError
 	}
 
-	repaidRepayments := getRepaidRepaymentCount(loanDetails)
+	defer func() {
+		if err != nil {
+			log.Println("calling rollback for error " + err.Error())
+			_ = tx.Rollback()
+			return
+		}
+		_ = tx.Commit()
+	}()
+
+	loanDetails, err := r.repo.GetLoanById(loanId, tx)
+	if err != nil {
+		log.Println("loan can not be fetched")
+		return nil, loanNotPresent
+	}
+
+	// check if loan belongs to customer
+	if loanDetails.CustomerId != customerId {
+		log.Println("loan doesn't belongs to customer")
+		err = fmt.Errorf("loan doesn't belongs to customer")
+		return nil, loanNotPresent
+	}
+
+	err = checkLoanPayable(loanDetails)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := getLoanPaymentGroups(loanDetails.Repayments)
+	payment, err := r.applyPayment(customerId, loanDetails, groups, request.Amount, channel, tx)
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// checkLoanPayable : only approved loans accept payments
+func checkLoanPayable(loanDetails *repoDto.LoanDetails) error {
+	// check the lean status, cancelled loans don't accept payments
+	if loanDetails.Status == repoDto.LoanStatusCancelled {
+		log.Println("loan is cancelled")
+		return loanCancelled
+	}
+	if loanDetails.Status != repoDto.LoanStatusApproved {
+		log.Printf("loan has an invalid status %s\n", loanDetails.Status)
+		return invalidLoanStatus
+	}
+	return nil
+}
+
+// applyPayment : allocates the payment to the groups of repayments, records it and marks the loan as paid once
+// nothing is outstanding. Must run in the transaction that locked the loan
+func (r RepaymentServiceImplementation) applyPayment(customerId string, loanDetails *repoDto.LoanDetails,
+	groups [][]*repoDto.RepaymentDetails, paidAmount float64, channel string,
+	tx *repository.Transaction) (*repoDto.PaymentDetails, error) {
+	// the amount can't have fractions of the minor unit of the currency
+	amount := decimal.NewFromFloat(paidAmount)
+	minorUnits, ok := util.GetCurrencyMinorUnits(loanDetails.Currency)
+	if !ok || !amount.Equal(amount.Truncate(minorUnits)) {
+		log.Printf("amount %v has fractions of the minor unit of %s\n", amount, loanDetails.Currency)
+		return nil, repaymentAmountInvalid
+	}
+
+	allocations, err := allocatePayment(groups, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	repayments := map[string]*repoDto.RepaymentDetails{}
+	for _, repayment := range loanDetails.Repayments {
+		repayments[repayment.RepaymentId] = repayment
+	}
+	for _, allocation := range allocations {
+		err = r.repo.UpdateRepaymentPayment(repayments[allocation.RepaymentId], tx)
+		if err != nil {
+			log.Println("failed to update repayment, error " + err.Error())
+			return nil, app_errors.InternalServerError
+		}
+	}
+
+	// the payment records what the customer paid and the repayments it paid
+	payment := &repoDto.PaymentDetails{
+		PaymentId:        util.GeneratePaymentID(),
+		LoanId:           loanDetails.LoanId,
+		CustomerId:       customerId,
+		Amount:           amount,
+		Currency:         loanDetails.Currency,
+		Channel:          channel,
+		Allocations:      allocations,
+		CreatedTimestamp: util.GetCurrentTimeInUtc(),
+	}
+	err = r.repo.CreatePayment(payment, tx)
+	if err != nil {
+		log.Printf("failed to record payment of loan %s, error %v\n", loanDetails.LoanId, err)
+		return nil, app_errors.InternalServerError
+	}
 
 	// check if all repayments are being paid
 	// mark the loan as paid
-	if repaidRepayments+1 == loanDetails.Term {
-		err = r.repo.UpdateLoanStatus(loanID, repoDto.LOAN_STATUS_PAID, tx)
+	loanDetails.UpdateOutstanding()
+	if loanDetails.Outstanding.IsZero() {
+		actor := getCustomerLoanActor(customerId)
+		err = checkLoanTransition(loanDetails.Status, repoDto.LoanStatusPaid, actor)
+		if err != nil {
+			return nil, err
+		}
+
+		err = r.repo.UpdateLoanStatus(loanDetails.LoanId, repoDto.LoanStatusPaid, tx)
 		if err != nil {
 			log.Println("failed tp update loan status")
-			return app_errors.InternalServerError
+			return nil, app_errors.InternalServerError
+		}
+
+		err = recordLoanTransition(r.repo, loanDetails, repoDto.LoanStatusPaid, actor, "all repayments are paid", tx)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	return nil
+	return payment, nil
+}
+
+// GetLoanPayments : responds with the payments of the loan and their allocations to the repayments, customerId
+// restricts the payments to the loans of the customer and is empty for staff
+func (r RepaymentServiceImplementation) GetLoanPayments(customerId string,
+	loanId string) ([]*repoDto.PaymentDetails, error) {
+	if loanId == "" {
+		log.Println("loan id not specified")
+		return nil, invalidLoanId
+	}
+
+	payments, err := r.repo.GetPaymentsByLoanId(loanId, customerId)
+	if err != nil {
+		log.Printf("failed to get payments of loan %s, error %v\n", loanId, err)
+		return nil, app_errors.InternalServerError
+	}
+	return payments, nil
+}
+
+// getPaymentChannel : validates the channel of the payment, payments without a channel are paid by card
+func getPaymentChannel(channel string) (string, error) {
+	switch channel {
+	case "":
+		return repoDto.PaymentChannelCard, nil
+	case repoDto.PaymentChannelCard, repoDto.PaymentChannelBankTransfer, repoDto.PaymentChannelDirectDebit:
+		return channel, nil
+	default:
+		log.Printf("invalid payment channel %s\n", channel)
+		return "", paymentChannelInvalid
+	}
 }
 
+// getRepaidRepaymentCount : counts the repayments with a payment, including the partially paid repayments
 func getRepaidRepaymentCount(loanDetails *repoDto.LoanDetails) int {
 	repaidRepayments := 0
 	for _, repayment := range loanDetails.Repayments {
-		if repayment.Status == repoDto.RepaymentStatusPaid {
+		if repayment.Status != repoDto.RepaymentStatusPending {
 			repaidRepayments = repaidRepayments + 1
 		}
 	}
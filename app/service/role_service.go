@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/s8sg/mini-loan-app/app/app_errors"
+	"github.com/s8sg/mini-loan-app/app/controller/dto"
+	responseDto "github.com/s8sg/mini-loan-app/app/dto"
+	repository "github.com/s8sg/mini-loan-app/app/repostory"
+	"github.com/shopspring/decimal"
+	"log"
+	"time"
+)
+
+var (
+	roleNameNotProvided  = &app_errors.AppError{Code: 400, Message: "role name must be provided"}
+	unknownPermission    = &app_errors.AppError{Code: 400, Message: "unknown permission"}
+	unknownRole          = &app_errors.AppError{Code: 400, Message: "unknown role"}
+	userNotFound         = &app_errors.AppError{Code: 404, Message: "user not found"}
+	staffRolesRequired   = &app_errors.AppError{Code: 400, Message: "at least one staff role must be provided"}
+	customerRoleInvalid  = &app_errors.AppError{Code: 400, Message: "customer role is managed by customer signup"}
+	approvalLimitInvalid = &app_errors.AppError{Code: 400, Message: "approval limit can't be negative"}
+)
+
+type RoleService interface {
+	GetAllRoles() ([]*responseDto.RoleDetails, error)
+	GetAllPermissions() ([]*responseDto.PermissionDetails, error)
+	SaveRole(request *dto.RoleSaveRequest) error
+	UpdateUserRoles(request *dto.UserRolesRequest) error
+	UpdateApprovalLimit(request *dto.ApprovalLimitRequest) error
+	CreateStaffUser(request *dto.StaffUserCreateRequest) error
+}
+
+type RoleServiceImplementation struct {
+	repo        repository.RoleRepository
+	userRepo    repository.UserRepository
+	authService AuthService
+}
+
+// GetRoleService : Initialise role-service, uses dependency roleRepository, userRepository and authService
+func GetRoleService(roleRepository repository.RoleRepository, userRepository repository.UserRepository,
+	authService AuthService) RoleService {
+	roleService := &RoleServiceImplementation{
+		repo:        roleRepository,
+		userRepo:    userRepository,
+		authService: authService,
+	}
+	return roleService
+}
+
+func (r RoleServiceImplementation) GetAllRoles() ([]*responseDto.RoleDetails, error) {
+	roleDetailsList, err := r.repo.GetAllRoles()
+	if err != nil {
+		log.Printf("failed to get roles, error %v\n", err)
+		return nil, app_errors.InternalServerError
+	}
+	return roleDetailsList, nil
+}
+
+func (r RoleServiceImplementation) GetAllPermissions() ([]*responseDto.PermissionDetails, error) {
+	permissionDetailsList, err := r.repo.GetAllPermissions()
+	if err != nil {
+		log.Printf("failed to get permissions, error %v\n", err)
+		return nil, app_errors.InternalServerError
+	}
+	return permissionDetailsList, nil
+}
+
+// SaveRole : creates the role or replaces the permissions of an existing role
+func (r RoleServiceImplementation) SaveRole(request *dto.RoleSaveRequest) error {
+	if request.Name == "" {
+		log.Println("role name must be provided")
+		return roleNameNotProvided
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), TimeoutInSecond*time.Second)
+	defer cancelFunc()
+
+	tx, err := r.repo.CreateTransaction(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		log.Println("failed to initiate transaction")
+		return app_errors.InternalServerError
+	}
+
+	defer func() {
+		if err != nil {
+			log.Println("calling rollback for error " + err.Error())
+			_ = tx.Rollback()
+			return
+		}
+		_ = tx.Commit()
+	}()
+
+	err = r.repo.SaveRole(&responseDto.RoleDetails{
+		Name:        request.Name,
+		Description: request.Description,
+		Permissions: uniqueValues(request.Permissions),
+	}, tx)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			log.Printf("role %s has unknown permissions %v\n", request.Name, request.Permissions)
+			return unknownPermission
+		}
+		log.Printf("failed to save role %s, error %v\n", request.Name, err)
+		return app_errors.InternalServerError
+	}
+	return nil
+}
+
+// UpdateUserRoles : replaces the roles of the user, applied to existing sessions on refresh
+func (r RoleServiceImplementation) UpdateUserRoles(request *dto.UserRolesRequest) error {
+	if request.Username == "" {
+		return userIdMustBeProvided
+	}
+
+	roles, err := r.validateStaffRoles(request.Roles)
+	if err != nil {
+		return err
+	}
+
+	userDetails, err := r.userRepo.GetUserByUsername(request.Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return userNotFound
+		}
+		log.Printf("failed to fetch user %s, error %v\n", request.Username, err)
+		return app_errors.InternalServerError
+	}
+
+	if userDetails.HasRole(USER_TYPE_CUSTOMER) {
+		log.Printf("user %s is a customer, roles can't be updated\n", request.Username)
+		return customerRoleInvalid
+	}
+
+	err = r.userRepo.UpdateUserRoles(request.Username, roles)
+	if err != nil {
+		log.Printf("failed to update roles of user %s, error %v\n", request.Username, err)
+		return app_errors.InternalServerError
+	}
+	return nil
+}
+
+// UpdateApprovalLimit : sets the highest loan amount the staff user can approve, applied to the next approval
+func (r RoleServiceImplementation) UpdateApprovalLimit(request *dto.ApprovalLimitRequest) error {
+	if request.Username == "" {
+		return userIdMustBeProvided
+	}
+
+	var approvalLimit *decimal.Decimal
+	if request.ApprovalLimit != nil {
+		if *request.ApprovalLimit < 0 {
+			return approvalLimitInvalid
+		}
+		limit := decimal.NewFromFloat(*request.ApprovalLimit)
+		approvalLimit = &limit
+	}
+
+	userDetails, err := r.userRepo.GetUserByUsername(request.Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return userNotFound
+		}
+		log.Printf("failed to fetch user %s, error %v\n", request.Username, err)
+		return app_errors.InternalServerError
+	}
+
+	if userDetails.HasRole(USER_TYPE_CUSTOMER) {
+		log.Printf("user %s is a customer, approval limit can't be updated\n", request.Username)
+		return customerRoleInvalid
+	}
+
+	err = r.userRepo.UpdateApprovalLimit(request.Username, approvalLimit)
+	if err != nil {
+		log.Printf("failed to update approval limit of user %s, error %v\n", request.Username, err)
+		return app_errors.InternalServerError
+	}
+	return nil
+}
+
+// CreateStaffUser : creates a user which logs in as admin with the provided staff roles
+func (r RoleServiceImplementation) CreateStaffUser(request *dto.StaffUserCreateRequest) error {
+	roles, err := r.validateStaffRoles(request.Roles)
+	if err != nil {
+		return err
+	}
+	return r.authService.RegisterUser(request.Username, request.Secret, roles)
+}
+
+// validateStaffRoles : checks the roles exists and doesn't contain the customer role
+func (r RoleServiceImplementation) validateStaffRoles(requestedRoles []string) ([]string, error) {
+	roles := uniqueValues(requestedRoles)
+	if len(roles) == 0 {
+		return nil, staffRolesRequired
+	}
+
+	for _, role := range roles {
+		if role == USER_TYPE_CUSTOMER {
+			return nil, customerRoleInvalid
+		}
+	}
+
+	count, err := r.repo.CountRoles(roles)
+	if err != nil {
+		log.Printf("failed to validate roles %v, error %v\n", roles, err)
+		return nil, app_errors.InternalServerError
+	}
+	if count != len(roles) {
+		log.Printf("unknown roles in %v\n", roles)
+		return nil, unknownRole
+	}
+	return roles, nil
+}
+
+func uniqueValues(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/s8sg/mini-loan-app/app/dto"
+	"math/big"
+	"os"
+	"sort"
+)
+
+const (
+	SIGNING_ALGORITHM_HS256 = "HS256"
+	SIGNING_ALGORITHM_RS256 = "RS256"
+	SIGNING_ALGORITHM_ES256 = "ES256"
+)
+
+// verificationKey : public key with the algorithm it verifies
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    interface{}
+}
+
+// SigningKeys : key used to sign the bearer tokens and the keys accepted to verify them (selected by kid)
+type SigningKeys struct {
+	method           jwt.SigningMethod
+	keyId            string
+	signingKey       interface{}
+	verificationKeys map[string]*verificationKey
+}
+
+// GetHmacSigningKeys : signs and verifies the bearer tokens with a shared secret
+func GetHmacSigningKeys(secretKey string) *SigningKeys {
+	return &SigningKeys{
+		method:           jwt.SigningMethodHS256,
+		signingKey:       []byte(secretKey),
+		verificationKeys: map[string]*verificationKey{},
+	}
+}
+
+// LoadSigningKeys : loads the PEM encoded private key used for signing with RS256 or ES256,
+// verificationKeyFiles maps kid to the PEM encoded public keys still accepted (e.g. keys being rotated out)
+func LoadSigningKeys(algorithm string, keyId string, privateKeyFile string,
+	verificationKeyFiles map[string]string) (*SigningKeys, error) {
+
+	if keyId == "" {
+		return nil, fmt.Errorf("key id must be provided for %s", algorithm)
+	}
+
+	privateKeyPem, err := os.ReadFile(privateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key %s, %v", privateKeyFile, err)
+	}
+
+	signingKeys := &SigningKeys{
+		keyId:            keyId,
+		verificationKeys: map[string]*verificationKey{},
+	}
+
+	switch algorithm {
+	case SIGNING_ALGORITHM_RS256:
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyPem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RSA signing key, %v", err)
+		}
+		signingKeys.method = jwt.SigningMethodRS256
+		signingKeys.signingKey = privateKey
+		signingKeys.verificationKeys[keyId] = &verificationKey{method: jwt.SigningMethodRS256, key: &privateKey.PublicKey}
+	case SIGNING_ALGORITHM_ES256:
+		privateKey, err := jwt.ParseECPrivateKeyFromPEM(privateKeyPem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse EC signing key, %v", err)
+		}
+		if privateKey.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("ES256 requires a P-256 key")
+		}
+		signingKeys.method = jwt.SigningMethodES256
+		signingKeys.signingKey = privateKey
+		signingKeys.verificationKeys[keyId] = &verificationKey{method: jwt.SigningMethodES256, key: &privateKey.PublicKey}
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %s", algorithm)
+	}
+
+	for kid, publicKeyFile := range verificationKeyFiles {
+		if _, ok := signingKeys.verificationKeys[kid]; ok {
+			return nil, fmt.Errorf("duplicate key id %s", kid)
+		}
+		publicKeyPem, err := os.ReadFile(publicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read verification key %s, %v", publicKeyFile, err)
+		}
+		key, err := parsePublicKey(publicKeyPem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse verification key %s, %v", publicKeyFile, err)
+		}
+		signingKeys.verificationKeys[kid] = key
+	}
+
+	return signingKeys, nil
+}
+
+// sign : signs the claims with the signing key, the kid is set in the header for asymmetric keys
+func (keys *SigningKeys) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(keys.method, claims)
+	if keys.keyId != "" {
+		token.Header["kid"] = keys.keyId
+	}
+	return token.SignedString(keys.signingKey)
+}
+
+// keyFunc : selects the verification key for the token
+func (keys *SigningKeys) keyFunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := keys.method.(*jwt.SigningMethodHMAC); ok {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return keys.signingKey, nil
+	}
+
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil, fmt.Errorf("token doesn't have a kid")
+	}
+	key, ok := keys.verificationKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %s", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %v for kid %s", token.Header["alg"], kid)
+	}
+	return key.key, nil
+}
+
+// jwks : public verification keys in JWKS format, empty for HMAC
+func (keys *SigningKeys) jwks() *dto.JSONWebKeySet {
+	keyIds := make([]string, 0, len(keys.verificationKeys))
+	for kid := range keys.verificationKeys {
+		keyIds = append(keyIds, kid)
+	}
+	sort.Strings(keyIds)
+
+	keySet := &dto.JSONWebKeySet{Keys: make([]*dto.JSONWebKey, 0, len(keyIds))}
+	for _, kid := range keyIds {
+		key := keys.verificationKeys[kid]
+		jwk := &dto.JSONWebKey{
+			KeyId:     kid,
+			Use:       "sig",
+			Algorithm: key.method.Alg(),
+		}
+		switch publicKey := key.key.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case *ecdsa.PublicKey:
+			jwk.KeyType = "EC"
+			jwk.Curve = publicKey.Curve.Params().Name
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey.X.FillBytes(make([]byte, 32)))
+			jwk.Y = base64.RawURLEncoding.EncodeToString(publicKey.Y.FillBytes(make([]byte, 32)))
+		}
+		keySet.Keys = append(keySet.Keys, jwk)
+	}
+	return keySet
+}
+
+// parseJSONWebKey : parses an RSA or P-256 EC public key in JWKS format
+func parseJSONWebKey(jwk *dto.JSONWebKey) (*verificationKey, error) {
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus of key %s, %v", jwk.KeyId, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent of key %s, %v", jwk.KeyId, err)
+		}
+		publicKey := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		return &verificationKey{method: jwt.SigningMethodRS256, key: publicKey}, nil
+	case "EC":
+		if jwk.Curve != elliptic.P256().Params().Name {
+			return nil, fmt.Errorf("ES256 requires a P-256 key, key %s uses %s", jwk.KeyId, jwk.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate of key %s, %v", jwk.KeyId, err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate of key %s, %v", jwk.KeyId, err)
+		}
+		publicKey := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !publicKey.Curve.IsOnCurve(publicKey.X, publicKey.Y) {
+			return nil, fmt.Errorf("key %s is not on the P-256 curve", jwk.KeyId)
+		}
+		return &verificationKey{method: jwt.SigningMethodES256, key: publicKey}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s of key %s", jwk.KeyType, jwk.KeyId)
+	}
+}
+
+func parsePublicKey(publicKeyPem []byte) (*verificationKey, error) {
+	if rsaKey, err := jwt.ParseRSAPublicKeyFromPEM(publicKeyPem); err == nil {
+		return &verificationKey{method: jwt.SigningMethodRS256, key: rsaKey}, nil
+	}
+	ecKey, err := jwt.ParseECPublicKeyFromPEM(publicKeyPem)
+	if err != nil {
+		return nil, fmt.Errorf("key is neither an RSA nor an EC public key")
+	}
+	if ecKey.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("ES256 requires a P-256 key")
+	}
+	return &verificationKey{method: jwt.SigningMethodES256, key: ecKey}, nil
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// writePemKeys writes the PEM encoded private key and its public key to the temp dir of the test
+func writePemKeys(t *testing.T, name string, key interface{}) (string, string) {
+	t.Helper()
+
+	var privateKeyBlock *pem.Block
+	var publicKey interface{}
+	switch privateKey := key.(type) {
+	case *rsa.PrivateKey:
+		privateKeyBlock = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}
+		publicKey = &privateKey.PublicKey
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		privateKeyBlock = &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}
+		publicKey = &privateKey.PublicKey
+	}
+	publicKeyDer, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	privateKeyFile := filepath.Join(dir, name+".pem")
+	publicKeyFile := filepath.Join(dir, name+".pub.pem")
+	if err := os.WriteFile(privateKeyFile, pem.EncodeToMemory(privateKeyBlock), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(publicKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDer}),
+		0600); err != nil {
+		t.Fatal(err)
+	}
+	return privateKeyFile, publicKeyFile
+}
+
+func mustGenerateECKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// signWithKid signs the claims with the key and the kid in the header, bypassing the SigningKeys under test
+func signWithKid(t *testing.T, method jwt.SigningMethod, kid string, key interface{}) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, jwt.MapClaims{"id": "user1", "exp": time.Now().Add(time.Minute).Unix()})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signedToken, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signedToken
+}
+
+func TestSigningKeys_SignAndVerify(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		key       interface{}
+	}{
+		{"RS256", SIGNING_ALGORITHM_RS256, mustGenerateRSAKey(t)},
+		{"ES256", SIGNING_ALGORITHM_ES256, mustGenerateECKey(t)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			privateKeyFile, _ := writePemKeys(t, "signing", tt.key)
+			signingKeys, err := LoadSigningKeys(tt.algorithm, "key-1", privateKeyFile, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			signedToken, err := signingKeys.sign(jwt.MapClaims{"id": "user1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			token, err := jwt.Parse(signedToken, signingKeys.keyFunc)
+			if err != nil || !token.Valid {
+				t.Fatalf("expected token to be valid, error %v", err)
+			}
+			if token.Header["kid"] != "key-1" || token.Method.Alg() != tt.algorithm {
+				t.Errorf("expected kid key-1 and alg %s but got %v", tt.algorithm, token.Header)
+			}
+
+			keySet := signingKeys.jwks()
+			if len(keySet.Keys) != 1 || keySet.Keys[0].KeyId != "key-1" || keySet.Keys[0].Algorithm != tt.algorithm {
+				t.Errorf("expected the JWKS to contain key-1 but got %+v", keySet.Keys)
+			}
+		})
+	}
+}
+
+func TestSigningKeys_KeySelection(t *testing.T) {
+	rsaKey := mustGenerateRSAKey(t)
+	rotatedKey := mustGenerateECKey(t)
+	privateKeyFile, _ := writePemKeys(t, "signing", rsaKey)
+	_, rotatedKeyFile := writePemKeys(t, "rotated", rotatedKey)
+
+	signingKeys, err := LoadSigningKeys(SIGNING_ALGORITHM_RS256, "current", privateKeyFile,
+		map[string]string{"rotated": rotatedKeyFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(signingKeys.jwks().Keys) != 2 {
+		t.Errorf("expected the JWKS to contain both keys but got %+v", signingKeys.jwks().Keys)
+	}
+
+	// tokens signed with a key being rotated out are verified with the key of their kid
+	token := signWithKid(t, jwt.SigningMethodES256, "rotated", rotatedKey)
+	if _, err := jwt.Parse(token, signingKeys.keyFunc); err != nil {
+		t.Errorf("expected token of the rotated key to be valid, error %v", err)
+	}
+
+	invalidTokens := map[string]string{
+		"unknown kid":            signWithKid(t, jwt.SigningMethodRS256, "unknown", rsaKey),
+		"no kid":                 signWithKid(t, jwt.SigningMethodRS256, "", rsaKey),
+		"signed by unknown key":  signWithKid(t, jwt.SigningMethodRS256, "current", mustGenerateRSAKey(t)),
+		"algorithm of other kid": signWithKid(t, jwt.SigningMethodES256, "current", rotatedKey),
+		"hmac with the kid":      signWithKid(t, jwt.SigningMethodHS256, "current", []byte("secret")),
+		"signed by other ec key": signWithKid(t, jwt.SigningMethodES256, "rotated", mustGenerateECKey(t)),
+	}
+	for name, token := range invalidTokens {
+		t.Run(name, func(t *testing.T) {
+			if _, err := jwt.Parse(token, signingKeys.keyFunc); err == nil {
+				t.Errorf("expected token to be rejected")
+			}
+		})
+	}
+}
+
+func TestSigningKeys_Hmac(t *testing.T) {
+	signingKeys := GetHmacSigningKeys("secret")
+
+	signedToken, err := signingKeys.sign(jwt.MapClaims{"id": "user1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := jwt.Parse(signedToken, signingKeys.keyFunc); err != nil {
+		t.Errorf("expected token to be valid, error %v", err)
+	}
+
+	// asymmetric tokens are rejected when signing with a shared secret
+	token := signWithKid(t, jwt.SigningMethodRS256, "key-1", mustGenerateRSAKey(t))
+	if _, err := jwt.Parse(token, signingKeys.keyFunc); err == nil {
+		t.Errorf("expected RS256 token to be rejected")
+	}
+	if len(signingKeys.jwks().Keys) != 0 {
+		t.Errorf("expected the JWKS to be empty but got %+v", signingKeys.jwks().Keys)
+	}
+}
+
+func TestLoadSigningKeys_Invalid(t *testing.T) {
+	rsaKeyFile, rsaPublicKeyFile := writePemKeys(t, "rsa", mustGenerateRSAKey(t))
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384KeyFile, _ := writePemKeys(t, "p384", p384Key)
+
+	tests := []struct {
+		name             string
+		algorithm        string
+		keyId            string
+		privateKeyFile   string
+		verificationKeys map[string]string
+	}{
+		{"no key id", SIGNING_ALGORITHM_RS256, "", rsaKeyFile, nil},
+		{"missing key file", SIGNING_ALGORITHM_RS256, "key-1", filepath.Join(t.TempDir(), "missing.pem"), nil},
+		{"ES256 with RSA key", SIGNING_ALGORITHM_ES256, "key-1", rsaKeyFile, nil},
+		{"ES256 with P-384 key", SIGNING_ALGORITHM_ES256, "key-1", p384KeyFile, nil},
+		{"unsupported algorithm", "PS256", "key-1", rsaKeyFile, nil},
+		{"duplicate key id", SIGNING_ALGORITHM_RS256, "key-1", rsaKeyFile, map[string]string{"key-1": rsaPublicKeyFile}},
+		{"verification key is not a public key", SIGNING_ALGORITHM_RS256, "key-1", rsaKeyFile,
+			map[string]string{"key-2": rsaKeyFile}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadSigningKeys(tt.algorithm, tt.keyId, tt.privateKeyFile, tt.verificationKeys); err == nil {
+				t.Errorf("expected the keys to be rejected")
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package util
+
+// currencyMinorUnits : decimal places of the minor unit of the supported currencies (ISO 4217)
+var currencyMinorUnits = map[string]int32{
+	"AUD": 2,
+	"CNY": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"HKD": 2,
+	"IDR": 2,
+	"INR": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"MYR": 2,
+	"PHP": 2,
+	"SGD": 2,
+	"THB": 2,
+	"USD": 2,
+	"VND": 0,
+}
+
+// GetCurrencyMinorUnits : decimal places of the minor unit of the currency, false if the currency isn't supported
+func GetCurrencyMinorUnits(currency string) (int32, bool) {
+	minorUnits, ok := currencyMinorUnits[currency]
+	return minorUnits, ok
+}
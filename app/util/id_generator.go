@@ -9,3 +9,31 @@ func GenerateLoanID() string {
 func GenerateRepaymentID() string {
 	return uuid.New().String()
 }
+
+func GenerateSessionID() string {
+	return uuid.New().String()
+}
+
+func GenerateTokenID() string {
+	return uuid.New().String()
+}
+
+func GenerateApiKeyID() string {
+	return uuid.New().String()
+}
+
+func GenerateMfaChallengeID() string {
+	return uuid.New().String()
+}
+
+func GenerateLoanProductID() string {
+	return uuid.New().String()
+}
+
+func GeneratePaymentID() string {
+	return uuid.New().String()
+}
+
+func GenerateQuoteID() string {
+	return uuid.New().String()
+}
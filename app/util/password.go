@@ -0,0 +1,27 @@
+package util
+
+import "golang.org/x/crypto/bcrypt"
+
+// dummySecretHash is compared when there is no stored hash, so that the comparison takes the same time for
+// unknown users as for known ones
+var dummySecretHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-secret"), bcrypt.DefaultCost)
+
+// HashSecret : generates a salted bcrypt hash of the secret
+func HashSecret(secret string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CompareSecret : checks if the secret matches the bcrypt hash
+func CompareSecret(hash string, secret string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(secret)) == nil
+}
+
+// CompareDummySecret : compares the secret with a dummy hash, used when the user is unknown so that the response time
+// doesn't reveal which users exist
+func CompareDummySecret(secret string) {
+	_ = bcrypt.CompareHashAndPassword(dummySecretHash, []byte(secret))
+}
@@ -0,0 +1,82 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// TotpPeriod is the time step of the TOTP codes (RFC 6238)
+	TotpPeriod = 30
+	// TotpDigits is the length of the TOTP codes
+	TotpDigits = 6
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTotpSecret : generates a base32 encoded 160 bit TOTP secret
+func GenerateTotpSecret() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(buf), nil
+}
+
+// GetTotpUri : otpauth URI of the secret, rendered as QR code by the authenticator apps
+func GetTotpUri(issuer string, accountName string, secret string) string {
+	values := url.Values{}
+	values.Set("secret", secret)
+	values.Set("issuer", issuer)
+	values.Set("algorithm", "SHA1")
+	values.Set("digits", fmt.Sprint(TotpDigits))
+	values.Set("period", fmt.Sprint(TotpPeriod))
+	return "otpauth://totp/" + url.PathEscape(issuer+":"+accountName) + "?" + values.Encode()
+}
+
+// GetTotpStep : time step of the time
+func GetTotpStep(t time.Time) int64 {
+	return t.Unix() / TotpPeriod
+}
+
+// GenerateTotpCode : TOTP code of the secret for the time step (HMAC-SHA1, RFC 4226 dynamic truncation)
+func GenerateTotpCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", TotpDigits, value%1000000), nil
+}
+
+// ValidateTotpCode : checks the code against the current time step and the adjacent steps to allow clock skew,
+// responds with the matched step so the caller can reject replays
+func ValidateTotpCode(secret string, code string, t time.Time) (int64, bool) {
+	current := GetTotpStep(t)
+	for _, step := range []int64{current - 1, current, current + 1} {
+		expected, err := GenerateTotpCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
@@ -1,28 +1,307 @@
+CREATE TABLE IF NOT EXISTS loan_products
+(
+    id                   UUID PRIMARY KEY,
+    name                 VARCHAR NOT NULL,
+    description          VARCHAR NOT NULL DEFAULT '',
+    status               VARCHAR NOT NULL,
+    currency             VARCHAR NOT NULL,
+    min_amount           NUMERIC NOT NULL,
+    max_amount           NUMERIC NOT NULL,
+    terms                INT[] NOT NULL,
+    interest_rate        NUMERIC NOT NULL DEFAULT 0,
+    interest_method      VARCHAR NOT NULL,
+    repayment_frequency  VARCHAR NOT NULL,
+    origination_fee_rate NUMERIC NOT NULL DEFAULT 0,
+    max_open_loans       INT NOT NULL DEFAULT 0,
+    min_customer_days    INT NOT NULL DEFAULT 0,
+    version              INT NOT NULL DEFAULT 1,
+    created_at           TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at           TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- terms of every version of a loan product, quotes are validated against the version they were quoted at
+CREATE TABLE IF NOT EXISTS loan_product_versions
+(
+    product_id           UUID NOT NULL REFERENCES loan_products (id),
+    version              INT NOT NULL,
+    currency             VARCHAR NOT NULL,
+    min_amount           NUMERIC NOT NULL,
+    max_amount           NUMERIC NOT NULL,
+    terms                INT[] NOT NULL,
+    interest_rate        NUMERIC NOT NULL DEFAULT 0,
+    interest_method      VARCHAR NOT NULL,
+    repayment_frequency  VARCHAR NOT NULL,
+    origination_fee_rate NUMERIC NOT NULL DEFAULT 0,
+    created_at           TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (product_id, version)
+);
+
+
 CREATE TABLE IF NOT EXISTS loans
+(
+    id                  UUID PRIMARY KEY,
+    customer_id         VARCHAR NOT NULL,
+    amount              NUMERIC NOT NULL,
+    currency            VARCHAR NOT NULL DEFAULT 'USD',
+    term                INT NOT NULL,
+    repayment_frequency VARCHAR NOT NULL DEFAULT 'WEEKLY',
+    status              VARCHAR NOT NULL,
+    start_date          TIMESTAMP NOT NULL,
+    approved_at         TIMESTAMP,
+    interest_rate       NUMERIC NOT NULL DEFAULT 0,
+    interest_method     VARCHAR NOT NULL DEFAULT 'FLAT',
+    origination_fee     NUMERIC NOT NULL DEFAULT 0,
+    product_id          UUID REFERENCES loan_products (id),
+    product_version     INT NOT NULL DEFAULT 0,
+    quote_id            UUID UNIQUE,
+    rejection_reason    VARCHAR NOT NULL DEFAULT '',
+    rejection_notes     VARCHAR NOT NULL DEFAULT '',
+    created_at          TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_customer_id_loans ON loans (customer_id);
+
+
+CREATE TABLE IF NOT EXISTS repayments
+(
+    id             UUID PRIMARY KEY,
+    num            INT NOT NULL,
+    loan_id        UUID,
+    amount         NUMERIC NOT NULL,
+    principal      NUMERIC NOT NULL DEFAULT 0,
+    interest       NUMERIC NOT NULL DEFAULT 0,
+    fee            NUMERIC NOT NULL DEFAULT 0,
+    paid_amount    NUMERIC NOT NULL DEFAULT 0,
+    paid_principal NUMERIC NOT NULL DEFAULT 0,
+    paid_interest  NUMERIC NOT NULL DEFAULT 0,
+    paid_fee       NUMERIC NOT NULL DEFAULT 0,
+    status         VARCHAR NOT NULL,
+    due_date       TIMESTAMP NOT NULL,
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_customer_id_repayments ON loans (customer_id);
+
+
+CREATE TABLE IF NOT EXISTS payments
 (
     id          UUID PRIMARY KEY,
+    loan_id     UUID NOT NULL REFERENCES loans (id),
     customer_id VARCHAR NOT NULL,
     amount      NUMERIC NOT NULL,
-    term        INT NOT NULL,
+    currency    VARCHAR NOT NULL,
+    channel     VARCHAR NOT NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_loan_id_payments ON payments (loan_id);
+
+
+CREATE TABLE IF NOT EXISTS payment_allocations
+(
+    payment_id   UUID NOT NULL REFERENCES payments (id),
+    repayment_id UUID NOT NULL REFERENCES repayments (id),
+    amount       NUMERIC NOT NULL,
+    principal    NUMERIC NOT NULL DEFAULT 0,
+    interest     NUMERIC NOT NULL DEFAULT 0,
+    fee          NUMERIC NOT NULL DEFAULT 0,
+    PRIMARY KEY (payment_id, repayment_id)
+);
+
+
+CREATE TABLE IF NOT EXISTS loan_status_history
+(
+    id          BIGSERIAL PRIMARY KEY,
+    loan_id     UUID NOT NULL REFERENCES loans (id),
+    from_status VARCHAR NOT NULL DEFAULT '',
+    to_status   VARCHAR NOT NULL,
+    actor_id    VARCHAR NOT NULL,
+    actor_type  VARCHAR NOT NULL,
+    reason      VARCHAR NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_loan_id_loan_status_history ON loan_status_history (loan_id);
+
+
+CREATE TABLE IF NOT EXISTS loan_approvals
+(
+    loan_id       UUID NOT NULL REFERENCES loans (id),
+    approver_id   VARCHAR NOT NULL,
+    approver_type VARCHAR NOT NULL,
+    created_at    TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (loan_id, approver_id)
+);
+
+
+
+CREATE TABLE IF NOT EXISTS users
+(
+    username       VARCHAR PRIMARY KEY,
+    secret_hash    VARCHAR NOT NULL,
+    roles          VARCHAR[] NOT NULL,
+    totp_secret    VARCHAR NOT NULL DEFAULT '',
+    totp_enabled   BOOLEAN NOT NULL DEFAULT FALSE,
+    totp_last_step BIGINT NOT NULL DEFAULT 0,
+    approval_limit NUMERIC,
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+
+CREATE TABLE IF NOT EXISTS customers
+(
+    id          VARCHAR PRIMARY KEY REFERENCES users (username),
+    name        VARCHAR NOT NULL,
+    email       VARCHAR NOT NULL,
+    phone       VARCHAR NOT NULL DEFAULT '',
     status      VARCHAR NOT NULL,
-    start_date  TIMESTAMP NOT NULL,
     created_at  TIMESTAMP NOT NULL DEFAULT NOW(),
     updated_at  TIMESTAMP NOT NULL DEFAULT NOW()
 );
 
-CREATE INDEX idx_customer_id_loans ON loans (customer_id);
+ALTER TABLE loans ADD CONSTRAINT fk_customer_id_loans FOREIGN KEY (customer_id) REFERENCES customers (id);
 
 
-CREATE TABLE IF NOT EXISTS repayments
+CREATE TABLE IF NOT EXISTS sessions
 (
-    id          UUID PRIMARY KEY,
-    num         INT NOT NULL,
-    loan_id     UUID,
-    amount      NUMERIC NOT NULL,
-    status      VARCHAR NOT NULL,
-    due_date    TIMESTAMP NOT NULL,
+    id                 UUID PRIMARY KEY,
+    username           VARCHAR NOT NULL REFERENCES users (username) ON DELETE CASCADE,
+    role               VARCHAR NOT NULL,
+    refresh_token_hash VARCHAR NOT NULL,
+    expires_at         TIMESTAMP NOT NULL,
+    revoked            BOOLEAN NOT NULL DEFAULT FALSE,
+    mfa_verified       BOOLEAN NOT NULL DEFAULT FALSE,
+    impersonated_by    VARCHAR,
+    created_at         TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_username_sessions ON sessions (username);
+
+-- denylist of revoked access tokens, kept until the token expires
+CREATE TABLE IF NOT EXISTS revoked_tokens
+(
+    jti        UUID PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+
+CREATE TABLE IF NOT EXISTS roles
+(
+    name        VARCHAR PRIMARY KEY,
+    description VARCHAR NOT NULL DEFAULT '',
     created_at  TIMESTAMP NOT NULL DEFAULT NOW(),
     updated_at  TIMESTAMP NOT NULL DEFAULT NOW()
 );
-CREATE INDEX idx_customer_id_repayments ON loans (customer_id);
 
+CREATE TABLE IF NOT EXISTS permissions
+(
+    name        VARCHAR PRIMARY KEY,
+    description VARCHAR NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions
+(
+    role       VARCHAR NOT NULL REFERENCES roles (name) ON DELETE CASCADE,
+    permission VARCHAR NOT NULL REFERENCES permissions (name) ON DELETE CASCADE,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO permissions (name, description)
+VALUES ('loan:create:own', 'create a loan for self'),
+       ('loan:cancel:own', 'cancel own loans'),
+       ('loan:read:own', 'read own loans'),
+       ('repayment:create:own', 'repay own loans'),
+       ('profile:read:own', 'read own customer profile'),
+       ('profile:update:own', 'update own customer profile'),
+       ('loan:approve', 'approve and reject loans'),
+       ('loan:read:any', 'read loans of any customer'),
+       ('customer:read', 'read customer profiles'),
+       ('customer:manage', 'disable, enable and delete customers'),
+       ('role:manage', 'manage roles and their permissions'),
+       ('user:manage', 'create staff users and manage their roles'),
+       ('api-key:manage', 'issue and revoke api keys'),
+       ('customer:impersonate', 'view the app as a customer (read only)'),
+       ('loan-product:manage', 'manage the loan products offered to customers')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO roles (name, description)
+VALUES ('customer', 'customer of the loan app'),
+       ('admin', 'administrator with all staff permissions'),
+       ('support', 'customer support staff'),
+       ('credit-officer', 'staff approving loans'),
+       ('auditor', 'read only access for audits')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+VALUES ('customer', 'loan:create:own'),
+       ('customer', 'loan:cancel:own'),
+       ('customer', 'loan:read:own'),
+       ('customer', 'repayment:create:own'),
+       ('customer', 'profile:read:own'),
+       ('customer', 'profile:update:own'),
+       ('admin', 'loan:approve'),
+       ('admin', 'loan:read:any'),
+       ('admin', 'customer:read'),
+       ('admin', 'customer:manage'),
+       ('admin', 'role:manage'),
+       ('admin', 'user:manage'),
+       ('admin', 'api-key:manage'),
+       ('admin', 'customer:impersonate'),
+       ('admin', 'loan-product:manage'),
+       ('support', 'loan:read:any'),
+       ('support', 'customer:read'),
+       ('support', 'customer:impersonate'),
+       ('credit-officer', 'loan:approve'),
+       ('credit-officer', 'loan:read:any'),
+       ('credit-officer', 'customer:read'),
+       ('auditor', 'loan:read:any'),
+       ('auditor', 'customer:read')
+ON CONFLICT DO NOTHING;
+
+
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id           UUID PRIMARY KEY,
+    name         VARCHAR NOT NULL,
+    key_hash     VARCHAR NOT NULL,
+    permissions  VARCHAR[] NOT NULL DEFAULT '{}',
+    created_by   VARCHAR NOT NULL REFERENCES users (username),
+    expires_at   TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP,
+    revoked      BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+
+CREATE TABLE IF NOT EXISTS login_attempts
+(
+    scope          VARCHAR NOT NULL,
+    subject        VARCHAR NOT NULL,
+    failed_count   INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP NOT NULL,
+    locked_until   TIMESTAMP,
+    PRIMARY KEY (scope, subject)
+);
+
+
+CREATE TABLE IF NOT EXISTS mfa_challenges
+(
+    id             UUID PRIMARY KEY,
+    username       VARCHAR NOT NULL REFERENCES users (username) ON DELETE CASCADE,
+    challenge_hash VARCHAR NOT NULL,
+    expires_at     TIMESTAMP NOT NULL,
+    attempts       INTEGER NOT NULL DEFAULT 0,
+    used           BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS recovery_codes
+(
+    username   VARCHAR NOT NULL REFERENCES users (username) ON DELETE CASCADE,
+    code_hash  VARCHAR NOT NULL,
+    used_at    TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (username, code_hash)
+);